@@ -1,6 +1,8 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -30,6 +32,49 @@ type Author struct {
 	Date  string `json:"date"`
 }
 
+// BranchProtection 表示分支保护规则
+type BranchProtection struct {
+	URL                        string                      `json:"url"`
+	RequiredStatusChecks       *RequiredStatusChecks       `json:"required_status_checks"`
+	EnforceAdmins              *ProtectionSetting          `json:"enforce_admins"`
+	RequiredPullRequestReviews *RequiredPullRequestReviews `json:"required_pull_request_reviews"`
+	Restrictions               *BranchRestrictions         `json:"restrictions"`
+	RequiredLinearHistory      *ProtectionSetting          `json:"required_linear_history,omitempty"`
+	AllowForcePushes           *ProtectionSetting          `json:"allow_force_pushes,omitempty"`
+	AllowDeletions             *ProtectionSetting          `json:"allow_deletions,omitempty"`
+}
+
+// RequiredStatusChecks 表示合并前必须通过的状态检查
+type RequiredStatusChecks struct {
+	Strict   bool     `json:"strict"`
+	Contexts []string `json:"contexts"`
+}
+
+// ProtectionSetting 表示一个开关类的保护设置
+type ProtectionSetting struct {
+	Enabled bool `json:"enabled"`
+}
+
+// RequiredPullRequestReviews 表示合并前的代码审查要求
+type RequiredPullRequestReviews struct {
+	DismissStaleReviews          bool `json:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews      bool `json:"require_code_owner_reviews"`
+	RequiredApprovingReviewCount int  `json:"required_approving_review_count"`
+}
+
+// BranchRestrictions 表示允许推送到分支的用户和团队
+type BranchRestrictions struct {
+	Users []User `json:"users"`
+	Teams []Team `json:"teams"`
+}
+
+// Team 表示组织中的团队
+type Team struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
 // CreateBranchOptions 表示创建分支的参数
 type CreateBranchOptions struct {
 	BranchName string `json:"branch_name"`
@@ -37,25 +82,20 @@ type CreateBranchOptions struct {
 }
 
 // ListBranches 列出仓库的分支
-func (api *BranchAPI) ListBranches(owner, repo string) ([]Branch, error) {
+func (api *BranchAPI) ListBranches(ctx context.Context, owner, repo string, opts *ListOptions) ([]Branch, error) {
 	path := fmt.Sprintf("/repos/%s/%s/branches", owner, repo)
-	resp, err := api.Client.GET(path, nil)
+	branches, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Branch])
 	if err != nil {
 		return nil, err
 	}
 	
-	var branches []Branch
-	if err := json.Unmarshal(resp, &branches); err != nil {
-		return nil, fmt.Errorf("解析分支列表失败: %w", err)
-	}
-	
 	return branches, nil
 }
 
 // GetBranch 获取特定分支的详细信息
-func (api *BranchAPI) GetBranch(owner, repo, branch string) (*Branch, error) {
+func (api *BranchAPI) GetBranch(ctx context.Context, owner, repo, branch string) (*Branch, error) {
 	path := fmt.Sprintf("/repos/%s/%s/branches/%s", owner, repo, branch)
-	resp, err := api.Client.GET(path, nil)
+	resp, err := api.Client.GET(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -69,14 +109,14 @@ func (api *BranchAPI) GetBranch(owner, repo, branch string) (*Branch, error) {
 }
 
 // CreateBranch 创建新分支
-func (api *BranchAPI) CreateBranch(owner, repo, branch, ref string) (*Branch, error) {
+func (api *BranchAPI) CreateBranch(ctx context.Context, owner, repo, branch, ref string) (*Branch, error) {
 	path := fmt.Sprintf("/repos/%s/%s/branches", owner, repo)
 	options := CreateBranchOptions{
 		BranchName: branch,
 		Ref:        ref,
 	}
 	
-	resp, err := api.Client.POST(path, nil, options)
+	resp, err := api.Client.POST(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
@@ -90,45 +130,45 @@ func (api *BranchAPI) CreateBranch(owner, repo, branch, ref string) (*Branch, er
 }
 
 // DeleteBranch 删除分支
-func (api *BranchAPI) DeleteBranch(owner, repo, branch string) error {
+func (api *BranchAPI) DeleteBranch(ctx context.Context, owner, repo, branch string) error {
 	path := fmt.Sprintf("/repos/%s/%s/branches/%s", owner, repo, branch)
-	_, err := api.Client.DELETE(path, nil)
+	_, err := api.Client.DELETE(ctx, path, nil)
 	return err
 }
 
 // ProtectBranch 设置分支保护
-func (api *BranchAPI) ProtectBranch(owner, repo, branch string, options map[string]interface{}) error {
+func (api *BranchAPI) ProtectBranch(ctx context.Context, owner, repo, branch string, options map[string]interface{}) error {
 	path := fmt.Sprintf("/repos/%s/%s/branches/%s/protection", owner, repo, branch)
-	_, err := api.Client.PUT(path, nil, options)
+	_, err := api.Client.PUT(ctx, path, nil, options)
 	return err
 }
 
 // RemoveProtection 移除分支保护
-func (api *BranchAPI) RemoveProtection(owner, repo, branch string) error {
+func (api *BranchAPI) RemoveProtection(ctx context.Context, owner, repo, branch string) error {
 	path := fmt.Sprintf("/repos/%s/%s/branches/%s/protection", owner, repo, branch)
-	_, err := api.Client.DELETE(path, nil)
+	_, err := api.Client.DELETE(ctx, path, nil)
 	return err
 }
 
 // GetProtection 获取分支保护规则
-func (api *BranchAPI) GetProtection(owner, repo, branch string) (map[string]interface{}, error) {
+func (api *BranchAPI) GetProtection(ctx context.Context, owner, repo, branch string) (*BranchProtection, error) {
 	path := fmt.Sprintf("/repos/%s/%s/branches/%s/protection", owner, repo, branch)
-	resp, err := api.Client.GET(path, nil)
+	resp, err := api.Client.GET(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
 	
-	var protection map[string]interface{}
+	var protection BranchProtection
 	if err := json.Unmarshal(resp, &protection); err != nil {
 		return nil, fmt.Errorf("解析分支保护规则失败: %w", err)
 	}
 	
-	return protection, nil
+	return &protection, nil
 }
 
 // IsBranchProtected 检查分支是否受保护
-func (api *BranchAPI) IsBranchProtected(owner, repo, branch string) (bool, error) {
-	branchInfo, err := api.GetBranch(owner, repo, branch)
+func (api *BranchAPI) IsBranchProtected(ctx context.Context, owner, repo, branch string) (bool, error) {
+	branchInfo, err := api.GetBranch(ctx, owner, repo, branch)
 	if err != nil {
 		return false, err
 	}
@@ -136,8 +176,8 @@ func (api *BranchAPI) IsBranchProtected(owner, repo, branch string) (bool, error
 	return branchInfo.Protected, nil
 }
 
-// MergeBranch 合并分支
-func (api *BranchAPI) MergeBranch(owner, repo, base, head string, message string) (map[string]interface{}, error) {
+// MergeBranch 合并分支，head已经包含在base中时返回Merged为false的结果
+func (api *BranchAPI) MergeBranch(ctx context.Context, owner, repo, base, head string, message string) (*MergeResult, error) {
 	path := fmt.Sprintf("/repos/%s/%s/merges", owner, repo)
 	options := map[string]string{
 		"base": base,
@@ -148,15 +188,21 @@ func (api *BranchAPI) MergeBranch(owner, repo, base, head string, message string
 		options["commit_message"] = message
 	}
 	
-	resp, err := api.Client.POST(path, nil, options)
+	resp, err := api.Client.POST(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
 	
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
+	// 没有需要合并的内容时服务端不返回数据
+	if len(bytes.TrimSpace(resp)) == 0 {
+		return &MergeResult{Merged: false, Message: fmt.Sprintf("%s 已包含 %s 的所有提交，无需合并", base, head)}, nil
+	}
+	
+	// 合并成功时返回合并提交
+	var commit Commit
+	if err := json.Unmarshal(resp, &commit); err != nil {
 		return nil, fmt.Errorf("解析合并结果失败: %w", err)
 	}
 	
-	return result, nil
+	return &MergeResult{Merged: true, SHA: commit.SHA, Message: commit.Commit.Message}, nil
 } 
\ No newline at end of file
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"bytes"
 	"crypto/md5"
+	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +12,9 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 	
 	"github.com/gitcode-org-com/gitcode-mcp/config"
@@ -21,8 +26,10 @@ var (
 	ErrPermissionDenied = errors.New("权限不足")
 	ErrNotFound        = errors.New("资源不存在")
 	ErrValidation      = errors.New("参数验证失败")
+	ErrConflict        = errors.New("资源冲突")
 	ErrRateLimit       = errors.New("API请求频率限制")
 	ErrServer          = errors.New("服务器错误")
+	ErrCircuitOpen     = errors.New("GitCode API持续失败，熔断器已打开")
 	ErrUnknown         = errors.New("未知错误")
 )
 
@@ -49,6 +56,14 @@ type GitCodeAPI struct {
 	BaseURL     string
 	Timeout     time.Duration
 	HTTPClient  *http.Client
+	Retry       RetryPolicy
+	Cache       config.Cache // GET响应缓存，会话客户端使用各自独立的缓存
+	
+	ownsCache bool
+	breaker  *circuitBreaker
+	inflight inflightGroup
+	counters requestCounters
+	generations cacheGenerations
 	
 	// API子模块
 	Repos      *RepositoryAPI
@@ -56,6 +71,10 @@ type GitCodeAPI struct {
 	Issues     *IssueAPI
 	Pulls      *PullRequestAPI
 	Search     *SearchAPI
+	Contents   *ContentsAPI
+	Commits    *CommitAPI
+	Releases   *ReleaseAPI
+	Milestones *MilestoneAPI
 }
 
 // NewGitCodeAPI 创建一个新的GitCode API客户端
@@ -78,6 +97,16 @@ func NewGitCodeAPI(token string) (*GitCodeAPI, error) {
 		HTTPClient: &http.Client{
 			Timeout: timeout,
 		},
+		Retry: RetryPolicy{
+			MaxRetries: config.GlobalConfig.APIMaxRetries,
+			BaseDelay:  time.Duration(config.GlobalConfig.APIRetryBaseDelay) * time.Millisecond,
+			MaxDelay:   time.Duration(config.GlobalConfig.APIRetryMaxDelay) * time.Second,
+		},
+		Cache: config.GlobalCache,
+		breaker: newCircuitBreaker(
+			config.GlobalConfig.CircuitBreakerThreshold,
+			time.Duration(config.GlobalConfig.CircuitBreakerCooldown)*time.Second,
+		),
 	}
 	
 	// 初始化API子模块
@@ -86,6 +115,10 @@ func NewGitCodeAPI(token string) (*GitCodeAPI, error) {
 	client.Issues = NewIssueAPI(client)
 	client.Pulls = NewPullRequestAPI(client)
 	client.Search = NewSearchAPI(client)
+	client.Contents = NewContentsAPI(client)
+	client.Commits = NewCommitAPI(client)
+	client.Releases = NewReleaseAPI(client)
+	client.Milestones = NewMilestoneAPI(client)
 	
 	return client, nil
 }
@@ -94,6 +127,11 @@ func NewGitCodeAPI(token string) (*GitCodeAPI, error) {
 func (c *GitCodeAPI) buildURL(path string, params url.Values) string {
 	u := fmt.Sprintf("%s%s", c.BaseURL, path)
 	
+	// 分页Link头中给出的是完整URL，直接使用（调用方已确认与BaseURL同源）
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		u = path
+	}
+	
 	if params != nil && len(params) > 0 {
 		u = fmt.Sprintf("%s?%s", u, params.Encode())
 	}
@@ -117,38 +155,254 @@ func (c *GitCodeAPI) generateCacheKey(method, url string, body interface{}) stri
 	return key
 }
 
+// cacheClass 根据请求路径判断接口所属的缓存类别
+func (c *GitCodeAPI) cacheClass(path string) string {
+	path = strings.TrimPrefix(path, c.BaseURL)
+	if u, err := url.Parse(path); err == nil {
+		path = u.Path
+		// 分页Link头给出的完整URL可能包含BaseURL中的路径前缀
+		if base, err := url.Parse(c.BaseURL); err == nil {
+			path = strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+		}
+	}
+	
+	switch {
+	case strings.HasPrefix(path, "/search/"):
+		return config.CacheClassSearch
+	case strings.Contains(path, "/pulls"):
+		return config.CacheClassPull
+	case strings.Contains(path, "/issues"), strings.HasSuffix(path, "/labels"), strings.Contains(path, "/labels/"), strings.Contains(path, "/milestones"):
+		return config.CacheClassIssue
+	case strings.Contains(path, "/branches"), strings.Contains(path, "/commits"), strings.Contains(path, "/compare/"):
+		return config.CacheClassBranch
+	case strings.HasPrefix(path, "/repos/"), strings.HasPrefix(path, "/orgs/"):
+		return config.CacheClassRepo
+	case path == "/user", strings.HasPrefix(path, "/user/"), strings.HasPrefix(path, "/users/"):
+		return config.CacheClassUser
+	default:
+		return config.CacheClassDefault
+	}
+}
+
+// Stats 返回请求缓存和合并的统计数据
+func (c *GitCodeAPI) Stats() RequestStats {
+	entries, bytes := c.Cache.Stats()
+	return RequestStats{
+		CacheHits:     c.counters.cacheHits.Load(),
+		CacheMisses:   c.counters.cacheMisses.Load(),
+		Revalidations: c.counters.revalidations.Load(),
+		Deduplicated:  c.counters.deduplicated.Load(),
+		Upstream:      c.counters.upstream.Load(),
+		CacheEntries:  entries,
+		CacheBytes:    bytes,
+	}
+}
+
+// Response 表示API的原始响应
+type Response struct {
+	StatusCode int         // HTTP状态码
+	Header     http.Header // 响应头
+	Body       []byte      // 响应体
+}
+
+func init() {
+	// 磁盘缓存使用gob编码缓存值
+	gob.Register(&Response{})
+}
+
+// Size 估算响应占用的字节数，用于缓存容量统计
+func (r *Response) Size() int {
+	size := len(r.Body)
+	for k, values := range r.Header {
+		size += len(k)
+		for _, v := range values {
+			size += len(v)
+		}
+	}
+	return size
+}
+
 // Request 发送API请求
-func (c *GitCodeAPI) Request(method, path string, params url.Values, body interface{}) ([]byte, error) {
+func (c *GitCodeAPI) Request(ctx context.Context, method, path string, params url.Values, body interface{}) ([]byte, error) {
+	resp, err := c.do(ctx, method, path, params, body)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// do 发送API请求并返回包含响应头的完整响应
+// GET请求优先读取缓存，未命中时并发的相同请求会合并为一个上游请求
+func (c *GitCodeAPI) do(ctx context.Context, method, path string, params url.Values, body interface{}) (*Response, error) {
 	url := c.buildURL(path, params)
+	cacheKey := c.generateCacheKey(method, url, body)
+	
+	if method != "GET" {
+		return c.fetch(ctx, method, path, url, cacheKey, body)
+	}
 	
 	// 对于GET请求，尝试从缓存获取
-	cacheKey := c.generateCacheKey(method, url, body)
-	if method == "GET" {
-		if cachedData, found := config.GlobalCache.Get(cacheKey); found {
+	if !noCache(ctx) {
+		if item, found := c.Cache.Lookup(cacheKey); found && !item.Expired() {
+			c.counters.cacheHits.Add(1)
 			log.Printf("从缓存获取: %s %s", method, url)
-			return cachedData.([]byte), nil
+			return item.Value.(*Response), nil
 		}
 	}
+	c.counters.cacheMisses.Add(1)
 	
-	var bodyReader io.Reader
+	// 跳过缓存的请求要求读到最新数据，不能合并到可能在写操作之前发出的请求中
+	if noCache(ctx) {
+		return c.fetch(ctx, method, path, url, cacheKey, nil)
+	}
+	
+	resp, err, shared := c.inflight.do(ctx, cacheKey, func(ctx context.Context) (*Response, error) {
+		return c.fetch(ctx, method, path, url, cacheKey, nil)
+	})
+	if shared {
+		c.counters.deduplicated.Add(1)
+		log.Printf("合并相同的进行中请求: %s %s", method, url)
+	}
+	return resp, err
+}
+
+// fetch 向GitCode发送请求，处理条件请求、重试和缓存写入
+func (c *GitCodeAPI) fetch(ctx context.Context, method, path, url, cacheKey string, body interface{}) (*Response, error) {
+	// 已过期但带有ETag/Last-Modified的缓存通过条件请求重新验证
+	var cached *config.CacheItem
+	var conditional http.Header
+	var generation uint64
+	if method == "GET" {
+		generation = c.generations.current(strings.TrimPrefix(path, c.BaseURL))
+		if item, found := c.Cache.Lookup(cacheKey); found && item.Revalidatable() {
+			cached = item
+			conditional = http.Header{}
+			if item.ETag != "" {
+				conditional.Set("If-None-Match", item.ETag)
+			}
+			if item.LastModified != "" {
+				conditional.Set("If-Modified-Since", item.LastModified)
+			}
+		}
+	}
+	
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("序列化请求体失败: %w", err)
 		}
-		bodyReader = bytes.NewBuffer(jsonData)
 	}
 	
-	req, err := http.NewRequest(method, url, bodyReader)
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := c.breaker.allow(); err != nil {
+			return nil, err
+		}
+		
+		c.counters.upstream.Add(1)
+		resp, err := c.send(ctx, method, url, jsonData, conditional)
+		
+		// 调用方取消或超时，直接返回，不计入熔断统计
+		if err != nil && ctx.Err() != nil {
+			c.breaker.release()
+			return nil, err
+		}
+		
+		// 网络错误和5xx计入熔断统计，其余响应说明服务端可用
+		if err != nil || resp.StatusCode >= 500 {
+			c.breaker.failure()
+		} else {
+			c.breaker.success()
+		}
+		
+		// 304表示缓存内容仍然有效，延长有效期后直接返回缓存
+		if err == nil && resp.StatusCode == http.StatusNotModified && cached != nil {
+			c.counters.revalidations.Add(1)
+			log.Printf("缓存验证未修改: %s %s", method, url)
+			c.Cache.Refresh(cacheKey, config.CacheTTL(c.cacheClass(path)))
+			return cached.Value.(*Response), nil
+		}
+		
+		if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			// 对于GET请求，缓存响应及其验证信息；写操作成功后使相关缓存失效
+			if method == "GET" {
+				stored := c.generations.storeIf(strings.TrimPrefix(path, c.BaseURL), generation, func() {
+					c.Cache.SetItem(&config.CacheItem{
+						Key:          cacheKey,
+						Value:        resp,
+						ETag:         resp.Header.Get("ETag"),
+						LastModified: resp.Header.Get("Last-Modified"),
+					}, config.CacheTTL(c.cacheClass(path)))
+				})
+				if !stored {
+					log.Printf("请求期间缓存已失效，不缓存响应: %s %s", method, url)
+				}
+			} else {
+				c.invalidate(path)
+			}
+			return resp, nil
+		}
+		
+		// 判断是否需要重试
+		var header http.Header
+		retryable := isIdempotent(method) && attempt < c.Retry.MaxRetries
+		if err == nil {
+			header = resp.Header
+			retryable = retryable && isRetryableStatus(resp.StatusCode)
+		}
+		var delay time.Duration
+		if retryable {
+			delay, retryable = c.Retry.backoff(attempt, header)
+		}
+		if !retryable {
+			if err != nil {
+				return nil, err
+			}
+			return nil, newAPIError(resp.StatusCode, resp.Body)
+		}
+		
+		if err != nil {
+			log.Printf("API请求失败，%s后进行第%d次重试: %s %s: %v", delay, attempt+1, method, url, err)
+		} else {
+			log.Printf("API请求返回%d，%s后进行第%d次重试: %s %s", resp.StatusCode, delay, attempt+1, method, url)
+		}
+		
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send 发送一次HTTP请求并读取完整响应
+// header中的请求头会附加到请求上，例如条件请求头
+func (c *GitCodeAPI) send(ctx context.Context, method, url string, jsonData []byte, header http.Header) (*Response, error) {
+	var bodyReader io.Reader
+	if jsonData != nil {
+		bodyReader = bytes.NewReader(jsonData)
+	}
+	
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
 	
 	// 设置请求头
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	c.authorize(req)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "GitCode-MCP-Go-Client/1.0.0")
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
 	
 	log.Printf("API请求: %s %s", method, url)
 	
@@ -164,15 +418,21 @@ func (c *GitCodeAPI) Request(method, path string, params url.Values, body interf
 		return nil, fmt.Errorf("读取响应体失败: %w", err)
 	}
 	
-	// 处理响应状态码
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		// 对于GET请求，缓存响应
-		if method == "GET" {
-			config.GlobalCache.Set(cacheKey, respBody)
-		}
-		return respBody, nil
-	}
-	
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       respBody,
+	}, nil
+}
+
+// authorize 为请求设置认证和User-Agent请求头
+func (c *GitCodeAPI) authorize(req *http.Request) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	req.Header.Set("User-Agent", "GitCode-MCP-Go-Client/1.0.0")
+}
+
+// newAPIError 根据状态码和响应体创建API错误
+func newAPIError(statusCode int, respBody []byte) *APIError {
 	// 解析错误信息
 	var errorMessage string
 	var errorResponse map[string]interface{}
@@ -187,50 +447,209 @@ func (c *GitCodeAPI) Request(method, path string, params url.Values, body interf
 	}
 	
 	// 根据状态码创建特定错误
-	var apiErr *APIError
-	switch resp.StatusCode {
+	switch statusCode {
 	case http.StatusUnauthorized: // 401
-		apiErr = &APIError{Code: resp.StatusCode, Message: errorMessage, Err: ErrAuthFailed}
+		return &APIError{Code: statusCode, Message: errorMessage, Err: ErrAuthFailed}
 	case http.StatusForbidden: // 403
-		apiErr = &APIError{Code: resp.StatusCode, Message: errorMessage, Err: ErrPermissionDenied}
+		return &APIError{Code: statusCode, Message: errorMessage, Err: ErrPermissionDenied}
 	case http.StatusNotFound: // 404
-		apiErr = &APIError{Code: resp.StatusCode, Message: errorMessage, Err: ErrNotFound}
+		return &APIError{Code: statusCode, Message: errorMessage, Err: ErrNotFound}
+	case http.StatusConflict: // 409
+		return &APIError{Code: statusCode, Message: errorMessage, Err: ErrConflict}
 	case http.StatusUnprocessableEntity: // 422
-		apiErr = &APIError{Code: resp.StatusCode, Message: errorMessage, Err: ErrValidation}
+		return &APIError{Code: statusCode, Message: errorMessage, Err: ErrValidation}
 	case http.StatusTooManyRequests: // 429
-		apiErr = &APIError{Code: resp.StatusCode, Message: errorMessage, Err: ErrRateLimit}
-	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable: // 500, 502, 503
-		apiErr = &APIError{Code: resp.StatusCode, Message: errorMessage, Err: ErrServer}
+		return &APIError{Code: statusCode, Message: errorMessage, Err: ErrRateLimit}
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout: // 500, 502, 503, 504
+		return &APIError{Code: statusCode, Message: errorMessage, Err: ErrServer}
 	default:
-		apiErr = &APIError{Code: resp.StatusCode, Message: errorMessage, Err: ErrUnknown}
+		return &APIError{Code: statusCode, Message: errorMessage, Err: ErrUnknown}
 	}
-	
-	return nil, apiErr
 }
 
 // GET 发送GET请求
-func (c *GitCodeAPI) GET(path string, params url.Values) ([]byte, error) {
-	return c.Request("GET", path, params, nil)
+func (c *GitCodeAPI) GET(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	return c.Request(ctx, "GET", path, params, nil)
 }
 
 // POST 发送POST请求
-func (c *GitCodeAPI) POST(path string, params url.Values, body interface{}) ([]byte, error) {
-	return c.Request("POST", path, params, body)
+func (c *GitCodeAPI) POST(ctx context.Context, path string, params url.Values, body interface{}) ([]byte, error) {
+	return c.Request(ctx, "POST", path, params, body)
 }
 
 // PUT 发送PUT请求
-func (c *GitCodeAPI) PUT(path string, params url.Values, body interface{}) ([]byte, error) {
-	return c.Request("PUT", path, params, body)
+func (c *GitCodeAPI) PUT(ctx context.Context, path string, params url.Values, body interface{}) ([]byte, error) {
+	return c.Request(ctx, "PUT", path, params, body)
 }
 
 // DELETE 发送DELETE请求
-func (c *GitCodeAPI) DELETE(path string, params url.Values) ([]byte, error) {
-	return c.Request("DELETE", path, params, nil)
+func (c *GitCodeAPI) DELETE(ctx context.Context, path string, params url.Values) ([]byte, error) {
+	return c.Request(ctx, "DELETE", path, params, nil)
 }
 
 // PATCH 发送PATCH请求
-func (c *GitCodeAPI) PATCH(path string, params url.Values, body interface{}) ([]byte, error) {
-	return c.Request("PATCH", path, params, body)
+func (c *GitCodeAPI) PATCH(ctx context.Context, path string, params url.Values, body interface{}) ([]byte, error) {
+	return c.Request(ctx, "PATCH", path, params, body)
+}
+
+// 分页相关默认值
+const (
+	DefaultPerPage = 20  // 默认每页数量
+	MaxPerPage     = 100 // GitCode允许的最大每页数量
+)
+
+// ListOptions 表示列表请求的分页参数
+// 页码始终指GitCode接口的页，客户端补充过滤时一页返回的条目可能少于PerPage
+type ListOptions struct {
+	Page       int // 起始页码，从1开始，从该页开始获取
+	PerPage    int // 每页数量，默认20，最大100
+	MaxItems   int // 最多返回的条目数，0表示获取起始页之后的所有页
+	Offset     int // 起始页中跳过的条目数（按过滤后计），用于从上次截断处继续
+	NextPage   int // 由列表请求设置：继续获取时应使用的页码，0表示没有更多数据
+	NextOffset int // 由列表请求设置：继续获取时应使用的Offset，NextPage有未返回的条目时大于0
+}
+
+// linkNextPattern 匹配Link响应头中的下一页地址
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// nextPageURL 从Link响应头中解析下一页地址
+func nextPageURL(header http.Header) string {
+	for _, link := range header.Values("Link") {
+		if m := linkNextPattern.FindStringSubmatch(link); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// sameOrigin 判断URL的协议和主机是否与BaseURL相同
+func (c *GitCodeAPI) sameOrigin(rawURL string) bool {
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == base.Scheme && u.Host == base.Host
+}
+
+// decodeList 将响应体直接解析为列表
+func decodeList[T any](body []byte) ([]T, error) {
+	var items []T
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// decodeSearchItems 解析搜索接口返回的items字段
+func decodeSearchItems[T any](body []byte) ([]T, error) {
+	var result struct {
+		TotalCount int `json:"total_count"`
+		Items      []T `json:"items"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Items, nil
+}
+
+// paginate 依次获取列表接口的各页数据
+// 优先跟随Link响应头中的next地址，没有Link头时递增page参数，
+// 直到某页不足PerPage条、达到MaxItems或没有更多数据为止
+func paginate[T any](ctx context.Context, c *GitCodeAPI, path string, params url.Values, opts *ListOptions, decode func([]byte) ([]T, error)) ([]T, error) {
+	return paginateFiltered(ctx, c, path, params, opts, decode, nil)
+}
+
+// paginateFiltered 与paginate相同，但只保留keep返回true的条目，用于在客户端补充服务端不支持的过滤条件
+// 页码和翻页是否结束仍按服务端返回的条目数判断；
+// 返回前将opts.NextPage和opts.NextOffset设为继续获取的位置，因MaxItems截断的页还有未返回的条目时
+// NextPage为该页本身，NextOffset为该页已返回的条目数，继续获取时不会重复返回
+func paginateFiltered[T any](ctx context.Context, c *GitCodeAPI, path string, params url.Values, opts *ListOptions, decode func([]byte) ([]T, error), keep func(T) bool) ([]T, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+	
+	page := opts.Page
+	if page <= 0 {
+		page = 1
+	}
+	perPage := opts.PerPage
+	if perPage <= 0 {
+		perPage = DefaultPerPage
+	}
+	if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+	maxItems := opts.MaxItems
+	opts.NextPage = 0
+	opts.NextOffset = 0
+	skip := opts.Offset
+	
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	query.Set("per_page", strconv.Itoa(perPage))
+	
+	items := []T{}
+	next := ""
+	for {
+		var resp *Response
+		var err error
+		if next != "" {
+			resp, err = c.do(ctx, "GET", next, nil, nil)
+		} else {
+			query.Set("page", strconv.Itoa(page))
+			resp, err = c.do(ctx, "GET", path, query, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+		
+		pageItems, err := decode(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("解析第%d页数据失败: %w", page, err)
+		}
+		if len(pageItems) == 0 {
+			return items, nil
+		}
+		// 起始页中跳过上次已返回的条目
+		pageStart := len(items)
+		skipped := 0
+		for _, item := range pageItems {
+			if keep == nil || keep(item) {
+				if skipped < skip {
+					skipped++
+					continue
+				}
+				items = append(items, item)
+			}
+		}
+		skip = 0
+		
+		next = nextPageURL(resp.Header)
+		more := next != "" || len(pageItems) >= perPage
+		if next != "" && !c.sameOrigin(next) {
+			// 不向其他主机发送令牌，改为按页码请求
+			next = ""
+		}
+		if maxItems > 0 && len(items) >= maxItems {
+			if len(items) > maxItems {
+				opts.NextPage = page
+				opts.NextOffset = skipped + maxItems - pageStart
+			} else if more {
+				opts.NextPage = page + 1
+			}
+			return items[:maxItems], nil
+		}
+		if !more {
+			return items, nil
+		}
+		page++
+	}
 }
 
 // BaseAPI 所有API模块的基类
@@ -259,6 +678,22 @@ type SearchAPI struct {
 	BaseAPI
 }
 
+type ContentsAPI struct {
+	BaseAPI
+}
+
+type CommitAPI struct {
+	BaseAPI
+}
+
+type ReleaseAPI struct {
+	BaseAPI
+}
+
+type MilestoneAPI struct {
+	BaseAPI
+}
+
 // 创建各API子模块的实例
 func NewRepositoryAPI(client *GitCodeAPI) *RepositoryAPI {
 	return &RepositoryAPI{BaseAPI{Client: client}}
@@ -278,4 +713,20 @@ func NewPullRequestAPI(client *GitCodeAPI) *PullRequestAPI {
 
 func NewSearchAPI(client *GitCodeAPI) *SearchAPI {
 	return &SearchAPI{BaseAPI{Client: client}}
-} 
\ No newline at end of file
+}
+
+func NewContentsAPI(client *GitCodeAPI) *ContentsAPI {
+	return &ContentsAPI{BaseAPI{Client: client}}
+}
+
+func NewCommitAPI(client *GitCodeAPI) *CommitAPI {
+	return &CommitAPI{BaseAPI{Client: client}}
+}
+
+func NewReleaseAPI(client *GitCodeAPI) *ReleaseAPI {
+	return &ReleaseAPI{BaseAPI{Client: client}}
+}
+
+func NewMilestoneAPI(client *GitCodeAPI) *MilestoneAPI {
+	return &MilestoneAPI{BaseAPI{Client: client}}
+}
@@ -0,0 +1,393 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gitcode-org-com/gitcode-mcp/config"
+)
+
+// withCacheTTL 在测试期间设置默认缓存有效期
+func withCacheTTL(t *testing.T, seconds int) {
+	t.Helper()
+	saved := config.GlobalConfig.CacheTTLs
+	config.GlobalConfig.CacheTTLs = map[string]int{config.CacheClassDefault: seconds}
+	t.Cleanup(func() { config.GlobalConfig.CacheTTLs = saved })
+}
+
+// expireCached 使GET缓存项立即过期，保留其验证信息
+func expireCached(t *testing.T, c *GitCodeAPI, path string) string {
+	t.Helper()
+	key := c.generateCacheKey("GET", c.buildURL(path, nil), nil)
+	item, found := c.Cache.Lookup(key)
+	if !found {
+		t.Fatalf("%s 没有被缓存", path)
+	}
+	c.Cache.SetItem(item, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	return key
+}
+
+func TestConditionalRequestNotModified(t *testing.T) {
+	withCacheTTL(t, 60)
+	tests := []struct {
+		name       string
+		validator  map[string]string
+		wantHeader string
+		wantValue  string
+	}{
+		{"ETag", map[string]string{"ETag": `"v1"`}, "If-None-Match", `"v1"`},
+		{"Last-Modified", map[string]string{"Last-Modified": "Mon, 02 Jan 2006 15:04:05 GMT"}, "If-Modified-Since", "Mon, 02 Jan 2006 15:04:05 GMT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScriptedServer(t,
+				scriptedResponse{status: 200, header: tt.validator, body: `{"name":"cached"}`},
+				scriptedResponse{status: 304},
+			)
+			c := newTestClient(t, s, RetryPolicy{})
+			ctx := context.Background()
+
+			if _, err := c.GET(ctx, "/repos/o/r", nil); err != nil {
+				t.Fatalf("GET() error = %v", err)
+			}
+			key := expireCached(t, c, "/repos/o/r")
+
+			// 过期的缓存通过条件请求重新验证，304时返回缓存的响应体
+			body, err := c.GET(ctx, "/repos/o/r", nil)
+			if err != nil {
+				t.Fatalf("重新验证 error = %v", err)
+			}
+			if string(body) != `{"name":"cached"}` {
+				t.Fatalf("body = %s, want cached body", body)
+			}
+			if got := s.request(1).Header.Get(tt.wantHeader); got != tt.wantValue {
+				t.Fatalf("%s = %q, want %q", tt.wantHeader, got, tt.wantValue)
+			}
+			if stats := c.Stats(); stats.Revalidations != 1 {
+				t.Fatalf("Revalidations = %d, want 1", stats.Revalidations)
+			}
+
+			// 304刷新了缓存有效期，后续请求直接命中缓存
+			if item, _ := c.Cache.Lookup(key); item == nil || item.Expired() {
+				t.Fatal("304后缓存项应重新生效")
+			}
+			if _, err := c.GET(ctx, "/repos/o/r", nil); err != nil {
+				t.Fatalf("GET() error = %v", err)
+			}
+			if n := s.count(); n != 2 {
+				t.Fatalf("请求次数 = %d, want 2", n)
+			}
+		})
+	}
+}
+
+func TestConditionalRequestModified(t *testing.T) {
+	withCacheTTL(t, 60)
+	s := newScriptedServer(t,
+		scriptedResponse{status: 200, header: map[string]string{"ETag": `"v1"`}, body: `{"name":"old"}`},
+		scriptedResponse{status: 200, header: map[string]string{"ETag": `"v2"`}, body: `{"name":"new"}`},
+	)
+	c := newTestClient(t, s, RetryPolicy{})
+	ctx := context.Background()
+
+	if _, err := c.GET(ctx, "/repos/o/r", nil); err != nil {
+		t.Fatalf("GET() error = %v", err)
+	}
+	key := expireCached(t, c, "/repos/o/r")
+
+	// 内容已变化时使用新的响应并替换缓存
+	body, err := c.GET(ctx, "/repos/o/r", nil)
+	if err != nil {
+		t.Fatalf("GET() error = %v", err)
+	}
+	if string(body) != `{"name":"new"}` {
+		t.Fatalf("body = %s, want new body", body)
+	}
+	item, _ := c.Cache.Lookup(key)
+	if item == nil || item.ETag != `"v2"` || item.Expired() {
+		t.Fatalf("缓存项 = %+v, want ETag \"v2\"", item)
+	}
+}
+
+func TestUnconditionalRequestWithoutValidator(t *testing.T) {
+	withCacheTTL(t, 60)
+	s := newScriptedServer(t, scriptedResponse{status: 200, body: `{}`})
+	c := newTestClient(t, s, RetryPolicy{})
+
+	if _, err := c.GET(context.Background(), "/repos/o/r", nil); err != nil {
+		t.Fatalf("GET() error = %v", err)
+	}
+	if h := s.request(0).Header; h.Get("If-None-Match") != "" || h.Get("If-Modified-Since") != "" {
+		t.Fatalf("首次请求不应带条件请求头: %v", h)
+	}
+}
+
+func TestPaginateFiltered(t *testing.T) {
+	full := scriptedResponse{status: 200, body: `[1,2,3]`}
+	short := scriptedResponse{status: 200, body: `[4]`}
+	odd := func(n int) bool { return n%2 == 1 }
+
+	tests := []struct {
+		name         string
+		opts         ListOptions
+		keep         func(int) bool
+		want         []int
+		wantPages    []string
+		wantNextPage int
+	}{
+		{"获取全部页", ListOptions{PerPage: 3}, nil, []int{1, 2, 3, 1, 2, 3, 4}, []string{"1", "2", "3"}, 0},
+		{"指定Page时从该页获取到最后一页", ListOptions{Page: 2, PerPage: 3}, odd, []int{1, 3}, []string{"2", "3"}, 0},
+		{"指定Page和MaxItems时从该页继续翻页", ListOptions{Page: 2, PerPage: 3, MaxItems: 4}, odd, []int{1, 3}, []string{"2", "3"}, 0},
+		{"MaxItems截断的页从该页继续", ListOptions{PerPage: 3, MaxItems: 2}, nil, []int{1, 2}, []string{"1"}, 1},
+		{"最后一页不足PerPage条", ListOptions{Page: 3, PerPage: 3}, nil, []int{4}, []string{"3"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script := []scriptedResponse{full, full, short}
+			if tt.opts.Page > 1 {
+				script = script[tt.opts.Page-1:]
+			}
+			s := newScriptedServer(t, script...)
+			c := newTestClient(t, s, RetryPolicy{})
+			opts := tt.opts
+
+			got, err := paginateFiltered(context.Background(), c, "/items", nil, &opts, decodeList[int], tt.keep)
+			if err != nil {
+				t.Fatalf("paginateFiltered() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("paginateFiltered() = %v, want %v", got, tt.want)
+			}
+			var pages []string
+			for i := 0; i < s.count(); i++ {
+				pages = append(pages, s.request(i).URL.Query().Get("page"))
+			}
+			if !slices.Equal(pages, tt.wantPages) {
+				t.Fatalf("请求的页码 = %v, want %v", pages, tt.wantPages)
+			}
+			if opts.NextPage != tt.wantNextPage {
+				t.Fatalf("NextPage = %d, want %d", opts.NextPage, tt.wantNextPage)
+			}
+		})
+	}
+}
+
+func TestPaginateLinkHeader(t *testing.T) {
+	other := newScriptedServer(t, scriptedResponse{status: 200, body: `[9]`})
+	tests := []struct {
+		name      string
+		link      func(base string) string
+		wantQuery string
+	}{
+		{"同源的Link直接使用", func(base string) string { return base + "/items?page=2&cursor=abc" }, "page=2&cursor=abc"},
+		{"其他主机的Link改为按页码请求", func(string) string { return other.URL + "/items?page=2" }, "page=2&per_page=3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScriptedServer(t, scriptedResponse{status: 200, body: `[1,2,3]`}, scriptedResponse{status: 200, body: `[4]`})
+			s.responses[0].header = map[string]string{"Link": fmt.Sprintf(`<%s>; rel="next"`, tt.link(s.URL))}
+			c := newTestClient(t, s, RetryPolicy{})
+
+			got, err := paginate(context.Background(), c, "/items", nil, &ListOptions{PerPage: 3}, decodeList[int])
+			if err != nil {
+				t.Fatalf("paginate() error = %v", err)
+			}
+			if !slices.Equal(got, []int{1, 2, 3, 4}) {
+				t.Fatalf("paginate() = %v", got)
+			}
+			if n := s.count(); n != 2 {
+				t.Fatalf("请求次数 = %d, want 2", n)
+			}
+			if q := s.request(1).URL.RawQuery; q != tt.wantQuery {
+				t.Fatalf("第二页查询参数 = %q, want %q", q, tt.wantQuery)
+			}
+			if auth := s.request(1).Header.Get("Authorization"); auth == "" {
+				t.Fatal("第二页请求应带令牌")
+			}
+		})
+	}
+	if n := other.count(); n != 0 {
+		t.Fatalf("其他主机收到%d个请求，令牌可能泄露", n)
+	}
+}
+
+func TestPaginateResumeWithoutDuplicates(t *testing.T) {
+	pages := []string{`[1,2,3]`, `[4,5,6]`, `[7]`}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 || page > len(pages) {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(pages[page-1]))
+	}))
+	t.Cleanup(ts.Close)
+	c := &GitCodeAPI{BaseURL: ts.URL, HTTPClient: ts.Client(), Cache: config.NewCacheManager(time.Minute, 0, 0, 0, 0)}
+	t.Cleanup(c.Cache.Close)
+
+	// 每次最多取2条，按返回的位置继续，直到没有更多数据
+	var got []int
+	opts := ListOptions{PerPage: 3, MaxItems: 2}
+	for i := 0; i < 10; i++ {
+		items, err := paginate(context.Background(), c, "/items", nil, &opts, decodeList[int])
+		if err != nil {
+			t.Fatalf("paginate() error = %v", err)
+		}
+		got = append(got, items...)
+		if opts.NextPage == 0 {
+			break
+		}
+		opts = ListOptions{Page: opts.NextPage, Offset: opts.NextOffset, PerPage: 3, MaxItems: 2}
+	}
+	if want := []int{1, 2, 3, 4, 5, 6, 7}; !slices.Equal(got, want) {
+		t.Fatalf("分次获取的结果 = %v, want %v", got, want)
+	}
+
+	// 截断处的位置指向该页中第一个未返回的条目
+	opts = ListOptions{Page: 2, Offset: 1, PerPage: 3, MaxItems: 1}
+	items, err := paginate(context.Background(), c, "/items", nil, &opts, decodeList[int])
+	if err != nil {
+		t.Fatalf("paginate() error = %v", err)
+	}
+	if !slices.Equal(items, []int{5}) || opts.NextPage != 2 || opts.NextOffset != 2 {
+		t.Fatalf("paginate() = %v, NextPage = %d, NextOffset = %d, want [5], 2, 2", items, opts.NextPage, opts.NextOffset)
+	}
+}
+
+// blockingServer 在release关闭前阻塞GET请求，每收到一个GET请求向arrived发送一次
+type blockingServer struct {
+	*httptest.Server
+	arrived chan string
+	release chan struct{}
+	gets    atomic.Int32
+}
+
+func newBlockingServer(t *testing.T) *blockingServer {
+	t.Helper()
+	s := &blockingServer{arrived: make(chan string, 16), release: make(chan struct{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			n := s.gets.Add(1)
+			s.arrived <- r.URL.Path
+			<-s.release
+			fmt.Fprintf(w, `{"n":%d}`, n)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// waitArrived 等待服务器收到n个GET请求
+func (s *blockingServer) waitArrived(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.arrived:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("等待第%d个GET请求超时", i+1)
+		}
+	}
+}
+
+func newBlockingClient(t *testing.T, s *blockingServer) *GitCodeAPI {
+	t.Helper()
+	withCacheTTL(t, 60)
+	cache := config.NewCacheManager(time.Minute, 0, 0, 0, time.Hour)
+	t.Cleanup(cache.Close)
+	return &GitCodeAPI{Token: "test-token", BaseURL: s.URL, HTTPClient: s.Client(), Cache: cache}
+}
+
+func TestInflightCoalescing(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantGets  int32
+		wantDedup int64
+	}{
+		{"相同的并发请求合并为一个", context.Background(), 1, 1},
+		{"跳过缓存的请求不合并", WithNoCache(context.Background()), 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newBlockingServer(t)
+			c := newBlockingClient(t, s)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := c.Request(tt.ctx, "GET", "/repos/o/r", nil, nil); err != nil {
+						t.Errorf("Request() error = %v", err)
+					}
+				}()
+			}
+			s.waitArrived(t, int(tt.wantGets))
+			// 等待第二个调用方进入合并或发出请求
+			time.Sleep(20 * time.Millisecond)
+			close(s.release)
+			wg.Wait()
+
+			if got := s.gets.Load(); got != tt.wantGets {
+				t.Fatalf("上游GET请求数 = %d, want %d", got, tt.wantGets)
+			}
+			if got := c.Stats().Deduplicated; got != tt.wantDedup {
+				t.Fatalf("Deduplicated = %d, want %d", got, tt.wantDedup)
+			}
+		})
+	}
+}
+
+func TestInvalidationDuringFetch(t *testing.T) {
+	s := newBlockingServer(t)
+	c := newBlockingClient(t, s)
+
+	// 两个GET请求在写操作之前发出，写操作只使同一仓库的缓存失效
+	var wg sync.WaitGroup
+	for _, path := range []string{"/repos/o/r/issues", "/repos/o/other"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Request(context.Background(), "GET", path, nil, nil); err != nil {
+				t.Errorf("Request(%s) error = %v", path, err)
+			}
+		}()
+	}
+	s.waitArrived(t, 2)
+	if _, err := c.Request(context.Background(), "POST", "/repos/o/r/issues", nil, map[string]string{"title": "t"}); err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	close(s.release)
+	wg.Wait()
+
+	cached := func(path string) bool {
+		_, found := c.Cache.Lookup(c.generateCacheKey("GET", c.buildURL(path, nil), nil))
+		return found
+	}
+	if cached("/repos/o/r/issues") {
+		t.Fatal("写操作之前发出的GET请求不应在失效后写入缓存")
+	}
+	if !cached("/repos/o/other") {
+		t.Fatal("其他仓库的GET请求应正常写入缓存")
+	}
+
+	// 失效之后发出的请求照常缓存
+	if _, err := c.Request(context.Background(), "GET", "/repos/o/r/issues", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !cached("/repos/o/r/issues") {
+		t.Fatal("失效之后发出的GET请求应写入缓存")
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Commit 表示提交
+type Commit struct {
+	SHA       string         `json:"sha"`
+	URL       string         `json:"url"`
+	HTMLURL   string         `json:"html_url"`
+	Commit    CommitDetail   `json:"commit"`
+	Author    *User          `json:"author"`    // 作者对应的GitCode用户，无法关联时为空
+	Committer *User          `json:"committer"` // 提交者对应的GitCode用户，无法关联时为空
+	Parents   []CommitParent `json:"parents"`
+	Stats     *CommitStats   `json:"stats,omitempty"` // 仅获取单个提交时返回
+	Files     []CommitFile   `json:"files,omitempty"` // 仅获取单个提交时返回
+}
+
+// CommitDetail 表示提交的Git信息
+type CommitDetail struct {
+	Message   string    `json:"message"`
+	Author    Author    `json:"author"`
+	Committer Author    `json:"committer"`
+	Tree      GitObject `json:"tree"`
+}
+
+// CommitParent 表示父提交
+type CommitParent struct {
+	SHA string `json:"sha"`
+	URL string `json:"url"`
+}
+
+// CommitStats 表示提交的增删行数统计
+type CommitStats struct {
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+	Total     int `json:"total"`
+}
+
+// CommitFile 表示提交或比较结果中修改的文件
+type CommitFile struct {
+	SHA              string `json:"sha"`
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename,omitempty"`
+	Status           string `json:"status"` // added、modified、removed、renamed等
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Changes          int    `json:"changes"`
+	Patch            string `json:"patch,omitempty"`
+	BlobURL          string `json:"blob_url"`
+	RawURL           string `json:"raw_url"`
+}
+
+// Comparison 表示两个引用的比较结果
+type Comparison struct {
+	Status          string       `json:"status"` // ahead、behind、diverged或identical
+	AheadBy         int          `json:"ahead_by"`
+	BehindBy        int          `json:"behind_by"`
+	TotalCommits    int          `json:"total_commits"`
+	BaseCommit      *Commit      `json:"base_commit"`
+	MergeBaseCommit *Commit      `json:"merge_base_commit"`
+	Commits         []Commit     `json:"commits"`
+	Files           []CommitFile `json:"files"`
+	URL             string       `json:"url"`
+	HTMLURL         string       `json:"html_url"`
+	DiffURL         string       `json:"diff_url"`
+	PatchURL        string       `json:"patch_url"`
+}
+
+// ListCommitsOptions 表示列出提交时的过滤条件，零值表示不过滤
+type ListCommitsOptions struct {
+	SHA    string    // 起始的分支名、标签名或提交SHA，默认为仓库的默认分支
+	Path   string    // 只返回修改了该路径的提交
+	Author string    // 作者的用户名或邮箱
+	Since  time.Time // 只返回该时间之后的提交
+	Until  time.Time // 只返回该时间之前的提交
+}
+
+// values 生成查询参数
+func (opts *ListCommitsOptions) values() url.Values {
+	values := url.Values{}
+	if opts == nil {
+		return values
+	}
+	if opts.SHA != "" {
+		values.Set("sha", opts.SHA)
+	}
+	if opts.Path != "" {
+		values.Set("path", opts.Path)
+	}
+	if opts.Author != "" {
+		values.Set("author", opts.Author)
+	}
+	if !opts.Since.IsZero() {
+		values.Set("since", opts.Since.UTC().Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		values.Set("until", opts.Until.UTC().Format(time.RFC3339))
+	}
+	return values
+}
+
+// ListCommits 列出仓库的提交
+func (api *CommitAPI) ListCommits(ctx context.Context, owner, repo string, filter *ListCommitsOptions, opts *ListOptions) ([]Commit, error) {
+	path := fmt.Sprintf("/repos/%s/%s/commits", owner, repo)
+	commits, err := paginate(ctx, api.Client, path, filter.values(), opts, decodeList[Commit])
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+// GetCommit 获取单个提交，包含增删行数统计和每个文件的patch
+func (api *CommitAPI) GetCommit(ctx context.Context, owner, repo, sha string) (*Commit, error) {
+	path := fmt.Sprintf("/repos/%s/%s/commits/%s", owner, repo, escapePath(sha))
+	resp, err := api.Client.GET(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var commit Commit
+	if err := json.Unmarshal(resp, &commit); err != nil {
+		return nil, fmt.Errorf("解析提交详情失败: %w", err)
+	}
+
+	return &commit, nil
+}
+
+// CompareRefs 比较两个引用，返回head相对base领先和落后的提交数、提交列表和修改的文件
+func (api *CommitAPI) CompareRefs(ctx context.Context, owner, repo, base, head string) (*Comparison, error) {
+	path := fmt.Sprintf("/repos/%s/%s/compare/%s...%s", owner, repo, escapePath(base), escapePath(head))
+	resp, err := api.Client.GET(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var comparison Comparison
+	if err := json.Unmarshal(resp, &comparison); err != nil {
+		return nil, fmt.Errorf("解析比较结果失败: %w", err)
+	}
+
+	return &comparison, nil
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// FileContent 表示仓库中的文件或目录项
+type FileContent struct {
+	Type        string `json:"type"` // file、dir、symlink或submodule
+	Encoding    string `json:"encoding,omitempty"`
+	Size        int    `json:"size"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Content     string `json:"content,omitempty"`
+	SHA         string `json:"sha"`
+	URL         string `json:"url"`
+	HTMLURL     string `json:"html_url"`
+	DownloadURL string `json:"download_url"`
+}
+
+// Decode 返回文件的原始内容，GitCode默认以base64编码返回文件内容
+func (f *FileContent) Decode() ([]byte, error) {
+	if f.Encoding != "base64" {
+		return []byte(f.Content), nil
+	}
+
+	// base64内容中可能包含换行
+	data, err := base64.StdEncoding.DecodeString(strings.NewReplacer("\n", "", "\r", "").Replace(f.Content))
+	if err != nil {
+		return nil, fmt.Errorf("解码文件内容失败: %w", err)
+	}
+	return data, nil
+}
+
+// TreeEntry 表示Git树中的一项
+type TreeEntry struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Type string `json:"type"` // blob、tree或commit
+	SHA  string `json:"sha"`
+	Size int    `json:"size,omitempty"`
+	URL  string `json:"url"`
+}
+
+// Tree 表示Git树
+type Tree struct {
+	SHA       string      `json:"sha"`
+	URL       string      `json:"url"`
+	Tree      []TreeEntry `json:"tree"`
+	Truncated bool        `json:"truncated"`
+}
+
+// escapePath 对仓库内的文件路径逐段进行URL编码
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// IsBinary 判断内容是否为二进制数据
+// 与git的判断方式类似，检查开头部分是否包含NUL字节或不是合法的UTF-8
+func IsBinary(data []byte) bool {
+	sample := data
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+		// 截断处可能落在多字节字符中间
+		for i := 0; i < utf8.UTFMax && len(sample) > 0 && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+	return bytes.IndexByte(sample, 0) >= 0 || !utf8.Valid(sample)
+}
+
+// GetContents 获取路径对应的内容，文件返回单个FileContent，目录返回目录项列表
+// ref为空时使用仓库的默认分支
+func (api *ContentsAPI) GetContents(ctx context.Context, owner, repo, path, ref string) (*FileContent, []FileContent, error) {
+	apiPath := fmt.Sprintf("/repos/%s/%s/contents/%s", owner, repo, escapePath(path))
+	values := url.Values{}
+	if ref != "" {
+		values.Set("ref", ref)
+	}
+
+	resp, err := api.Client.GET(ctx, apiPath, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 目录返回数组，文件返回对象
+	if trimmed := bytes.TrimSpace(resp); len(trimmed) > 0 && trimmed[0] == '[' {
+		var entries []FileContent
+		if err := json.Unmarshal(resp, &entries); err != nil {
+			return nil, nil, fmt.Errorf("解析目录内容失败: %w", err)
+		}
+		return nil, entries, nil
+	}
+
+	var file FileContent
+	if err := json.Unmarshal(resp, &file); err != nil {
+		return nil, nil, fmt.Errorf("解析文件内容失败: %w", err)
+	}
+	return &file, nil, nil
+}
+
+// GetFile 获取指定引用下的文件内容
+func (api *ContentsAPI) GetFile(ctx context.Context, owner, repo, path, ref string) (*FileContent, error) {
+	file, _, err := api.GetContents(ctx, owner, repo, path, ref)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%s 是目录而不是文件", path)
+	}
+	return file, nil
+}
+
+// ListDirectory 列出指定引用下目录中的文件和子目录
+func (api *ContentsAPI) ListDirectory(ctx context.Context, owner, repo, path, ref string) ([]FileContent, error) {
+	file, entries, err := api.GetContents(ctx, owner, repo, path, ref)
+	if err != nil {
+		return nil, err
+	}
+	if file != nil {
+		return nil, fmt.Errorf("%s 是文件而不是目录", path)
+	}
+	return entries, nil
+}
+
+// GetTree 获取Git树，sha可以是树或提交的SHA，也可以是分支名；recursive为true时递归获取所有子树
+func (api *ContentsAPI) GetTree(ctx context.Context, owner, repo, sha string, recursive bool) (*Tree, error) {
+	path := fmt.Sprintf("/repos/%s/%s/git/trees/%s", owner, repo, escapePath(sha))
+	values := url.Values{}
+	if recursive {
+		values.Set("recursive", "1")
+	}
+
+	resp, err := api.Client.GET(ctx, path, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree Tree
+	if err := json.Unmarshal(resp, &tree); err != nil {
+		return nil, fmt.Errorf("解析Git树失败: %w", err)
+	}
+
+	return &tree, nil
+}
+
+// FileCommit 表示文件写操作产生的提交
+type FileCommit struct {
+	SHA       string `json:"sha"`
+	Message   string `json:"message"`
+	URL       string `json:"url"`
+	HTMLURL   string `json:"html_url"`
+	Author    Author `json:"author"`
+	Committer Author `json:"committer"`
+}
+
+// FileCommitResult 表示创建、更新或删除文件的结果，删除文件时Content为空
+type FileCommitResult struct {
+	Content *FileContent `json:"content"`
+	Commit  FileCommit   `json:"commit"`
+}
+
+// FileOptions 表示写文件时的提交参数
+type FileOptions struct {
+	Message   string  // 提交信息
+	Branch    string  // 目标分支，为空时使用默认分支
+	SHA       string  // 文件当前的blob SHA，更新和删除时必填，用于检测冲突
+	Author    *Author // 作者，为空时使用令牌对应的用户
+	Committer *Author // 提交者，为空时使用令牌对应的用户
+}
+
+// body 生成写文件请求的请求体
+func (opts *FileOptions) body() map[string]interface{} {
+	body := map[string]interface{}{
+		"message": opts.Message,
+	}
+	if opts.Branch != "" {
+		body["branch"] = opts.Branch
+	}
+	if opts.SHA != "" {
+		body["sha"] = opts.SHA
+	}
+	if opts.Author != nil {
+		body["author"] = map[string]string{"name": opts.Author.Name, "email": opts.Author.Email}
+	}
+	if opts.Committer != nil {
+		body["committer"] = map[string]string{"name": opts.Committer.Name, "email": opts.Committer.Email}
+	}
+	return body
+}
+
+// checkFileSHA 在写入前检查文件在目标分支上的当前状态，避免覆盖其他人的修改
+// sha为空表示期望文件不存在
+func (api *ContentsAPI) checkFileSHA(ctx context.Context, owner, repo, path, branch, sha string) error {
+	current, err := api.GetFile(WithNoCache(ctx), owner, repo, path, branch)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			if sha == "" {
+				return nil
+			}
+			return fmt.Errorf("%w: 文件 %s 不存在", ErrNotFound, path)
+		}
+		return err
+	}
+
+	if sha == "" {
+		return fmt.Errorf("%w: 文件 %s 已存在（SHA %s），请使用更新操作", ErrConflict, path, current.SHA)
+	}
+	if current.SHA != sha {
+		return fmt.Errorf("%w: 文件 %s 已被修改，当前SHA为 %s，与提供的 %s 不一致，请重新获取文件后再修改", ErrConflict, path, current.SHA, sha)
+	}
+	return nil
+}
+
+// writeFile 发送写文件请求并解析结果
+func (api *ContentsAPI) writeFile(ctx context.Context, method, owner, repo, path string, body map[string]interface{}) (*FileCommitResult, error) {
+	apiPath := fmt.Sprintf("/repos/%s/%s/contents/%s", owner, repo, escapePath(path))
+	resp, err := api.Client.Request(ctx, method, apiPath, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result FileCommitResult
+	if len(bytes.TrimSpace(resp)) > 0 {
+		if err := json.Unmarshal(resp, &result); err != nil {
+			return nil, fmt.Errorf("解析提交结果失败: %w", err)
+		}
+	}
+	return &result, nil
+}
+
+// CreateFile 在分支上创建文件，文件已存在时返回ErrConflict
+func (api *ContentsAPI) CreateFile(ctx context.Context, owner, repo, path string, content []byte, opts *FileOptions) (*FileCommitResult, error) {
+	if opts == nil || opts.Message == "" {
+		return nil, fmt.Errorf("%w: 提交信息不能为空", ErrValidation)
+	}
+	if err := api.checkFileSHA(ctx, owner, repo, path, opts.Branch, ""); err != nil {
+		return nil, err
+	}
+
+	body := opts.body()
+	delete(body, "sha")
+	body["content"] = base64.StdEncoding.EncodeToString(content)
+	return api.writeFile(ctx, http.MethodPost, owner, repo, path, body)
+}
+
+// UpdateFile 更新分支上的文件，opts.SHA必须与文件的当前SHA一致，否则返回ErrConflict
+func (api *ContentsAPI) UpdateFile(ctx context.Context, owner, repo, path string, content []byte, opts *FileOptions) (*FileCommitResult, error) {
+	if opts == nil || opts.Message == "" {
+		return nil, fmt.Errorf("%w: 提交信息不能为空", ErrValidation)
+	}
+	if opts.SHA == "" {
+		return nil, fmt.Errorf("%w: 更新文件需要提供文件的当前SHA", ErrValidation)
+	}
+	if err := api.checkFileSHA(ctx, owner, repo, path, opts.Branch, opts.SHA); err != nil {
+		return nil, err
+	}
+
+	body := opts.body()
+	body["content"] = base64.StdEncoding.EncodeToString(content)
+	return api.writeFile(ctx, http.MethodPut, owner, repo, path, body)
+}
+
+// DeleteFile 删除分支上的文件，opts.SHA必须与文件的当前SHA一致，否则返回ErrConflict
+func (api *ContentsAPI) DeleteFile(ctx context.Context, owner, repo, path string, opts *FileOptions) (*FileCommitResult, error) {
+	if opts == nil || opts.Message == "" {
+		return nil, fmt.Errorf("%w: 提交信息不能为空", ErrValidation)
+	}
+	if opts.SHA == "" {
+		return nil, fmt.Errorf("%w: 删除文件需要提供文件的当前SHA", ErrValidation)
+	}
+	if err := api.checkFileSHA(ctx, owner, repo, path, opts.Branch, opts.SHA); err != nil {
+		return nil, err
+	}
+
+	return api.writeFile(ctx, http.MethodDelete, owner, repo, path, opts.body())
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gitcode-org-com/gitcode-mcp/config"
+)
+
+// newFixtureClient 创建访问测试服务器的客户端，服务器按"方法 路径"返回testdata/contract下的响应样例，
+// 样例为空字符串时返回204
+func newFixtureClient(t *testing.T, routes map[string]string) *GitCodeAPI {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		if fixture == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", "contract", fixture))
+		if err != nil {
+			t.Errorf("读取响应样例失败: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(ts.Close)
+
+	cache := config.NewCacheManager(time.Minute, 0, 0, 0, 0)
+	t.Cleanup(cache.Close)
+	c := &GitCodeAPI{Token: "test-token", BaseURL: ts.URL, HTTPClient: ts.Client(), Cache: cache}
+	c.Branches = NewBranchAPI(c)
+	c.Pulls = NewPullRequestAPI(c)
+	c.Search = NewSearchAPI(c)
+	return c
+}
+
+func TestContractPullRequest(t *testing.T) {
+	c := newFixtureClient(t, map[string]string{"GET /repos/octo/demo/pulls/7": "pull_request.json"})
+
+	pr, err := c.Pulls.GetPullRequest(context.Background(), "octo", "demo", 7)
+	if err != nil {
+		t.Fatalf("GetPullRequest() error = %v", err)
+	}
+	if pr.Number != 7 || pr.User.Username != "alice" || pr.ChangedFiles != 4 || len(pr.RequestedReviewers) != 1 {
+		t.Fatalf("PullRequest = %+v", pr)
+	}
+	if pr.Base.Ref != "main" || pr.Base.Repo.FullName != "octo/demo" || pr.Base.SHA == "" {
+		t.Fatalf("Base = %+v", pr.Base)
+	}
+	if pr.Head.Label != "alice:typed-models" || pr.Head.User.Username != "alice" || !pr.Head.Repo.Fork {
+		t.Fatalf("Head = %+v", pr.Head)
+	}
+}
+
+func TestContractPullRequestReviews(t *testing.T) {
+	c := newFixtureClient(t, map[string]string{
+		"GET /repos/octo/demo/pulls/7/reviews":  "pull_reviews.json",
+		"POST /repos/octo/demo/pulls/7/reviews": "pull_review.json",
+	})
+	ctx := context.Background()
+
+	reviews, err := c.Pulls.ListPRReviews(ctx, "octo", "demo", 7, nil)
+	if err != nil {
+		t.Fatalf("ListPRReviews() error = %v", err)
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("len(reviews) = %d, want 2", len(reviews))
+	}
+	if r := reviews[1]; r.ID != 502 || r.State != "CHANGES_REQUESTED" || r.User.Username != "carol" || r.SubmittedAt == "" {
+		t.Fatalf("reviews[1] = %+v", r)
+	}
+
+	review, err := c.Pulls.CreatePRReview(ctx, "octo", "demo", 7, "Nit inline.", "COMMENT", nil)
+	if err != nil {
+		t.Fatalf("CreatePRReview() error = %v", err)
+	}
+	if review.ID != 503 || review.State != "COMMENTED" || review.CommitID == "" {
+		t.Fatalf("CreatePRReview() = %+v", review)
+	}
+}
+
+func TestContractPullRequestFilesAndCommits(t *testing.T) {
+	c := newFixtureClient(t, map[string]string{
+		"GET /repos/octo/demo/pulls/7/files":   "pull_files.json",
+		"GET /repos/octo/demo/pulls/7/commits": "pull_commits.json",
+	})
+	ctx := context.Background()
+
+	files, err := c.Pulls.ListFiles(ctx, "octo", "demo", 7, nil)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 2 || files[0].Filename != "api/pulls.go" || files[0].Changes != 85 || files[0].Patch == "" {
+		t.Fatalf("ListFiles() = %+v", files)
+	}
+	if files[1].Status != "renamed" || files[1].PreviousFilename != "api/types.go" {
+		t.Fatalf("files[1] = %+v", files[1])
+	}
+
+	commits, err := c.Pulls.ListCommits(ctx, "octo", "demo", 7, nil)
+	if err != nil {
+		t.Fatalf("ListCommits() error = %v", err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("len(commits) = %d, want 1", len(commits))
+	}
+	commit := commits[0]
+	if commit.Commit.Message == "" || commit.Commit.Author.Email != "alice@example.com" || commit.Commit.Tree.SHA == "" {
+		t.Fatalf("Commit.Commit = %+v", commit.Commit)
+	}
+	if commit.Author == nil || commit.Author.Username != "alice" || commit.Committer != nil || len(commit.Parents) != 1 {
+		t.Fatalf("Commit = %+v", commit)
+	}
+}
+
+func TestContractSearchCommits(t *testing.T) {
+	c := newFixtureClient(t, map[string]string{"GET /search/commits": "search_commits.json"})
+
+	commits, err := c.Search.SearchCommits(context.Background(), "typed", nil)
+	if err != nil {
+		t.Fatalf("SearchCommits() error = %v", err)
+	}
+	if len(commits) != 1 || commits[0].SHA == "" || commits[0].HTMLURL == "" || commits[0].Commit.Message == "" {
+		t.Fatalf("SearchCommits() = %+v", commits)
+	}
+}
+
+func TestContractBranchProtection(t *testing.T) {
+	c := newFixtureClient(t, map[string]string{"GET /repos/octo/demo/branches/main/protection": "branch_protection.json"})
+
+	protection, err := c.Branches.GetProtection(context.Background(), "octo", "demo", "main")
+	if err != nil {
+		t.Fatalf("GetProtection() error = %v", err)
+	}
+	if checks := protection.RequiredStatusChecks; checks == nil || !checks.Strict || len(checks.Contexts) != 2 {
+		t.Fatalf("RequiredStatusChecks = %+v", checks)
+	}
+	if protection.EnforceAdmins == nil || !protection.EnforceAdmins.Enabled {
+		t.Fatalf("EnforceAdmins = %+v", protection.EnforceAdmins)
+	}
+	if reviews := protection.RequiredPullRequestReviews; reviews == nil || reviews.RequiredApprovingReviewCount != 2 || !reviews.DismissStaleReviews {
+		t.Fatalf("RequiredPullRequestReviews = %+v", reviews)
+	}
+	if r := protection.Restrictions; r == nil || len(r.Users) != 1 || len(r.Teams) != 1 || r.Teams[0].Slug != "maintainers" {
+		t.Fatalf("Restrictions = %+v", r)
+	}
+	if protection.AllowForcePushes == nil || protection.AllowForcePushes.Enabled || protection.AllowDeletions != nil {
+		t.Fatalf("AllowForcePushes = %+v, AllowDeletions = %+v", protection.AllowForcePushes, protection.AllowDeletions)
+	}
+}
+
+func TestContractMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    MergeResult
+	}{
+		{"合并产生提交", "merge_branch.json", MergeResult{Merged: true, SHA: "b1c2d3e4f5a6978877665544332211ffeeddccbb", Message: "Merge typed-models into main"}},
+		{"无需合并", "", MergeResult{Merged: false, Message: "main 已包含 typed-models 的所有提交，无需合并"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFixtureClient(t, map[string]string{"POST /repos/octo/demo/merges": tt.fixture})
+			result, err := c.Branches.MergeBranch(context.Background(), "octo", "demo", "main", "typed-models", "")
+			if err != nil {
+				t.Fatalf("MergeBranch() error = %v", err)
+			}
+			if *result != tt.want {
+				t.Fatalf("MergeBranch() = %+v, want %+v", *result, tt.want)
+			}
+		})
+	}
+
+	t.Run("合并PR", func(t *testing.T) {
+		c := newFixtureClient(t, map[string]string{"PUT /repos/octo/demo/pulls/7/merge": "merge_pull.json"})
+		result, err := c.Pulls.MergePullRequest(context.Background(), "octo", "demo", 7, MergeOptions{})
+		if err != nil {
+			t.Fatalf("MergePullRequest() error = %v", err)
+		}
+		if !result.Merged || result.SHA != "c2d3e4f5a6b7988776655443322110ffeeddccbb" || result.Message == "" {
+			t.Fatalf("MergePullRequest() = %+v", result)
+		}
+	})
+}
@@ -0,0 +1,441 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DiffLine 表示diff中的一行
+type DiffLine struct {
+	Kind     string `json:"kind"`               // +表示新增，-表示删除，空格表示上下文
+	OldLine  int    `json:"old_line,omitempty"` // 在原文件中的行号，新增行为0
+	NewLine  int    `json:"new_line,omitempty"` // 在新文件中的行号，删除行为0
+	Position int    `json:"position"`           // 在该文件diff中的位置，用于行内评论
+	Text     string `json:"text"`
+}
+
+// DiffHunk 表示diff中以@@开头的一段修改
+type DiffHunk struct {
+	Header   string     `json:"header"`
+	OldStart int        `json:"old_start"`
+	OldLines int        `json:"old_lines"`
+	NewStart int        `json:"new_start"`
+	NewLines int        `json:"new_lines"`
+	Position int        `json:"position"` // @@行本身的位置，第一段为0
+	Lines    []DiffLine `json:"lines"`
+}
+
+// FileDiff 表示一个文件的diff
+type FileDiff struct {
+	Path         string     `json:"path"`
+	PreviousPath string     `json:"previous_path,omitempty"`
+	Status       string     `json:"status"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	Binary       bool       `json:"binary"` // patch表明是二进制文件
+	Hunks        []DiffHunk `json:"hunks"`  // 重命名、修改过大等没有patch的文件为空
+}
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// isBinaryPatch 判断patch是否为git对二进制文件的说明。没有patch不代表是二进制文件，
+// 纯重命名、只修改权限、空文件和修改过大的文件同样没有patch
+func isBinaryPatch(patch string) bool {
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			return false
+		}
+		if strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch" {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePatch 解析文件的patch。position从第一个@@的下一行开始计为1，之后每一行（包括后续的@@行）依次加1
+func ParsePatch(patch string) ([]DiffHunk, error) {
+	var hunks []DiffHunk
+	var oldLine, newLine int
+	position := -1
+
+	for _, text := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		if strings.HasPrefix(text, "@@") {
+			m := hunkHeaderPattern.FindStringSubmatch(text)
+			if m == nil {
+				return nil, fmt.Errorf("无法解析diff段头: %s", text)
+			}
+			position++
+			hunk := DiffHunk{Header: text, Position: position, OldLines: 1, NewLines: 1}
+			hunk.OldStart, _ = strconv.Atoi(m[1])
+			hunk.NewStart, _ = strconv.Atoi(m[3])
+			if m[2] != "" {
+				hunk.OldLines, _ = strconv.Atoi(m[2])
+			}
+			if m[4] != "" {
+				hunk.NewLines, _ = strconv.Atoi(m[4])
+			}
+			hunks = append(hunks, hunk)
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+			continue
+		}
+		if len(hunks) == 0 {
+			// 第一个@@之前的内容（如diff --git头）不计入position
+			continue
+		}
+
+		position++
+		hunk := &hunks[len(hunks)-1]
+		line := DiffLine{Position: position}
+		switch {
+		case strings.HasPrefix(text, "+"):
+			line.Kind, line.Text, line.NewLine = "+", text[1:], newLine
+			newLine++
+		case strings.HasPrefix(text, "-"):
+			line.Kind, line.Text, line.OldLine = "-", text[1:], oldLine
+			oldLine++
+		case strings.HasPrefix(text, `\`):
+			// "\ No newline at end of file"不是文件内容，但仍占一个position
+			line.Kind, line.Text = `\`, text
+		default:
+			line.Kind, line.Text, line.OldLine, line.NewLine = " ", strings.TrimPrefix(text, " "), oldLine, newLine
+			oldLine++
+			newLine++
+		}
+		hunk.Lines = append(hunk.Lines, line)
+	}
+
+	return hunks, nil
+}
+
+// Position 返回文件中某一行在diff中的位置。side为RIGHT时按新文件行号查找新增行和上下文行，
+// 为LEFT时按原文件行号查找删除行和上下文行
+func (f *FileDiff) Position(side string, line int) (int, bool) {
+	for _, hunk := range f.Hunks {
+		for _, l := range hunk.Lines {
+			switch {
+			case side == "LEFT" && l.OldLine == line && (l.Kind == "-" || l.Kind == " "):
+				return l.Position, true
+			case side != "LEFT" && l.NewLine == line && (l.Kind == "+" || l.Kind == " "):
+				return l.Position, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// hasPosition 判断position是否对应diff中可以评论的行
+func (f *FileDiff) hasPosition(position int) bool {
+	for _, hunk := range f.Hunks {
+		for _, l := range hunk.Lines {
+			if l.Position == position {
+				return l.Kind != `\`
+			}
+		}
+	}
+	return false
+}
+
+// lineRanges 描述diff中可评论的新文件行号范围，用于错误提示
+func (f *FileDiff) lineRanges() string {
+	var ranges []string
+	for _, hunk := range f.Hunks {
+		if hunk.NewLines > 0 {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", hunk.NewStart, hunk.NewStart+hunk.NewLines-1))
+		}
+	}
+	if len(ranges) == 0 {
+		return "无"
+	}
+	return strings.Join(ranges, ", ")
+}
+
+// Unified 生成该文件的unified diff文本。lineNumbers为true时在每行前加上原文件和新文件的行号
+func (f *FileDiff) Unified(lineNumbers bool) string {
+	var b strings.Builder
+	b.WriteString(f.fileHeader())
+	for _, hunk := range f.Hunks {
+		b.WriteString(f.hunkText(hunk, lineNumbers))
+	}
+	return b.String()
+}
+
+// fileHeader 生成文件的diff头部。与git相同，重命名的文件输出rename from/to，
+// 二进制文件只输出一行说明，没有diff段的文件不输出---/+++行
+func (f *FileDiff) fileHeader() string {
+	var b strings.Builder
+	oldPath, newPath := "a/"+f.Path, "b/"+f.Path
+	renamed := f.PreviousPath != "" && f.PreviousPath != f.Path
+	if renamed {
+		oldPath = "a/" + f.PreviousPath
+	}
+	fmt.Fprintf(&b, "diff --git %s %s\n", oldPath, newPath)
+	if renamed {
+		fmt.Fprintf(&b, "rename from %s\nrename to %s\n", f.PreviousPath, f.Path)
+	}
+	switch f.Status {
+	case "added":
+		oldPath = "/dev/null"
+	case "removed":
+		newPath = "/dev/null"
+	}
+	switch {
+	case f.Binary:
+		fmt.Fprintf(&b, "Binary files %s and %s differ\n", oldPath, newPath)
+	case len(f.Hunks) > 0:
+		fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldPath, newPath)
+	}
+	return b.String()
+}
+
+// hunkText 生成一段diff的文本
+func (f *FileDiff) hunkText(hunk DiffHunk, lineNumbers bool) string {
+	var b strings.Builder
+	if lineNumbers {
+		fmt.Fprintf(&b, "%11s %s\n", "", hunk.Header)
+	} else {
+		b.WriteString(hunk.Header + "\n")
+	}
+	for _, l := range hunk.Lines {
+		text := l.Kind + l.Text
+		if l.Kind == `\` {
+			text = l.Text
+		}
+		if lineNumbers {
+			fmt.Fprintf(&b, "%5s %5s %s\n", lineNumber(l.OldLine), lineNumber(l.NewLine), text)
+		} else {
+			b.WriteString(text + "\n")
+		}
+	}
+	return b.String()
+}
+
+func lineNumber(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// GetDiff 获取PR中每个文件的diff
+func (api *PullRequestAPI) GetDiff(ctx context.Context, owner, repo string, pullNumber int) ([]FileDiff, error) {
+	files, err := api.ListFiles(ctx, owner, repo, pullNumber, &ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]FileDiff, 0, len(files))
+	for _, file := range files {
+		diff := FileDiff{
+			Path:         file.Filename,
+			PreviousPath: file.PreviousFilename,
+			Status:       file.Status,
+			Additions:    file.Additions,
+			Deletions:    file.Deletions,
+			Binary:       isBinaryPatch(file.Patch),
+		}
+		if file.Patch != "" && !diff.Binary {
+			if diff.Hunks, err = ParsePatch(file.Patch); err != nil {
+				return nil, fmt.Errorf("解析文件 %s 的diff失败: %w", file.Filename, err)
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// ValidateReviewComments 检查行内评论是否都落在diff中可以评论的行上，
+// 并把按行号（Line和Side）指定的评论换算为position。所有问题会一次性列出
+func ValidateReviewComments(diffs []FileDiff, comments []DraftReviewComment) ([]DraftReviewComment, error) {
+	byPath := make(map[string]*FileDiff, len(diffs))
+	for i := range diffs {
+		byPath[diffs[i].Path] = &diffs[i]
+	}
+
+	resolved := make([]DraftReviewComment, 0, len(comments))
+	var problems []string
+	for i, comment := range comments {
+		prefix := fmt.Sprintf("第%d条评论(%s)", i+1, comment.Path)
+		if strings.TrimSpace(comment.Body) == "" {
+			problems = append(problems, prefix+"内容为空")
+			continue
+		}
+		diff, ok := byPath[comment.Path]
+		if !ok {
+			problems = append(problems, prefix+"的文件不在该PR的修改中")
+			continue
+		}
+		if len(diff.Hunks) == 0 {
+			problems = append(problems, prefix+"的文件没有可评论的diff")
+			continue
+		}
+
+		switch {
+		case comment.Line > 0:
+			side := strings.ToUpper(comment.Side)
+			if side == "" {
+				side = "RIGHT"
+			}
+			if side != "LEFT" && side != "RIGHT" {
+				problems = append(problems, prefix+"的side只能是LEFT或RIGHT")
+				continue
+			}
+			position, ok := diff.Position(side, comment.Line)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s的第%d行(%s)不在diff中，新文件中可评论的行范围为%s", prefix, comment.Line, side, diff.lineRanges()))
+				continue
+			}
+			comment.Position = position
+		case comment.Position > 0:
+			if !diff.hasPosition(comment.Position) {
+				problems = append(problems, fmt.Sprintf("%s的position %d不是diff中可评论的行", prefix, comment.Position))
+				continue
+			}
+		default:
+			problems = append(problems, prefix+"需要提供line或position")
+			continue
+		}
+
+		// 统一以position提交
+		comment.Line, comment.Side = 0, ""
+		resolved = append(resolved, comment)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, strings.Join(problems, "; "))
+	}
+	return resolved, nil
+}
+
+// DiffFilter 表示选择diff文件的条件，零值表示选择全部文件
+type DiffFilter struct {
+	Files   []string // 只选择这些路径的文件
+	Include []string // 只选择匹配任意一个glob模式的文件
+	Exclude []string // 排除匹配任意一个glob模式的文件
+}
+
+// Match 判断文件是否满足条件，重命名的文件按新旧路径中任意一个判断
+func (f *DiffFilter) Match(diff *FileDiff) bool {
+	if f == nil {
+		return true
+	}
+	paths := []string{diff.Path}
+	if diff.PreviousPath != "" {
+		paths = append(paths, diff.PreviousPath)
+	}
+	matchAny := func(match func(string) bool) bool {
+		for _, p := range paths {
+			if match(p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(f.Files) > 0 && !matchAny(func(p string) bool {
+		for _, file := range f.Files {
+			if file == p {
+				return true
+			}
+		}
+		return false
+	}) {
+		return false
+	}
+	if len(f.Include) > 0 && !matchAny(func(p string) bool { return MatchAnyGlob(f.Include, p) }) {
+		return false
+	}
+	if len(f.Exclude) > 0 && matchAny(func(p string) bool { return MatchAnyGlob(f.Exclude, p) }) {
+		return false
+	}
+	return true
+}
+
+// DiffChunk 表示按token预算切分后的一页diff
+type DiffChunk struct {
+	Text      string   `json:"text"`
+	Files     []string `json:"files"`     // 该页包含的文件
+	Tokens    int      `json:"tokens"`    // 估算的token数
+	Oversized bool     `json:"oversized"` // 单个diff段超过预算，只能单独成页
+}
+
+// EstimateTokens 粗略估算文本的token数：ASCII字符按4个一个token，其他字符按每个一个token
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < 128 {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// ChunkDiff 将diff按token预算切分为多页，只在diff段（@@）之间切分。
+// 一个文件跨页时，后续页会重复该文件的头部；单个diff段超过预算时单独成页。maxTokens不大于0时不切分
+func ChunkDiff(diffs []FileDiff, lineNumbers bool, maxTokens int) []DiffChunk {
+	var chunks []DiffChunk
+	var current DiffChunk
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		current.Text = text.String()
+		chunks = append(chunks, current)
+		current = DiffChunk{}
+		text.Reset()
+	}
+	fits := func(tokens int) bool {
+		return maxTokens <= 0 || text.Len() == 0 || current.Tokens+tokens <= maxTokens
+	}
+	write := func(s string, tokens int) {
+		text.WriteString(s)
+		current.Tokens += tokens
+		if maxTokens > 0 && current.Tokens > maxTokens {
+			current.Oversized = true
+		}
+	}
+
+	for i := range diffs {
+		diff := &diffs[i]
+		header := diff.fileHeader()
+		headerTokens := EstimateTokens(header)
+		if diff.Binary || len(diff.Hunks) == 0 {
+			if !fits(headerTokens) {
+				flush()
+			}
+			write(header, headerTokens)
+			current.Files = append(current.Files, diff.Path)
+			continue
+		}
+
+		headerWritten := false
+		for _, hunk := range diff.Hunks {
+			hunkText := diff.hunkText(hunk, lineNumbers)
+			hunkTokens := EstimateTokens(hunkText)
+			needed := hunkTokens
+			if !headerWritten {
+				needed += headerTokens
+			}
+			if !fits(needed) {
+				flush()
+				headerWritten = false
+			}
+			if !headerWritten {
+				write(header, headerTokens)
+				current.Files = append(current.Files, diff.Path)
+				headerWritten = true
+			}
+			write(hunkText, hunkTokens)
+		}
+	}
+	flush()
+
+	return chunks
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetDiffUnified(t *testing.T) {
+	s := newScriptedServer(t, scriptedResponse{status: 200, body: `[
+		{"filename": "main.go", "status": "modified", "additions": 1, "deletions": 1, "patch": "@@ -1,2 +1,2 @@\n package main\n-var a = 1\n+var a = 2"},
+		{"filename": "new.go", "previous_filename": "old.go", "status": "renamed"},
+		{"filename": "pkg/b.go", "previous_filename": "pkg/a.go", "status": "renamed", "additions": 1, "deletions": 0, "patch": "@@ -1 +1,2 @@\n package pkg\n+// b"},
+		{"filename": "logo.png", "status": "added", "patch": "Binary files /dev/null and b/logo.png differ"},
+		{"filename": "huge.json", "status": "modified", "additions": 50000, "deletions": 10}
+	]`})
+	c := newTestClient(t, s, RetryPolicy{})
+	c.Pulls = NewPullRequestAPI(c)
+
+	diffs, err := c.Pulls.GetDiff(context.Background(), "o", "r", 1)
+	if err != nil {
+		t.Fatalf("GetDiff() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		binary bool
+		want   string
+	}{
+		{"修改", false, "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n package main\n-var a = 1\n+var a = 2\n"},
+		{"纯重命名", false, "diff --git a/old.go b/new.go\nrename from old.go\nrename to new.go\n"},
+		{"重命名并修改", false, "diff --git a/pkg/a.go b/pkg/b.go\nrename from pkg/a.go\nrename to pkg/b.go\n--- a/pkg/a.go\n+++ b/pkg/b.go\n@@ -1 +1,2 @@\n package pkg\n+// b\n"},
+		{"二进制文件", true, "diff --git a/logo.png b/logo.png\nBinary files /dev/null and b/logo.png differ\n"},
+		{"没有返回patch的文本文件", false, "diff --git a/huge.json b/huge.json\n"},
+	}
+	if len(diffs) != len(tests) {
+		t.Fatalf("len(diffs) = %d, want %d", len(diffs), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diffs[i].Binary != tt.binary {
+				t.Fatalf("Binary = %v, want %v", diffs[i].Binary, tt.binary)
+			}
+			if got := diffs[i].Unified(false); got != tt.want {
+				t.Fatalf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	// 没有diff段的文件不能添加行内评论
+	_, err = ValidateReviewComments(diffs, []DraftReviewComment{{Path: "new.go", Position: 1, Body: "x"}})
+	if err == nil {
+		t.Fatal("纯重命名的文件不应允许行内评论")
+	}
+}
+
+func TestParsePatchPositions(t *testing.T) {
+	hunks, err := ParsePatch("@@ -1,2 +1,2 @@\n a\n-b\n+c\n@@ -10 +10 @@\n-x\n+y\n\\ No newline at end of file")
+	if err != nil {
+		t.Fatalf("ParsePatch() error = %v", err)
+	}
+	if len(hunks) != 2 || hunks[1].Position != 4 {
+		t.Fatalf("ParsePatch() = %+v", hunks)
+	}
+
+	diff := FileDiff{Hunks: hunks}
+	tests := []struct {
+		side string
+		line int
+		want int
+	}{
+		{"RIGHT", 2, 3},
+		{"LEFT", 2, 2},
+		{"RIGHT", 1, 1},
+		{"RIGHT", 10, 6},
+		{"LEFT", 10, 5},
+	}
+	for _, tt := range tests {
+		if got, ok := diff.Position(tt.side, tt.line); !ok || got != tt.want {
+			t.Fatalf("Position(%s, %d) = %d, %v, want %d", tt.side, tt.line, got, ok, tt.want)
+		}
+	}
+	if diff.hasPosition(7) {
+		t.Fatal(`"\ No newline at end of file"不是可评论的行`)
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListIssuesOptions 表示列出Issue时的过滤和排序条件，零值表示不过滤
+// 条件会作为查询参数发送给服务端，返回结果再在客户端按相同条件过滤一次，服务端不支持某个参数时结果仍然正确
+type ListIssuesOptions struct {
+	State     string    // open、closed或all，为空时使用服务端默认值（open）
+	Labels    []string  // 必须同时带有的标签
+	Assignee  string    // 负责人用户名，"none"表示没有负责人
+	Creator   string    // 创建者用户名
+	Milestone string    // 里程碑编号，"none"表示没有里程碑，"*"表示有任意里程碑
+	Since     time.Time // 只返回该时间之后更新过的条目
+	Sort      string    // created、updated或comments
+	Direction string    // asc或desc，默认desc
+}
+
+// ListPullRequestsOptions 表示列出Pull Request时的过滤和排序条件，与Issue相同
+type ListPullRequestsOptions = ListIssuesOptions
+
+// listItem 是Issue和Pull Request中参与过滤和排序的字段
+type listItem struct {
+	State     string
+	Labels    []Label
+	Assignees []User
+	Creator   User
+	Milestone *Milestone
+	CreatedAt string
+	UpdatedAt string
+	Comments  int
+}
+
+func issueListItem(issue Issue) listItem {
+	return listItem{issue.State, issue.Labels, issue.Assignees, issue.User, issue.Milestone, issue.CreatedAt, issue.UpdatedAt, issue.Comments}
+}
+
+func pullRequestListItem(pr PullRequest) listItem {
+	return listItem{pr.State, pr.Labels, pr.Assignees, pr.User, pr.Milestone, pr.CreatedAt, pr.UpdatedAt, pr.Comments}
+}
+
+// validate 检查过滤条件的取值
+func (opts *ListIssuesOptions) validate() error {
+	if opts == nil {
+		return nil
+	}
+	switch opts.State {
+	case "", "open", "closed", "all":
+	default:
+		return fmt.Errorf("%w: state只能是open、closed或all", ErrValidation)
+	}
+	switch opts.Sort {
+	case "", "created", "updated", "comments":
+	default:
+		return fmt.Errorf("%w: sort只能是created、updated或comments", ErrValidation)
+	}
+	switch opts.Direction {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("%w: direction只能是asc或desc", ErrValidation)
+	}
+	if opts.Milestone != "" && opts.Milestone != "none" && opts.Milestone != "*" {
+		if _, err := strconv.Atoi(opts.Milestone); err != nil {
+			return fmt.Errorf("%w: milestone必须是里程碑编号、none或*", ErrValidation)
+		}
+	}
+	return nil
+}
+
+// values 生成查询参数
+func (opts *ListIssuesOptions) values() url.Values {
+	values := url.Values{}
+	if opts == nil {
+		return values
+	}
+	if opts.State != "" {
+		values.Set("state", opts.State)
+	}
+	if len(opts.Labels) > 0 {
+		values.Set("labels", strings.Join(opts.Labels, ","))
+	}
+	if opts.Assignee != "" {
+		values.Set("assignee", opts.Assignee)
+	}
+	if opts.Creator != "" {
+		values.Set("creator", opts.Creator)
+	}
+	if opts.Milestone != "" {
+		values.Set("milestone", opts.Milestone)
+	}
+	if !opts.Since.IsZero() {
+		values.Set("since", opts.Since.UTC().Format(time.RFC3339))
+	}
+	if opts.Sort != "" {
+		values.Set("sort", opts.Sort)
+	}
+	if opts.Direction != "" {
+		values.Set("direction", opts.Direction)
+	}
+	return values
+}
+
+// match 判断条目是否满足过滤条件
+func (opts *ListIssuesOptions) match(item listItem) bool {
+	if opts == nil {
+		return true
+	}
+	if opts.State != "" && opts.State != "all" && !strings.EqualFold(item.State, opts.State) {
+		return false
+	}
+	for _, label := range opts.Labels {
+		if !hasLabel(item.Labels, label) {
+			return false
+		}
+	}
+	if opts.Assignee != "" && !matchAssignee(item.Assignees, opts.Assignee) {
+		return false
+	}
+	if opts.Creator != "" && !strings.EqualFold(item.Creator.Username, opts.Creator) {
+		return false
+	}
+	if opts.Milestone != "" && !matchMilestone(item.Milestone, opts.Milestone) {
+		return false
+	}
+	if !opts.Since.IsZero() {
+		// 无法解析更新时间时交给服务端的结果决定
+		if updated, err := time.Parse(time.RFC3339, item.UpdatedAt); err == nil && updated.Before(opts.Since) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAssignee(assignees []User, assignee string) bool {
+	if assignee == "none" {
+		return len(assignees) == 0
+	}
+	for _, user := range assignees {
+		if strings.EqualFold(user.Username, assignee) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchMilestone(milestone *Milestone, want string) bool {
+	switch want {
+	case "none":
+		return milestone == nil
+	case "*":
+		return milestone != nil
+	default:
+		return milestone != nil && strconv.Itoa(milestone.Number) == want
+	}
+}
+
+// sortItems 在客户端按Sort和Direction重新排序；服务端已经排好序时结果不变
+func sortItems[T any](items []T, opts *ListIssuesOptions, toItem func(T) listItem) {
+	if opts == nil || opts.Sort == "" {
+		return
+	}
+	key := func(item listItem) string {
+		switch opts.Sort {
+		case "updated":
+			return item.UpdatedAt
+		default:
+			return item.CreatedAt
+		}
+	}
+	less := func(a, b listItem) bool {
+		if opts.Sort == "comments" {
+			return a.Comments < b.Comments
+		}
+		return parseListTime(key(a)).Before(parseListTime(key(b)))
+	}
+	asc := opts.Direction == "asc"
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := toItem(items[i]), toItem(items[j])
+		if asc {
+			return less(a, b)
+		}
+		return less(b, a)
+	})
+}
+
+// parseListTime 解析列表条目中的时间，无法解析时返回零值
+func parseListTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
@@ -0,0 +1,469 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 文件操作类型
+const (
+	FileActionCreate = "create"
+	FileActionUpdate = "update"
+	FileActionDelete = "delete"
+	FileActionMove   = "move"
+)
+
+// 多文件提交的实现方式
+const (
+	CommitMethodGitData    = "git_data"   // 通过blobs/trees/commits/refs接口生成单个提交
+	CommitMethodSequential = "sequential" // 平台不支持Git数据接口时逐个文件提交
+)
+
+// rollbackTimeout 逐个文件提交失败后撤销已完成操作的最长时间
+const rollbackTimeout = 2 * time.Minute
+
+// FileOperation 表示多文件提交中的一个文件操作
+type FileOperation struct {
+	Action       string // create、update、delete或move
+	Path         string // 文件路径，move时为目标路径
+	PreviousPath string // move时的源路径
+	Content      []byte // create和update时的文件内容；move时为空表示保持原内容
+	SHA          string // 可选，文件的当前blob SHA，用于检测冲突；move时对应源文件
+}
+
+// CommitFilesResult 表示多文件提交的结果
+type CommitFilesResult struct {
+	Method  string   `json:"method"`  // git_data或sequential
+	SHA     string   `json:"sha"`     // 分支最新的提交SHA
+	Commits []string `json:"commits"` // 产生的所有提交，git_data方式只有一个
+}
+
+// GitObject 表示Git数据接口返回的对象引用
+type GitObject struct {
+	SHA  string `json:"sha"`
+	URL  string `json:"url"`
+	Type string `json:"type,omitempty"`
+}
+
+// GitRef 表示Git引用
+type GitRef struct {
+	Ref    string    `json:"ref"`
+	URL    string    `json:"url"`
+	Object GitObject `json:"object"`
+}
+
+// treeInput 表示创建树时的一项，SHA为nil表示删除该路径
+type treeInput struct {
+	Path string  `json:"path"`
+	Mode string  `json:"mode"`
+	Type string  `json:"type"`
+	SHA  *string `json:"sha"`
+}
+
+// CreateBlob 创建blob对象
+func (api *ContentsAPI) CreateBlob(ctx context.Context, owner, repo string, content []byte) (*GitObject, error) {
+	path := fmt.Sprintf("/repos/%s/%s/git/blobs", owner, repo)
+	body := map[string]interface{}{
+		"content":  base64.StdEncoding.EncodeToString(content),
+		"encoding": "base64",
+	}
+	return api.createGitObject(ctx, path, body, "解析blob失败")
+}
+
+// createTree 基于baseTree创建新的树对象
+func (api *ContentsAPI) createTree(ctx context.Context, owner, repo, baseTree string, entries []treeInput) (*GitObject, error) {
+	path := fmt.Sprintf("/repos/%s/%s/git/trees", owner, repo)
+	body := map[string]interface{}{
+		"base_tree": baseTree,
+		"tree":      entries,
+	}
+	return api.createGitObject(ctx, path, body, "解析树对象失败")
+}
+
+// CreateCommit 创建提交对象，不会移动任何分支
+func (api *ContentsAPI) CreateCommit(ctx context.Context, owner, repo, message, tree string, parents []string) (*GitObject, error) {
+	path := fmt.Sprintf("/repos/%s/%s/git/commits", owner, repo)
+	body := map[string]interface{}{
+		"message": message,
+		"tree":    tree,
+		"parents": parents,
+	}
+	return api.createGitObject(ctx, path, body, "解析提交对象失败")
+}
+
+// createGitObject 发送创建Git对象的请求
+func (api *ContentsAPI) createGitObject(ctx context.Context, path string, body interface{}, errMsg string) (*GitObject, error) {
+	resp, err := api.Client.POST(ctx, path, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var object GitObject
+	if err := json.Unmarshal(resp, &object); err != nil {
+		return nil, fmt.Errorf("%s: %w", errMsg, err)
+	}
+	return &object, nil
+}
+
+// UpdateRef 将分支移动到指定提交，force为false时只允许快进
+func (api *ContentsAPI) UpdateRef(ctx context.Context, owner, repo, branch, sha string, force bool) (*GitRef, error) {
+	path := fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", owner, repo, escapePath(branch))
+	body := map[string]interface{}{
+		"sha":   sha,
+		"force": force,
+	}
+
+	resp, err := api.Client.PATCH(ctx, path, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var ref GitRef
+	if err := json.Unmarshal(resp, &ref); err != nil {
+		return nil, fmt.Errorf("解析引用失败: %w", err)
+	}
+	return &ref, nil
+}
+
+// gitDataUnsupported 判断错误是否表示平台不提供Git数据接口
+func gitDataUnsupported(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Code {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// validateFileOperations 检查文件操作列表是否合法
+func validateFileOperations(ops []FileOperation) error {
+	if len(ops) == 0 {
+		return fmt.Errorf("%w: 文件操作列表不能为空", ErrValidation)
+	}
+
+	seen := make(map[string]bool)
+	claim := func(path string) error {
+		if seen[path] {
+			return fmt.Errorf("%w: 文件 %s 在多个操作中出现", ErrValidation, path)
+		}
+		seen[path] = true
+		return nil
+	}
+
+	for i := range ops {
+		op := &ops[i]
+		op.Path = strings.Trim(op.Path, "/")
+		op.PreviousPath = strings.Trim(op.PreviousPath, "/")
+		if op.Path == "" {
+			return fmt.Errorf("%w: 第%d个操作缺少文件路径", ErrValidation, i+1)
+		}
+
+		switch op.Action {
+		case FileActionCreate, FileActionUpdate, FileActionDelete:
+		case FileActionMove:
+			if op.PreviousPath == "" {
+				return fmt.Errorf("%w: 移动文件 %s 需要提供源路径", ErrValidation, op.Path)
+			}
+			if op.PreviousPath == op.Path {
+				return fmt.Errorf("%w: 移动文件 %s 的源路径与目标路径相同", ErrValidation, op.Path)
+			}
+			if err := claim(op.PreviousPath); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: 不支持的文件操作 %q", ErrValidation, op.Action)
+		}
+		if err := claim(op.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CommitFiles 将多个文件操作作为一个提交落到分支上
+// 优先使用Git数据接口生成单个提交；平台不支持时回退为逐个文件提交，
+// 中途失败会尽力撤销已完成的操作
+func (api *ContentsAPI) CommitFiles(ctx context.Context, owner, repo, branch, message string, ops []FileOperation) (*CommitFilesResult, error) {
+	if branch == "" {
+		return nil, fmt.Errorf("%w: 目标分支不能为空", ErrValidation)
+	}
+	if message == "" {
+		return nil, fmt.Errorf("%w: 提交信息不能为空", ErrValidation)
+	}
+	if err := validateFileOperations(ops); err != nil {
+		return nil, err
+	}
+
+	// 写操作之前读取最新状态，避免基于缓存的旧数据提交
+	ctx = WithNoCache(ctx)
+	head, err := api.Client.Branches.GetBranch(ctx, owner, repo, branch)
+	if err != nil {
+		return nil, fmt.Errorf("获取分支 %s 失败: %w", branch, err)
+	}
+
+	result, err := api.commitGitData(ctx, owner, repo, branch, message, head.Commit.ID, ops)
+	if err != nil && gitDataUnsupported(err) {
+		return api.commitSequential(ctx, owner, repo, branch, message, ops)
+	}
+	return result, err
+}
+
+// commitGitData 通过Git数据接口生成单个提交并快进分支
+func (api *ContentsAPI) commitGitData(ctx context.Context, owner, repo, branch, message, headSHA string, ops []FileOperation) (*CommitFilesResult, error) {
+	tree, err := api.GetTree(ctx, owner, repo, headSHA, true)
+	if err != nil {
+		return nil, err
+	}
+	if tree.Truncated {
+		return nil, fmt.Errorf("仓库文件树过大，无法校验文件操作")
+	}
+
+	existing := make(map[string]TreeEntry, len(tree.Tree))
+	for _, entry := range tree.Tree {
+		if entry.Type == "blob" {
+			existing[entry.Path] = entry
+		}
+	}
+
+	// lookup 检查文件是否存在且SHA符合预期
+	lookup := func(path, sha string) (TreeEntry, error) {
+		entry, found := existing[path]
+		if !found {
+			return entry, fmt.Errorf("%w: 文件 %s 不存在", ErrNotFound, path)
+		}
+		if sha != "" && entry.SHA != sha {
+			return entry, fmt.Errorf("%w: 文件 %s 已被修改，当前SHA为 %s，与提供的 %s 不一致", ErrConflict, path, entry.SHA, sha)
+		}
+		return entry, nil
+	}
+
+	var entries []treeInput
+	for _, op := range ops {
+		mode := "100644"
+		switch op.Action {
+		case FileActionCreate:
+			if _, found := existing[op.Path]; found {
+				return nil, fmt.Errorf("%w: 文件 %s 已存在", ErrConflict, op.Path)
+			}
+		case FileActionUpdate:
+			entry, err := lookup(op.Path, op.SHA)
+			if err != nil {
+				return nil, err
+			}
+			mode = entry.Mode
+		case FileActionDelete:
+			entry, err := lookup(op.Path, op.SHA)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, treeInput{Path: op.Path, Mode: entry.Mode, Type: "blob"})
+			continue
+		case FileActionMove:
+			source, err := lookup(op.PreviousPath, op.SHA)
+			if err != nil {
+				return nil, err
+			}
+			if _, found := existing[op.Path]; found {
+				return nil, fmt.Errorf("%w: 目标文件 %s 已存在", ErrConflict, op.Path)
+			}
+			entries = append(entries, treeInput{Path: op.PreviousPath, Mode: source.Mode, Type: "blob"})
+			mode = source.Mode
+			if op.Content == nil {
+				sha := source.SHA
+				entries = append(entries, treeInput{Path: op.Path, Mode: mode, Type: "blob", SHA: &sha})
+				continue
+			}
+		}
+
+		blob, err := api.CreateBlob(ctx, owner, repo, op.Content)
+		if err != nil {
+			return nil, fmt.Errorf("创建文件 %s 的blob失败: %w", op.Path, err)
+		}
+		sha := blob.SHA
+		entries = append(entries, treeInput{Path: op.Path, Mode: mode, Type: "blob", SHA: &sha})
+	}
+
+	newTree, err := api.createTree(ctx, owner, repo, tree.SHA, entries)
+	if err != nil {
+		return nil, fmt.Errorf("创建树对象失败: %w", err)
+	}
+
+	commit, err := api.CreateCommit(ctx, owner, repo, message, newTree.SHA, []string{headSHA})
+	if err != nil {
+		return nil, fmt.Errorf("创建提交失败: %w", err)
+	}
+
+	// 只允许快进，分支在此期间被其他人更新时失败而不是覆盖
+	if _, err := api.UpdateRef(ctx, owner, repo, branch, commit.SHA, false); err != nil {
+		if gitDataUnsupported(err) {
+			return nil, fmt.Errorf("更新分支 %s 失败: %w", branch, err)
+		}
+		return nil, fmt.Errorf("%w: 更新分支 %s 失败，分支可能已被其他提交更新: %v", ErrConflict, branch, err)
+	}
+
+	return &CommitFilesResult{
+		Method:  CommitMethodGitData,
+		SHA:     commit.SHA,
+		Commits: []string{commit.SHA},
+	}, nil
+}
+
+// undoStep 记录逐个提交时如何撤销一个已完成的文件写操作
+type undoStep struct {
+	path    string
+	sha     string // 写操作后文件的SHA，为空表示文件已被删除
+	content []byte // 写操作前的内容，为nil表示文件原本不存在
+}
+
+// commitSequential 逐个文件提交，失败时按相反顺序撤销已完成的操作
+func (api *ContentsAPI) commitSequential(ctx context.Context, owner, repo, branch, message string, ops []FileOperation) (*CommitFilesResult, error) {
+	result := &CommitFilesResult{Method: CommitMethodSequential, Commits: []string{}}
+	var undo []undoStep
+
+	// 记录每次写操作产生的提交和撤销方式
+	record := func(res *FileCommitResult, step undoStep) {
+		if res.Content != nil {
+			step.sha = res.Content.SHA
+		}
+		undo = append(undo, step)
+		if res.Commit.SHA != "" {
+			result.Commits = append(result.Commits, res.Commit.SHA)
+			result.SHA = res.Commit.SHA
+		}
+	}
+
+	// current 读取文件的当前内容和SHA
+	current := func(path, sha string) (*FileContent, []byte, error) {
+		file, err := api.GetFile(ctx, owner, repo, path, branch)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sha != "" && file.SHA != sha {
+			return nil, nil, fmt.Errorf("%w: 文件 %s 已被修改，当前SHA为 %s，与提供的 %s 不一致", ErrConflict, path, file.SHA, sha)
+		}
+		data, err := file.Decode()
+		if err != nil {
+			return nil, nil, err
+		}
+		return file, data, nil
+	}
+
+	apply := func(op FileOperation) error {
+		opts := &FileOptions{Message: message, Branch: branch}
+		switch op.Action {
+		case FileActionCreate:
+			res, err := api.CreateFile(ctx, owner, repo, op.Path, op.Content, opts)
+			if err != nil {
+				return err
+			}
+			record(res, undoStep{path: op.Path})
+		case FileActionUpdate:
+			file, data, err := current(op.Path, op.SHA)
+			if err != nil {
+				return err
+			}
+			opts.SHA = file.SHA
+			res, err := api.UpdateFile(ctx, owner, repo, op.Path, op.Content, opts)
+			if err != nil {
+				return err
+			}
+			record(res, undoStep{path: op.Path, content: data})
+		case FileActionDelete:
+			file, data, err := current(op.Path, op.SHA)
+			if err != nil {
+				return err
+			}
+			opts.SHA = file.SHA
+			res, err := api.DeleteFile(ctx, owner, repo, op.Path, opts)
+			if err != nil {
+				return err
+			}
+			record(res, undoStep{path: op.Path, content: data})
+		case FileActionMove:
+			file, data, err := current(op.PreviousPath, op.SHA)
+			if err != nil {
+				return err
+			}
+			content := op.Content
+			if content == nil {
+				content = data
+			}
+			res, err := api.CreateFile(ctx, owner, repo, op.Path, content, opts)
+			if err != nil {
+				return err
+			}
+			record(res, undoStep{path: op.Path})
+
+			opts = &FileOptions{Message: message, Branch: branch, SHA: file.SHA}
+			res, err = api.DeleteFile(ctx, owner, repo, op.PreviousPath, opts)
+			if err != nil {
+				return err
+			}
+			record(res, undoStep{path: op.PreviousPath, content: data})
+		}
+		return nil
+	}
+
+	for _, op := range ops {
+		if err := apply(op); err != nil {
+			err = fmt.Errorf("处理文件 %s 失败: %w", op.Path, err)
+			// 失败可能是因为调用方取消或超时，撤销操作使用独立的上下文，避免分支停留在只完成一半的状态
+			rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+			rollbackErr := api.rollback(rollbackCtx, owner, repo, branch, message, undo)
+			cancel()
+			if rollbackErr != nil {
+				return result, fmt.Errorf("%w；撤销已完成的操作失败: %v", err, rollbackErr)
+			}
+			return result, fmt.Errorf("%w；已撤销此前完成的%d个操作", err, len(undo))
+		}
+	}
+
+	return result, nil
+}
+
+// rollback 按相反顺序撤销已完成的文件写操作
+func (api *ContentsAPI) rollback(ctx context.Context, owner, repo, branch, message string, undo []undoStep) error {
+	var errs []error
+	revert := fmt.Sprintf("Revert: %s", message)
+
+	for i := len(undo) - 1; i >= 0; i-- {
+		step := undo[i]
+		opts := &FileOptions{Message: revert, Branch: branch, SHA: step.sha}
+
+		var err error
+		switch {
+		case step.content == nil:
+			// 文件原本不存在，删除新建的文件；写操作结果中没有SHA时重新获取
+			if opts.SHA == "" {
+				var file *FileContent
+				if file, err = api.GetFile(ctx, owner, repo, step.path, branch); err == nil {
+					opts.SHA = file.SHA
+				}
+			}
+			if err == nil {
+				_, err = api.DeleteFile(ctx, owner, repo, step.path, opts)
+			}
+		case step.sha == "":
+			// 文件被删除，重新创建
+			opts.SHA = ""
+			_, err = api.CreateFile(ctx, owner, repo, step.path, step.content, opts)
+		default:
+			// 文件被修改，恢复原内容
+			_, err = api.UpdateFile(ctx, owner, repo, step.path, step.content, opts)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", step.path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
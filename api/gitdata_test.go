@@ -0,0 +1,319 @@
+package api
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gitcode-org-com/gitcode-mcp/config"
+)
+
+// fakeRepo 模拟仓库的contents接口和Git数据接口，文件内容保存在内存中
+type fakeRepo struct {
+	mu        sync.Mutex
+	files     map[string]string // 路径 -> 内容
+	gitData   int               // 不为0时Git数据接口返回该状态码
+	refStatus int               // 不为0时更新分支返回该状态码
+	failPath  string            // 写该文件时返回500
+	writes    []string          // 按顺序记录的写请求，格式为"方法 路径"
+	bodies    map[string]string // 写请求路径 -> 最近一次的请求体
+	commits   int
+}
+
+func blobSHA(content string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(content)))
+}
+
+// newFakeRepoClient 启动模拟仓库并创建访问它的客户端
+func newFakeRepoClient(t *testing.T, repo *fakeRepo) *GitCodeAPI {
+	t.Helper()
+	repo.bodies = map[string]string{}
+	ts := httptest.NewServer(repo)
+	t.Cleanup(ts.Close)
+
+	cache := config.NewCacheManager(time.Minute, 0, 0, 0, 0)
+	t.Cleanup(cache.Close)
+	c := &GitCodeAPI{Token: "test-token", BaseURL: ts.URL, HTTPClient: ts.Client(), Cache: cache}
+	c.Branches = NewBranchAPI(c)
+	c.Contents = NewContentsAPI(c)
+	return c
+}
+
+func (repo *fakeRepo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, "/repos/o/r/")
+	if r.Method != http.MethodGet {
+		repo.writes = append(repo.writes, r.Method+" "+path)
+		repo.bodies[path] = string(body)
+	}
+	reply := func(status int, v interface{}) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case path == "branches/main":
+		reply(http.StatusOK, map[string]interface{}{"name": "main", "commit": map[string]string{"id": "head0"}})
+	case strings.HasPrefix(path, "git/"):
+		if repo.gitData != 0 {
+			reply(repo.gitData, map[string]string{"message": "unsupported"})
+			return
+		}
+		switch path {
+		case "git/trees/head0":
+			var entries []TreeEntry
+			for _, name := range slices.Sorted(maps.Keys(repo.files)) {
+				entries = append(entries, TreeEntry{Path: name, Mode: "100644", Type: "blob", SHA: blobSHA(repo.files[name])})
+			}
+			reply(http.StatusOK, Tree{SHA: "tree0", Tree: entries})
+		case "git/blobs":
+			var blob struct{ Content string }
+			json.Unmarshal(body, &blob)
+			data, _ := base64.StdEncoding.DecodeString(blob.Content)
+			reply(http.StatusCreated, GitObject{SHA: blobSHA(string(data))})
+		case "git/trees":
+			reply(http.StatusCreated, GitObject{SHA: "tree1"})
+		case "git/commits":
+			reply(http.StatusCreated, GitObject{SHA: "commit1"})
+		case "git/refs/heads/main":
+			if repo.refStatus != 0 {
+				reply(repo.refStatus, map[string]string{"message": "Update is not a fast forward"})
+				return
+			}
+			reply(http.StatusOK, GitRef{Ref: "refs/heads/main", Object: GitObject{SHA: "commit1"}})
+		default:
+			reply(http.StatusNotFound, map[string]string{"message": "not found"})
+		}
+	case strings.HasPrefix(path, "contents/"):
+		repo.serveContents(w, r.Method, strings.TrimPrefix(path, "contents/"), body, reply)
+	default:
+		reply(http.StatusNotFound, map[string]string{"message": "not found"})
+	}
+}
+
+// serveContents 处理单个文件的读写
+func (repo *fakeRepo) serveContents(w http.ResponseWriter, method, name string, body []byte, reply func(int, interface{})) {
+	content, exists := repo.files[name]
+	if method == http.MethodGet {
+		if !exists {
+			reply(http.StatusNotFound, map[string]string{"message": "not found"})
+			return
+		}
+		reply(http.StatusOK, FileContent{Type: "file", Encoding: "base64", Path: name, SHA: blobSHA(content), Content: base64.StdEncoding.EncodeToString([]byte(content))})
+		return
+	}
+	if name == repo.failPath {
+		reply(http.StatusInternalServerError, map[string]string{"message": "internal error"})
+		return
+	}
+
+	var req struct{ Content, SHA string }
+	json.Unmarshal(body, &req)
+	if method != http.MethodPost && (!exists || req.SHA != blobSHA(content)) {
+		reply(http.StatusConflict, map[string]string{"message": "sha mismatch"})
+		return
+	}
+	repo.commits++
+	result := FileCommitResult{Commit: FileCommit{SHA: fmt.Sprintf("c%d", repo.commits)}}
+	if method == http.MethodDelete {
+		delete(repo.files, name)
+	} else {
+		data, _ := base64.StdEncoding.DecodeString(req.Content)
+		repo.files[name] = string(data)
+		result.Content = &FileContent{Path: name, SHA: blobSHA(string(data))}
+	}
+	reply(http.StatusOK, result)
+}
+
+// snapshot 返回当前的文件内容
+func (repo *fakeRepo) snapshot() map[string]string {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return maps.Clone(repo.files)
+}
+
+// fileWrites 返回contents接口收到的写请求
+func (repo *fakeRepo) fileWrites() []string {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var writes []string
+	for _, write := range repo.writes {
+		if strings.Contains(write, " contents/") {
+			writes = append(writes, write)
+		}
+	}
+	return writes
+}
+
+func initialFiles() map[string]string {
+	return map[string]string{"a.txt": "a", "old.txt": "o", "gone.txt": "g"}
+}
+
+func TestCommitFilesGitData(t *testing.T) {
+	repo := &fakeRepo{files: initialFiles()}
+	c := newFakeRepoClient(t, repo)
+
+	ops := []FileOperation{
+		{Action: FileActionCreate, Path: "new.txt", Content: []byte("n")},
+		{Action: FileActionUpdate, Path: "a.txt", Content: []byte("a2"), SHA: blobSHA("a")},
+		{Action: FileActionDelete, Path: "gone.txt"},
+		{Action: FileActionMove, PreviousPath: "old.txt", Path: "moved.txt"},
+	}
+	result, err := c.Contents.CommitFiles(context.Background(), "o", "r", "main", "批量修改", ops)
+	if err != nil {
+		t.Fatalf("CommitFiles() error = %v", err)
+	}
+	if result.Method != CommitMethodGitData || result.SHA != "commit1" || !slices.Equal(result.Commits, []string{"commit1"}) {
+		t.Fatalf("CommitFiles() = %+v", result)
+	}
+
+	wantWrites := []string{"POST git/blobs", "POST git/blobs", "POST git/trees", "POST git/commits", "PATCH git/refs/heads/main"}
+	if !slices.Equal(repo.writes, wantWrites) {
+		t.Fatalf("写请求 = %v, want %v", repo.writes, wantWrites)
+	}
+
+	var tree struct {
+		BaseTree string      `json:"base_tree"`
+		Tree     []treeInput `json:"tree"`
+	}
+	if err := json.Unmarshal([]byte(repo.bodies["git/trees"]), &tree); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, entry := range tree.Tree {
+		got[entry.Path] = "<nil>"
+		if entry.SHA != nil {
+			got[entry.Path] = *entry.SHA
+		}
+	}
+	want := map[string]string{
+		"new.txt":   blobSHA("n"),
+		"a.txt":     blobSHA("a2"),
+		"gone.txt":  "<nil>",
+		"old.txt":   "<nil>",
+		"moved.txt": blobSHA("o"),
+	}
+	if tree.BaseTree != "tree0" || !maps.Equal(got, want) {
+		t.Fatalf("树对象 = %s %v, want tree0 %v", tree.BaseTree, got, want)
+	}
+
+	var ref struct {
+		SHA   string `json:"sha"`
+		Force bool   `json:"force"`
+	}
+	json.Unmarshal([]byte(repo.bodies["git/refs/heads/main"]), &ref)
+	if ref.SHA != "commit1" || ref.Force {
+		t.Fatalf("更新分支 = %+v, want 快进到commit1", ref)
+	}
+}
+
+func TestCommitFilesConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		repo *fakeRepo
+		ops  []FileOperation
+	}{
+		{"分支已被其他提交更新", &fakeRepo{files: initialFiles(), refStatus: http.StatusUnprocessableEntity}, []FileOperation{{Action: FileActionCreate, Path: "new.txt", Content: []byte("n")}}},
+		{"文件SHA不一致", &fakeRepo{files: initialFiles()}, []FileOperation{{Action: FileActionUpdate, Path: "a.txt", Content: []byte("a2"), SHA: "stale"}}},
+		{"创建已存在的文件", &fakeRepo{files: initialFiles()}, []FileOperation{{Action: FileActionCreate, Path: "a.txt", Content: []byte("a2")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeRepoClient(t, tt.repo)
+			_, err := c.Contents.CommitFiles(context.Background(), "o", "r", "main", "修改", tt.ops)
+			if !errors.Is(err, ErrConflict) {
+				t.Fatalf("CommitFiles() error = %v, want ErrConflict", err)
+			}
+			// 冲突时不回退为逐个提交，分支和文件保持不变
+			if writes := tt.repo.fileWrites(); len(writes) != 0 {
+				t.Fatalf("不应逐个提交文件: %v", writes)
+			}
+			if files := tt.repo.snapshot(); !maps.Equal(files, initialFiles()) {
+				t.Fatalf("文件 = %v", files)
+			}
+		})
+	}
+}
+
+func TestCommitFilesSequentialFallback(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			repo := &fakeRepo{files: initialFiles(), gitData: status}
+			c := newFakeRepoClient(t, repo)
+
+			ops := []FileOperation{
+				{Action: FileActionCreate, Path: "new.txt", Content: []byte("n")},
+				{Action: FileActionUpdate, Path: "a.txt", Content: []byte("a2"), SHA: blobSHA("a")},
+				{Action: FileActionMove, PreviousPath: "old.txt", Path: "moved.txt"},
+			}
+			result, err := c.Contents.CommitFiles(context.Background(), "o", "r", "main", "批量修改", ops)
+			if err != nil {
+				t.Fatalf("CommitFiles() error = %v", err)
+			}
+			if result.Method != CommitMethodSequential || result.SHA != "c4" || !slices.Equal(result.Commits, []string{"c1", "c2", "c3", "c4"}) {
+				t.Fatalf("CommitFiles() = %+v", result)
+			}
+			want := map[string]string{"new.txt": "n", "a.txt": "a2", "moved.txt": "o", "gone.txt": "g"}
+			if files := repo.snapshot(); !maps.Equal(files, want) {
+				t.Fatalf("文件 = %v, want %v", files, want)
+			}
+		})
+	}
+}
+
+func TestCommitFilesSequentialRollback(t *testing.T) {
+	repo := &fakeRepo{files: initialFiles(), gitData: http.StatusNotFound, failPath: "old.txt"}
+	c := newFakeRepoClient(t, repo)
+
+	// 移动文件时删除源文件失败，此前完成的操作按相反顺序撤销
+	ops := []FileOperation{
+		{Action: FileActionCreate, Path: "new.txt", Content: []byte("n")},
+		{Action: FileActionUpdate, Path: "a.txt", Content: []byte("a2")},
+		{Action: FileActionDelete, Path: "gone.txt"},
+		{Action: FileActionMove, PreviousPath: "old.txt", Path: "moved.txt"},
+	}
+	result, err := c.Contents.CommitFiles(context.Background(), "o", "r", "main", "批量修改", ops)
+	if err == nil || !strings.Contains(err.Error(), "已撤销此前完成的4个操作") {
+		t.Fatalf("CommitFiles() error = %v", err)
+	}
+	if !errors.Is(err, ErrServer) {
+		t.Fatalf("CommitFiles() error = %v, want ErrServer", err)
+	}
+	if result == nil || len(result.Commits) != 4 {
+		t.Fatalf("CommitFiles() = %+v, want 失败前的4个提交", result)
+	}
+
+	if files := repo.snapshot(); !maps.Equal(files, initialFiles()) {
+		t.Fatalf("撤销后的文件 = %v, want %v", files, initialFiles())
+	}
+	wantWrites := []string{
+		"POST contents/new.txt",
+		"PUT contents/a.txt",
+		"DELETE contents/gone.txt",
+		"POST contents/moved.txt",
+		"DELETE contents/old.txt",
+		// 撤销
+		"DELETE contents/moved.txt",
+		"POST contents/gone.txt",
+		"PUT contents/a.txt",
+		"DELETE contents/new.txt",
+	}
+	if writes := repo.fileWrites(); !slices.Equal(writes, wantWrites) {
+		t.Fatalf("写请求 = %v, want %v", writes, wantWrites)
+	}
+}
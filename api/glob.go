@@ -0,0 +1,70 @@
+package api
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	globCache   = map[string]*regexp.Regexp{}
+	globCacheMu sync.Mutex
+)
+
+// MatchGlob 判断name是否匹配glob模式。*匹配除/以外的任意字符，**匹配任意层级的目录，?匹配单个字符；
+// 模式中不含/时只与name的最后一段比较，如*.md可以匹配docs/README.md
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+	}
+	return globRegexp(pattern).MatchString(name)
+}
+
+// MatchAnyGlob 判断name是否匹配任意一个glob模式
+func MatchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// globRegexp 将glob模式转换为正则表达式，结果会被缓存
+func globRegexp(pattern string) *regexp.Regexp {
+	globCacheMu.Lock()
+	defer globCacheMu.Unlock()
+	if re, ok := globCache[pattern]; ok {
+		return re
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				// **/ 匹配零个或多个目录
+				i++
+				b.WriteString("(?:.*/)?")
+			} else {
+				b.WriteString(".*")
+			}
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	re := regexp.MustCompile(b.String())
+	globCache[pattern] = re
+	return re
+}
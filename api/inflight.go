@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// inflightCall 表示一个正在进行的上游请求
+type inflightCall struct {
+	done    chan struct{}
+	resp    *Response
+	err     error
+	waiters int                // 仍在等待结果的调用方数量
+	cancel  context.CancelFunc // 所有调用方都放弃等待时取消上游请求
+}
+
+// inflightGroup 合并并发的相同请求，同一个键同时只有一个上游请求
+type inflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+// do 执行fn并返回结果；如果相同键的请求正在进行，则等待其结果，shared为true
+// 上游请求使用独立的上下文，保留首个调用方上下文中的值，
+// 只有当所有等待的调用方都取消后才会被取消
+func (g *inflightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*Response, error)) (resp *Response, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*inflightCall)
+	}
+	call, found := g.calls[key]
+	if found {
+		call.waiters++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &inflightCall{
+			done:    make(chan struct{}),
+			waiters: 1,
+			cancel:  cancel,
+		}
+		g.calls[key] = call
+
+		go func() {
+			call.resp, call.err = fn(callCtx)
+
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+
+			cancel()
+			close(call.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.resp, call.err, found
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err(), found
+	}
+}
+
+// RequestStats 表示请求缓存和合并的统计数据
+type RequestStats struct {
+	CacheHits     int64 `json:"cache_hits"`    // 直接命中缓存的GET请求数
+	CacheMisses   int64 `json:"cache_misses"`  // 未命中缓存的GET请求数
+	Revalidations int64 `json:"revalidations"` // 条件请求返回304、复用缓存的次数
+	Deduplicated  int64 `json:"deduplicated"`  // 合并到正在进行的相同请求中的GET请求数
+	Upstream      int64 `json:"upstream"`      // 实际发往GitCode的HTTP请求数（含重试）
+	CacheEntries  int   `json:"cache_entries"` // 当前缓存条目数
+	CacheBytes    int   `json:"cache_bytes"`   // 当前缓存占用字节数
+}
+
+// requestCounters 请求统计计数器
+type requestCounters struct {
+	cacheHits     atomic.Int64
+	cacheMisses   atomic.Int64
+	revalidations atomic.Int64
+	deduplicated  atomic.Int64
+	upstream      atomic.Int64
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+)
+
+type noCacheKey struct{}
+
+// WithNoCache 返回一个跳过缓存读取的上下文，请求结果仍会写入缓存
+func WithNoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// noCache 判断上下文是否要求跳过缓存
+func noCache(ctx context.Context) bool {
+	skip, _ := ctx.Value(noCacheKey{}).(bool)
+	return skip
+}
+
+// invalidationScopes 返回写操作影响的缓存路径范围
+// 仓库下的任何写操作都会使该仓库的全部缓存失效，以保证随后读取到最新数据
+func invalidationScopes(path string) []string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	scopes := []string{"/search"}
+
+	switch {
+	case len(segments) >= 3 && segments[0] == "repos":
+		scopes = append(scopes, "/"+strings.Join(segments[:3], "/"))
+	case len(segments) >= 2 && (segments[0] == "orgs" || segments[0] == "users"):
+		scopes = append(scopes, "/"+strings.Join(segments[:2], "/"))
+	case len(segments) >= 1 && segments[0] == "user":
+		scopes = append(scopes, "/user")
+		// 星标操作还会影响仓库的星标数
+		if len(segments) >= 4 && segments[1] == "starred" {
+			scopes = append(scopes, "/repos/"+segments[2]+"/"+segments[3])
+		}
+	}
+
+	// 创建或转移仓库会改变用户和组织的仓库列表
+	if strings.HasSuffix(path, "/repos") || strings.HasSuffix(path, "/transfer") {
+		scopes = append(scopes, "/user", "/users", "/orgs")
+	}
+
+	return scopes
+}
+
+// invalidate 使写操作影响的GET缓存失效
+func (c *GitCodeAPI) invalidate(path string) {
+	path = strings.TrimPrefix(path, c.BaseURL)
+	scopes := invalidationScopes(path)
+	prefixes := make([]string, 0, 4)
+	for _, scope := range scopes {
+		prefixes = append(prefixes, "GET:"+c.BaseURL+scope)
+	}
+
+	// 先增加代数再删除缓存，此后完成的、在失效前发出的GET请求不会写回旧数据
+	c.generations.bump(scopes)
+
+	removed := c.Cache.DeleteFunc(func(key string) bool {
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			// 只匹配完整的路径段，避免 /repos/a/b 误伤 /repos/a/bc
+			rest := key[len(prefix):]
+			if rest == "" || rest[0] == '/' || rest[0] == '?' {
+				return true
+			}
+		}
+		return false
+	})
+	if removed > 0 {
+		log.Printf("写操作使%d条缓存失效: %s", removed, path)
+	}
+}
+
+// cacheGenerations 记录各缓存范围的失效次数
+// GET请求发出前记录所在范围的代数，写入缓存前再次比较，
+// 代数变化说明请求期间有写操作使缓存失效，读到的可能是旧数据，不再写入缓存
+type cacheGenerations struct {
+	mu   sync.Mutex
+	gens map[string]uint64
+}
+
+// bump 使各范围的代数加一
+func (g *cacheGenerations) bump(scopes []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.gens == nil {
+		g.gens = make(map[string]uint64)
+	}
+	for _, scope := range scopes {
+		g.gens[scope]++
+	}
+}
+
+// current 返回路径所在各范围的代数之和，任一范围失效后都会变化
+func (g *cacheGenerations) current(path string) uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.sum(path)
+}
+
+// storeIf 在路径的代数仍为gen时调用store写入缓存，返回是否写入
+// 比较和写入在同一把锁内完成，避免与失效操作交错
+func (g *cacheGenerations) storeIf(path string, gen uint64, store func()) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.sum(path) != gen {
+		return false
+	}
+	store()
+	return true
+}
+
+// sum 累加路径每一级前缀的代数，调用方需持有锁
+func (g *cacheGenerations) sum(path string) uint64 {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	var total uint64
+	prefix := ""
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		prefix += "/" + segment
+		total += g.gens[prefix]
+	}
+	return total
+}
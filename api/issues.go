@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -22,6 +23,7 @@ type Issue struct {
 	Labels      []Label   `json:"labels"`
 	Assignees   []User    `json:"assignees"`
 	Comments    int       `json:"comments"`
+	Milestone   *Milestone `json:"milestone"`
 	PullRequest *PRRef    `json:"pull_request,omitempty"`
 }
 
@@ -53,6 +55,7 @@ type CreateIssueOptions struct {
 	Body      string   `json:"body,omitempty"`
 	Assignees []string `json:"assignees,omitempty"`
 	Labels    []string `json:"labels,omitempty"`
+	Milestone int      `json:"milestone,omitempty"`
 }
 
 // UpdateIssueOptions 表示更新Issue的参数
@@ -62,28 +65,31 @@ type UpdateIssueOptions struct {
 	State     string   `json:"state,omitempty"`
 	Assignees []string `json:"assignees,omitempty"`
 	Labels    []string `json:"labels,omitempty"`
+	Milestone *int     `json:"milestone,omitempty"` // 为nil时不修改，指向0时移除里程碑
 }
 
 // ListIssues 列出仓库的Issues
-func (api *IssueAPI) ListIssues(owner, repo string) ([]Issue, error) {
-	path := fmt.Sprintf("/repos/%s/%s/issues", owner, repo)
-	resp, err := api.Client.GET(path, nil)
-	if err != nil {
+func (api *IssueAPI) ListIssues(ctx context.Context, owner, repo string, filter *ListIssuesOptions, opts *ListOptions) ([]Issue, error) {
+	if err := filter.validate(); err != nil {
 		return nil, err
 	}
 	
-	var issues []Issue
-	if err := json.Unmarshal(resp, &issues); err != nil {
-		return nil, fmt.Errorf("解析Issues列表失败: %w", err)
+	path := fmt.Sprintf("/repos/%s/%s/issues", owner, repo)
+	issues, err := paginateFiltered(ctx, api.Client, path, filter.values(), opts, decodeList[Issue], func(issue Issue) bool {
+		return filter.match(issueListItem(issue))
+	})
+	if err != nil {
+		return nil, err
 	}
 	
+	sortItems(issues, filter, issueListItem)
 	return issues, nil
 }
 
 // GetIssue 获取特定Issue的详细信息
-func (api *IssueAPI) GetIssue(owner, repo string, issueNumber int) (*Issue, error) {
+func (api *IssueAPI) GetIssue(ctx context.Context, owner, repo string, issueNumber int) (*Issue, error) {
 	path := fmt.Sprintf("/repos/%s/%s/issues/%d", owner, repo, issueNumber)
-	resp, err := api.Client.GET(path, nil)
+	resp, err := api.Client.GET(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -97,14 +103,9 @@ func (api *IssueAPI) GetIssue(owner, repo string, issueNumber int) (*Issue, erro
 }
 
 // CreateIssue 创建新Issue
-func (api *IssueAPI) CreateIssue(owner, repo, title, body string) (*Issue, error) {
+func (api *IssueAPI) CreateIssue(ctx context.Context, owner, repo string, options CreateIssueOptions) (*Issue, error) {
 	path := fmt.Sprintf("/repos/%s/%s/issues", owner, repo)
-	options := CreateIssueOptions{
-		Title: title,
-		Body:  body,
-	}
-	
-	resp, err := api.Client.POST(path, nil, options)
+	resp, err := api.Client.POST(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
@@ -118,9 +119,9 @@ func (api *IssueAPI) CreateIssue(owner, repo, title, body string) (*Issue, error
 }
 
 // UpdateIssue 更新Issue
-func (api *IssueAPI) UpdateIssue(owner, repo string, issueNumber int, options UpdateIssueOptions) (*Issue, error) {
+func (api *IssueAPI) UpdateIssue(ctx context.Context, owner, repo string, issueNumber int, options UpdateIssueOptions) (*Issue, error) {
 	path := fmt.Sprintf("/repos/%s/%s/issues/%d", owner, repo, issueNumber)
-	resp, err := api.Client.PATCH(path, nil, options)
+	resp, err := api.Client.PATCH(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
@@ -134,43 +135,38 @@ func (api *IssueAPI) UpdateIssue(owner, repo string, issueNumber int, options Up
 }
 
 // CloseIssue 关闭Issue
-func (api *IssueAPI) CloseIssue(owner, repo string, issueNumber int) (*Issue, error) {
-	return api.UpdateIssue(owner, repo, issueNumber, UpdateIssueOptions{
+func (api *IssueAPI) CloseIssue(ctx context.Context, owner, repo string, issueNumber int) (*Issue, error) {
+	return api.UpdateIssue(ctx, owner, repo, issueNumber, UpdateIssueOptions{
 		State: "closed",
 	})
 }
 
 // ReopenIssue 重新打开Issue
-func (api *IssueAPI) ReopenIssue(owner, repo string, issueNumber int) (*Issue, error) {
-	return api.UpdateIssue(owner, repo, issueNumber, UpdateIssueOptions{
+func (api *IssueAPI) ReopenIssue(ctx context.Context, owner, repo string, issueNumber int) (*Issue, error) {
+	return api.UpdateIssue(ctx, owner, repo, issueNumber, UpdateIssueOptions{
 		State: "open",
 	})
 }
 
 // ListComments 列出Issue的评论
-func (api *IssueAPI) ListComments(owner, repo string, issueNumber int) ([]Comment, error) {
+func (api *IssueAPI) ListComments(ctx context.Context, owner, repo string, issueNumber int, opts *ListOptions) ([]Comment, error) {
 	path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments", owner, repo, issueNumber)
-	resp, err := api.Client.GET(path, nil)
+	comments, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Comment])
 	if err != nil {
 		return nil, err
 	}
 	
-	var comments []Comment
-	if err := json.Unmarshal(resp, &comments); err != nil {
-		return nil, fmt.Errorf("解析评论列表失败: %w", err)
-	}
-	
 	return comments, nil
 }
 
 // AddComment 添加Issue评论
-func (api *IssueAPI) AddComment(owner, repo string, issueNumber int, body string) (*Comment, error) {
+func (api *IssueAPI) AddComment(ctx context.Context, owner, repo string, issueNumber int, body string) (*Comment, error) {
 	path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments", owner, repo, issueNumber)
 	options := map[string]string{
 		"body": body,
 	}
 	
-	resp, err := api.Client.POST(path, nil, options)
+	resp, err := api.Client.POST(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
@@ -184,13 +180,13 @@ func (api *IssueAPI) AddComment(owner, repo string, issueNumber int, body string
 }
 
 // EditComment 编辑Issue评论
-func (api *IssueAPI) EditComment(owner, repo string, commentID int, body string) (*Comment, error) {
+func (api *IssueAPI) EditComment(ctx context.Context, owner, repo string, commentID int, body string) (*Comment, error) {
 	path := fmt.Sprintf("/repos/%s/%s/issues/comments/%d", owner, repo, commentID)
 	options := map[string]string{
 		"body": body,
 	}
 	
-	resp, err := api.Client.PATCH(path, nil, options)
+	resp, err := api.Client.PATCH(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
@@ -204,48 +200,38 @@ func (api *IssueAPI) EditComment(owner, repo string, commentID int, body string)
 }
 
 // DeleteComment 删除Issue评论
-func (api *IssueAPI) DeleteComment(owner, repo string, commentID int) error {
+func (api *IssueAPI) DeleteComment(ctx context.Context, owner, repo string, commentID int) error {
 	path := fmt.Sprintf("/repos/%s/%s/issues/comments/%d", owner, repo, commentID)
-	_, err := api.Client.DELETE(path, nil)
+	_, err := api.Client.DELETE(ctx, path, nil)
 	return err
 }
 
 // ListLabels 列出仓库的标签
-func (api *IssueAPI) ListLabels(owner, repo string) ([]Label, error) {
+func (api *IssueAPI) ListLabels(ctx context.Context, owner, repo string, opts *ListOptions) ([]Label, error) {
 	path := fmt.Sprintf("/repos/%s/%s/labels", owner, repo)
-	resp, err := api.Client.GET(path, nil)
+	labels, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Label])
 	if err != nil {
 		return nil, err
 	}
 	
-	var labels []Label
-	if err := json.Unmarshal(resp, &labels); err != nil {
-		return nil, fmt.Errorf("解析标签列表失败: %w", err)
-	}
-	
 	return labels, nil
 }
 
 // GetIssueLabels 获取Issue的标签
-func (api *IssueAPI) GetIssueLabels(owner, repo string, issueNumber int) ([]Label, error) {
+func (api *IssueAPI) GetIssueLabels(ctx context.Context, owner, repo string, issueNumber int, opts *ListOptions) ([]Label, error) {
 	path := fmt.Sprintf("/repos/%s/%s/issues/%d/labels", owner, repo, issueNumber)
-	resp, err := api.Client.GET(path, nil)
+	labels, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Label])
 	if err != nil {
 		return nil, err
 	}
 	
-	var labels []Label
-	if err := json.Unmarshal(resp, &labels); err != nil {
-		return nil, fmt.Errorf("解析Issue标签失败: %w", err)
-	}
-	
 	return labels, nil
 }
 
 // AddLabelsToIssue 为Issue添加标签
-func (api *IssueAPI) AddLabelsToIssue(owner, repo string, issueNumber int, labels []string) ([]Label, error) {
+func (api *IssueAPI) AddLabelsToIssue(ctx context.Context, owner, repo string, issueNumber int, labels []string) ([]Label, error) {
 	path := fmt.Sprintf("/repos/%s/%s/issues/%d/labels", owner, repo, issueNumber)
-	resp, err := api.Client.POST(path, nil, labels)
+	resp, err := api.Client.POST(ctx, path, nil, labels)
 	if err != nil {
 		return nil, err
 	}
@@ -259,32 +245,23 @@ func (api *IssueAPI) AddLabelsToIssue(owner, repo string, issueNumber int, label
 }
 
 // RemoveLabelFromIssue 从Issue移除标签
-func (api *IssueAPI) RemoveLabelFromIssue(owner, repo string, issueNumber int, label string) error {
+func (api *IssueAPI) RemoveLabelFromIssue(ctx context.Context, owner, repo string, issueNumber int, label string) error {
 	path := fmt.Sprintf("/repos/%s/%s/issues/%d/labels/%s", owner, repo, issueNumber, url.PathEscape(label))
-	_, err := api.Client.DELETE(path, nil)
+	_, err := api.Client.DELETE(ctx, path, nil)
 	return err
 }
 
 // SearchIssues 搜索Issues
-func (api *IssueAPI) SearchIssues(query string) ([]Issue, error) {
+func (api *IssueAPI) SearchIssues(ctx context.Context, query string, opts *ListOptions) ([]Issue, error) {
 	// 搜索API是通过SearchAPI处理的
 	// 这里提供一个便捷方法
 	values := url.Values{}
 	values.Set("q", query)
 	
-	resp, err := api.Client.GET("/search/issues", values)
+	items, err := paginate(ctx, api.Client, "/search/issues", values, opts, decodeSearchItems[Issue])
 	if err != nil {
 		return nil, err
 	}
 	
-	var searchResult struct {
-		TotalCount int     `json:"total_count"`
-		Items      []Issue `json:"items"`
-	}
-	
-	if err := json.Unmarshal(resp, &searchResult); err != nil {
-		return nil, fmt.Errorf("解析搜索结果失败: %w", err)
-	}
-	
-	return searchResult.Items, nil
-} 
\ No newline at end of file
+	return items, nil
+}
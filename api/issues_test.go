@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUpdateMilestone(t *testing.T) {
+	zero, three := 0, 3
+	tests := []struct {
+		name      string
+		milestone *int
+		want      string
+	}{
+		{"不修改里程碑", nil, `{"title":"t"}`},
+		{"设置里程碑", &three, `{"title":"t","milestone":3}`},
+		{"移除里程碑", &zero, `{"title":"t","milestone":0}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRoutedServer(t, map[string]scriptedResponse{
+				"PATCH /repos/o/r/issues/1": {status: 200, body: `{"number":1}`},
+				"PATCH /repos/o/r/pulls/2":  {status: 200, body: `{"number":2}`},
+			})
+			c := newRoutedClient(t, s)
+			c.Issues = NewIssueAPI(c)
+			c.Pulls = NewPullRequestAPI(c)
+			ctx := context.Background()
+
+			if _, err := c.Issues.UpdateIssue(ctx, "o", "r", 1, UpdateIssueOptions{Title: "t", Milestone: tt.milestone}); err != nil {
+				t.Fatalf("UpdateIssue() error = %v", err)
+			}
+			if got := s.body("PATCH /repos/o/r/issues/1"); got != tt.want {
+				t.Fatalf("UpdateIssue() 请求体 = %s, want %s", got, tt.want)
+			}
+			if _, err := c.Pulls.UpdatePullRequest(ctx, "o", "r", 2, UpdatePullRequestOptions{Title: "t", Milestone: tt.milestone}); err != nil {
+				t.Fatalf("UpdatePullRequest() error = %v", err)
+			}
+			if got := s.body("PATCH /repos/o/r/pulls/2"); got != tt.want {
+				t.Fatalf("UpdatePullRequest() 请求体 = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LabelOptions 表示创建或更新标签的参数，更新时Name不为空表示重命名
+type LabelOptions struct {
+	Name        string `json:"name,omitempty"`
+	Color       string `json:"color,omitempty"` // 6位十六进制颜色，不带#
+	Description string `json:"description,omitempty"`
+}
+
+// LabelDefinition 表示标签规范中的一个标签
+type LabelDefinition struct {
+	Name        string   `json:"name" yaml:"name"`
+	Color       string   `json:"color" yaml:"color"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"` // 为空时不修改现有描述
+	Aliases     []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`         // 旧名称，仓库中存在时重命名为Name
+}
+
+// LabelSpec 表示声明式的标签规范，用于让多个仓库的标签保持一致
+type LabelSpec struct {
+	Labels []LabelDefinition `json:"labels" yaml:"labels"`
+	Prune  bool              `json:"prune,omitempty" yaml:"prune,omitempty"` // 为true时删除规范中没有的标签
+}
+
+// LabelSyncResult 表示一个仓库的标签同步结果
+type LabelSyncResult struct {
+	Repo    string   `json:"repo"`
+	DryRun  bool     `json:"dry_run"`
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Renamed []string `json:"renamed"` // 格式为"旧名称 -> 新名称"
+	Deleted []string `json:"deleted"`
+}
+
+// RelabelResult 表示批量重命名或合并标签的结果
+type RelabelResult struct {
+	Mode          string   `json:"mode"` // rename或merge
+	From          []string `json:"from"`
+	To            string   `json:"to"`
+	DryRun        bool     `json:"dry_run"`
+	Issues        []int    `json:"issues"`        // 标签被替换的Issue编号
+	PullRequests  []int    `json:"pull_requests"` // 标签被替换的PR编号
+	DeletedLabels []string `json:"deleted_labels"`
+}
+
+var labelColorPattern = regexp.MustCompile(`^[0-9a-f]{6}$`)
+
+// normalizeLabelColor 去掉颜色前的#并转为小写
+func normalizeLabelColor(color string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(color), "#"))
+}
+
+// ParseLabelSpec 解析YAML或JSON格式的标签规范并检查其有效性
+func ParseLabelSpec(data []byte) (*LabelSpec, error) {
+	var spec LabelSpec
+	// JSON是YAML的子集，两种格式都用YAML解析
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("%w: 解析标签规范失败: %v", ErrValidation, err)
+	}
+	if len(spec.Labels) == 0 {
+		return nil, fmt.Errorf("%w: 标签规范中没有标签", ErrValidation)
+	}
+
+	seen := map[string]string{}
+	for i := range spec.Labels {
+		def := &spec.Labels[i]
+		def.Name = strings.TrimSpace(def.Name)
+		def.Color = normalizeLabelColor(def.Color)
+		if def.Name == "" {
+			return nil, fmt.Errorf("%w: 第%d个标签缺少名称", ErrValidation, i+1)
+		}
+		if !labelColorPattern.MatchString(def.Color) {
+			return nil, fmt.Errorf("%w: 标签 %s 的颜色 %q 不是6位十六进制颜色", ErrValidation, def.Name, def.Color)
+		}
+		for _, name := range append([]string{def.Name}, def.Aliases...) {
+			key := strings.ToLower(name)
+			if other, ok := seen[key]; ok {
+				return nil, fmt.Errorf("%w: 名称 %s 同时出现在标签 %s 和 %s 中", ErrValidation, name, other, def.Name)
+			}
+			seen[key] = def.Name
+		}
+	}
+
+	return &spec, nil
+}
+
+// GetLabel 获取仓库标签
+func (api *IssueAPI) GetLabel(ctx context.Context, owner, repo, name string) (*Label, error) {
+	path := fmt.Sprintf("/repos/%s/%s/labels/%s", owner, repo, url.PathEscape(name))
+	resp, err := api.Client.GET(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var label Label
+	if err := json.Unmarshal(resp, &label); err != nil {
+		return nil, fmt.Errorf("解析标签详情失败: %w", err)
+	}
+
+	return &label, nil
+}
+
+// CreateLabel 创建仓库标签
+func (api *IssueAPI) CreateLabel(ctx context.Context, owner, repo string, options LabelOptions) (*Label, error) {
+	options.Color = normalizeLabelColor(options.Color)
+	if options.Name == "" {
+		return nil, fmt.Errorf("%w: 标签名称不能为空", ErrValidation)
+	}
+	if !labelColorPattern.MatchString(options.Color) {
+		return nil, fmt.Errorf("%w: 颜色 %q 不是6位十六进制颜色", ErrValidation, options.Color)
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/labels", owner, repo)
+	resp, err := api.Client.POST(ctx, path, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var label Label
+	if err := json.Unmarshal(resp, &label); err != nil {
+		return nil, fmt.Errorf("解析新标签信息失败: %w", err)
+	}
+
+	return &label, nil
+}
+
+// UpdateLabel 更新仓库标签，options.Name不为空时重命名，已打上该标签的Issue会随之更新
+func (api *IssueAPI) UpdateLabel(ctx context.Context, owner, repo, name string, options LabelOptions) (*Label, error) {
+	if options.Color != "" {
+		options.Color = normalizeLabelColor(options.Color)
+		if !labelColorPattern.MatchString(options.Color) {
+			return nil, fmt.Errorf("%w: 颜色 %q 不是6位十六进制颜色", ErrValidation, options.Color)
+		}
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/labels/%s", owner, repo, url.PathEscape(name))
+	resp, err := api.Client.PATCH(ctx, path, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var label Label
+	if err := json.Unmarshal(resp, &label); err != nil {
+		return nil, fmt.Errorf("解析更新后的标签信息失败: %w", err)
+	}
+
+	return &label, nil
+}
+
+// DeleteLabel 删除仓库标签，标签会从所有Issue上移除
+func (api *IssueAPI) DeleteLabel(ctx context.Context, owner, repo, name string) error {
+	path := fmt.Sprintf("/repos/%s/%s/labels/%s", owner, repo, url.PathEscape(name))
+	_, err := api.Client.DELETE(ctx, path, nil)
+	return err
+}
+
+// SyncLabels 按照标签规范同步仓库的标签：创建缺少的标签，更新颜色和描述不一致的标签，
+// 将别名重命名为规范名称，spec.Prune为true时删除规范中没有的标签。dryRun为true时只返回计划，不做修改
+func (api *IssueAPI) SyncLabels(ctx context.Context, owner, repo string, spec *LabelSpec, dryRun bool) (*LabelSyncResult, error) {
+	existing, err := api.ListLabels(WithNoCache(ctx), owner, repo, &ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取仓库标签失败: %w", err)
+	}
+
+	byName := make(map[string]Label, len(existing))
+	for _, label := range existing {
+		byName[strings.ToLower(label.Name)] = label
+	}
+
+	result := &LabelSyncResult{
+		Repo:    owner + "/" + repo,
+		DryRun:  dryRun,
+		Created: []string{},
+		Updated: []string{},
+		Renamed: []string{},
+		Deleted: []string{},
+	}
+	kept := map[string]bool{}
+
+	for _, def := range spec.Labels {
+		options := LabelOptions{Color: def.Color, Description: def.Description}
+
+		current, found := byName[strings.ToLower(def.Name)]
+		if !found {
+			for _, alias := range def.Aliases {
+				if current, found = byName[strings.ToLower(alias)]; found {
+					break
+				}
+			}
+		}
+
+		switch {
+		case !found:
+			result.Created = append(result.Created, def.Name)
+			if !dryRun {
+				options.Name = def.Name
+				if _, err := api.CreateLabel(ctx, owner, repo, options); err != nil {
+					return result, fmt.Errorf("创建标签 %s 失败: %w", def.Name, err)
+				}
+			}
+		case current.Name != def.Name:
+			result.Renamed = append(result.Renamed, current.Name+" -> "+def.Name)
+			if !dryRun {
+				options.Name = def.Name
+				if _, err := api.UpdateLabel(ctx, owner, repo, current.Name, options); err != nil {
+					return result, fmt.Errorf("重命名标签 %s 失败: %w", current.Name, err)
+				}
+			}
+		case normalizeLabelColor(current.Color) != def.Color || (def.Description != "" && current.Description != def.Description):
+			result.Updated = append(result.Updated, def.Name)
+			if !dryRun {
+				if _, err := api.UpdateLabel(ctx, owner, repo, current.Name, options); err != nil {
+					return result, fmt.Errorf("更新标签 %s 失败: %w", current.Name, err)
+				}
+			}
+		}
+		if found {
+			kept[strings.ToLower(current.Name)] = true
+		}
+	}
+
+	if spec.Prune {
+		for _, label := range existing {
+			if kept[strings.ToLower(label.Name)] {
+				continue
+			}
+			result.Deleted = append(result.Deleted, label.Name)
+			if !dryRun {
+				if err := api.DeleteLabel(ctx, owner, repo, label.Name); err != nil {
+					return result, fmt.Errorf("删除标签 %s 失败: %w", label.Name, err)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Relabel 将from中的标签替换为to。from只有一个且to不存在时直接重命名标签；
+// 否则合并：为所有带from标签的Issue和PR加上to标签并移除原标签，deleteOld为true时最后删除原标签
+func (api *IssueAPI) Relabel(ctx context.Context, owner, repo string, from []string, to string, deleteOld, dryRun bool) (*RelabelResult, error) {
+	if len(from) == 0 || to == "" {
+		return nil, fmt.Errorf("%w: 需要提供原标签和目标标签", ErrValidation)
+	}
+
+	existing, err := api.ListLabels(WithNoCache(ctx), owner, repo, &ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取仓库标签失败: %w", err)
+	}
+	byName := make(map[string]Label, len(existing))
+	for _, label := range existing {
+		byName[strings.ToLower(label.Name)] = label
+	}
+
+	var sources []Label
+	for _, name := range from {
+		if strings.EqualFold(name, to) {
+			continue
+		}
+		label, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("%w: 标签 %s 不存在", ErrNotFound, name)
+		}
+		sources = append(sources, label)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%w: 原标签与目标标签相同", ErrValidation)
+	}
+
+	result := &RelabelResult{
+		From:          from,
+		To:            to,
+		DryRun:        dryRun,
+		Issues:        []int{},
+		PullRequests:  []int{},
+		DeletedLabels: []string{},
+	}
+
+	target, targetExists := byName[strings.ToLower(to)]
+	if !targetExists && len(sources) == 1 {
+		// 目标标签不存在时重命名即可，所有Issue上的标签随之更新
+		result.Mode = "rename"
+		if dryRun {
+			return result, nil
+		}
+		if _, err := api.UpdateLabel(ctx, owner, repo, sources[0].Name, LabelOptions{Name: to}); err != nil {
+			return nil, fmt.Errorf("重命名标签 %s 失败: %w", sources[0].Name, err)
+		}
+		return result, nil
+	}
+
+	result.Mode = "merge"
+	if !targetExists {
+		target = Label{Name: to, Color: sources[0].Color, Description: sources[0].Description}
+		if !dryRun {
+			if _, err := api.CreateLabel(ctx, owner, repo, LabelOptions{Name: to, Color: target.Color, Description: target.Description}); err != nil {
+				return nil, fmt.Errorf("创建目标标签 %s 失败: %w", to, err)
+			}
+		}
+	}
+
+	relabeledIssues := map[int]bool{}
+	relabeledPulls := map[int]bool{}
+	for _, source := range sources {
+		filter := &ListIssuesOptions{State: "all", Labels: []string{source.Name}}
+		issues, err := api.ListIssues(WithNoCache(ctx), owner, repo, filter, &ListOptions{})
+		if err != nil {
+			return result, fmt.Errorf("获取带有标签 %s 的Issue失败: %w", source.Name, err)
+		}
+		for _, issue := range issues {
+			// PR在下面通过PR接口处理
+			if issue.PullRequest != nil {
+				continue
+			}
+			if !relabeledIssues[issue.Number] {
+				relabeledIssues[issue.Number] = true
+				result.Issues = append(result.Issues, issue.Number)
+			}
+			if dryRun {
+				continue
+			}
+			if !hasLabel(issue.Labels, target.Name) {
+				if _, err := api.AddLabelsToIssue(ctx, owner, repo, issue.Number, []string{target.Name}); err != nil {
+					return result, fmt.Errorf("为Issue #%d 添加标签 %s 失败: %w", issue.Number, target.Name, err)
+				}
+			}
+			if err := api.RemoveLabelFromIssue(ctx, owner, repo, issue.Number, source.Name); err != nil {
+				return result, fmt.Errorf("从Issue #%d 移除标签 %s 失败: %w", issue.Number, source.Name, err)
+			}
+		}
+
+		pulls, err := api.Client.Pulls.ListPullRequests(WithNoCache(ctx), owner, repo, filter, &ListOptions{})
+		if err != nil {
+			return result, fmt.Errorf("获取带有标签 %s 的PR失败: %w", source.Name, err)
+		}
+		for _, pr := range pulls {
+			if !relabeledPulls[pr.Number] {
+				relabeledPulls[pr.Number] = true
+				result.PullRequests = append(result.PullRequests, pr.Number)
+			}
+			if dryRun {
+				continue
+			}
+			if !hasLabel(pr.Labels, target.Name) {
+				if _, err := api.Client.Pulls.AddLabelsToPullRequest(ctx, owner, repo, pr.Number, []string{target.Name}); err != nil {
+					return result, fmt.Errorf("为PR #%d 添加标签 %s 失败: %w", pr.Number, target.Name, err)
+				}
+			}
+			if err := api.Client.Pulls.RemoveLabelFromPullRequest(ctx, owner, repo, pr.Number, source.Name); err != nil {
+				return result, fmt.Errorf("从PR #%d 移除标签 %s 失败: %w", pr.Number, source.Name, err)
+			}
+		}
+
+		if deleteOld {
+			result.DeletedLabels = append(result.DeletedLabels, source.Name)
+			if !dryRun {
+				if err := api.DeleteLabel(ctx, owner, repo, source.Name); err != nil {
+					return result, fmt.Errorf("删除标签 %s 失败: %w", source.Name, err)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// hasLabel 判断标签列表中是否包含指定名称的标签，不区分大小写
+func hasLabel(labels []Label, name string) bool {
+	for _, label := range labels {
+		if strings.EqualFold(label.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseLabelSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"YAML", "labels:\n  - name: bug\n    color: '#D73A4A'\n    aliases: [defect]\nprune: true\n", false},
+		{"JSON", `{"labels":[{"name":"bug","color":"d73a4a"}]}`, false},
+		{"没有标签", "labels: []\n", true},
+		{"缺少名称", "labels:\n  - color: d73a4a\n", true},
+		{"颜色无效", "labels:\n  - name: bug\n    color: red\n", true},
+		{"别名与其他标签重名", "labels:\n  - name: bug\n    color: d73a4a\n  - name: defect\n    color: d73a4a\n    aliases: [BUG]\n", true},
+		{"格式错误", "labels: [", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParseLabelSpec([]byte(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidation) {
+					t.Fatalf("ParseLabelSpec() error = %v, want ErrValidation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLabelSpec() error = %v", err)
+			}
+			if def := spec.Labels[0]; def.Name != "bug" || def.Color != "d73a4a" {
+				t.Fatalf("Labels[0] = %+v, want 规范化后的颜色", def)
+			}
+		})
+	}
+}
+
+func TestSyncLabels(t *testing.T) {
+	spec := &LabelSpec{
+		Labels: []LabelDefinition{
+			{Name: "bug", Color: "ee0000"},
+			{Name: "feature", Color: "00ff00", Aliases: []string{"enhancement"}},
+			{Name: "docs", Color: "0000ff", Description: "文档"},
+			{Name: "wontfix", Color: "ffffff"},
+		},
+		Prune: true,
+	}
+	existing := `[
+		{"name":"bug","color":"ff0000"},
+		{"name":"enhancement","color":"00ff00"},
+		{"name":"stale","color":"000000"},
+		{"name":"wontfix","color":"FFFFFF"}
+	]`
+
+	for _, dryRun := range []bool{true, false} {
+		s := newRoutedServer(t, map[string]scriptedResponse{
+			"GET /repos/o/r/labels":               {status: 200, body: existing},
+			"POST /repos/o/r/labels":              {status: 201, body: `{"name":"docs"}`},
+			"PATCH /repos/o/r/labels/bug":         {status: 200, body: `{"name":"bug"}`},
+			"PATCH /repos/o/r/labels/enhancement": {status: 200, body: `{"name":"feature"}`},
+			"DELETE /repos/o/r/labels/stale":      {status: 204},
+		})
+		c := newRoutedClient(t, s)
+		c.Issues = NewIssueAPI(c)
+
+		result, err := c.Issues.SyncLabels(context.Background(), "o", "r", spec, dryRun)
+		if err != nil {
+			t.Fatalf("SyncLabels(dryRun=%v) error = %v", dryRun, err)
+		}
+		if !slices.Equal(result.Created, []string{"docs"}) ||
+			!slices.Equal(result.Updated, []string{"bug"}) ||
+			!slices.Equal(result.Renamed, []string{"enhancement -> feature"}) ||
+			!slices.Equal(result.Deleted, []string{"stale"}) {
+			t.Fatalf("SyncLabels(dryRun=%v) = %+v", dryRun, result)
+		}
+
+		var want []string
+		if !dryRun {
+			want = []string{
+				"PATCH /repos/o/r/labels/bug",
+				"PATCH /repos/o/r/labels/enhancement",
+				"POST /repos/o/r/labels",
+				"DELETE /repos/o/r/labels/stale",
+			}
+		}
+		if got := s.writes(); !slices.Equal(got, want) {
+			t.Fatalf("SyncLabels(dryRun=%v) 写请求 = %v, want %v", dryRun, got, want)
+		}
+	}
+}
+
+func TestRelabel(t *testing.T) {
+	labels := `[{"name":"bug","color":"ff0000"},{"name":"defect","color":"ee0000"}]`
+	// Issue列表中带pull_request的条目是PR，由PR接口处理
+	issues := `[
+		{"number":1,"state":"open","labels":[{"name":"bug"}]},
+		{"number":2,"state":"closed","labels":[{"name":"bug"},{"name":"defect"}]},
+		{"number":5,"state":"open","labels":[{"name":"bug"}],"pull_request":{}}
+	]`
+	pulls := `[{"number":5,"state":"open","labels":[{"name":"bug"}]}]`
+
+	tests := []struct {
+		name       string
+		to         string
+		deleteOld  bool
+		dryRun     bool
+		wantMode   string
+		wantIssues []int
+		wantPulls  []int
+		wantWrites []string
+	}{
+		{
+			name:       "目标标签不存在时重命名",
+			to:         "kind/bug",
+			deleteOld:  true,
+			wantMode:   "rename",
+			wantIssues: []int{},
+			wantPulls:  []int{},
+			wantWrites: []string{"PATCH /repos/o/r/labels/bug"},
+		},
+		{
+			name:       "合并到已有标签并删除原标签",
+			to:         "defect",
+			deleteOld:  true,
+			wantMode:   "merge",
+			wantIssues: []int{1, 2},
+			wantPulls:  []int{5},
+			wantWrites: []string{
+				"POST /repos/o/r/issues/1/labels",
+				"DELETE /repos/o/r/issues/1/labels/bug",
+				"DELETE /repos/o/r/issues/2/labels/bug",
+				"POST /repos/o/r/pulls/5/labels",
+				"DELETE /repos/o/r/pulls/5/labels/bug",
+				"DELETE /repos/o/r/labels/bug",
+			},
+		},
+		{
+			name:       "合并后保留原标签",
+			to:         "defect",
+			wantMode:   "merge",
+			wantIssues: []int{1, 2},
+			wantPulls:  []int{5},
+			wantWrites: []string{
+				"POST /repos/o/r/issues/1/labels",
+				"DELETE /repos/o/r/issues/1/labels/bug",
+				"DELETE /repos/o/r/issues/2/labels/bug",
+				"POST /repos/o/r/pulls/5/labels",
+				"DELETE /repos/o/r/pulls/5/labels/bug",
+			},
+		},
+		{
+			name:       "dry_run不做修改",
+			to:         "defect",
+			deleteOld:  true,
+			dryRun:     true,
+			wantMode:   "merge",
+			wantIssues: []int{1, 2},
+			wantPulls:  []int{5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRoutedServer(t, map[string]scriptedResponse{
+				"GET /repos/o/r/labels":                 {status: 200, body: labels},
+				"PATCH /repos/o/r/labels/bug":           {status: 200, body: `{"name":"kind/bug"}`},
+				"DELETE /repos/o/r/labels/bug":          {status: 204},
+				"GET /repos/o/r/issues":                 {status: 200, body: issues},
+				"POST /repos/o/r/issues/1/labels":       {status: 200, body: `[{"name":"bug"},{"name":"defect"}]`},
+				"DELETE /repos/o/r/issues/1/labels/bug": {status: 204},
+				"DELETE /repos/o/r/issues/2/labels/bug": {status: 204},
+				"GET /repos/o/r/pulls":                  {status: 200, body: pulls},
+				"POST /repos/o/r/pulls/5/labels":        {status: 200, body: `[{"name":"bug"},{"name":"defect"}]`},
+				"DELETE /repos/o/r/pulls/5/labels/bug":  {status: 204},
+			})
+			c := newRoutedClient(t, s)
+			c.Issues = NewIssueAPI(c)
+			c.Pulls = NewPullRequestAPI(c)
+
+			result, err := c.Issues.Relabel(context.Background(), "o", "r", []string{"bug"}, tt.to, tt.deleteOld, tt.dryRun)
+			if err != nil {
+				t.Fatalf("Relabel() error = %v", err)
+			}
+			if result.Mode != tt.wantMode || !slices.Equal(result.Issues, tt.wantIssues) || !slices.Equal(result.PullRequests, tt.wantPulls) {
+				t.Fatalf("Relabel() = %+v", result)
+			}
+			if got := s.writes(); !slices.Equal(got, tt.wantWrites) {
+				t.Fatalf("写请求 = %v, want %v", got, tt.wantWrites)
+			}
+			if tt.deleteOld && tt.wantMode == "merge" && !slices.Equal(result.DeletedLabels, []string{"bug"}) {
+				t.Fatalf("DeletedLabels = %v, want [bug]", result.DeletedLabels)
+			}
+		})
+	}
+
+	t.Run("原标签不存在", func(t *testing.T) {
+		s := newRoutedServer(t, map[string]scriptedResponse{"GET /repos/o/r/labels": {status: 200, body: labels}})
+		c := newRoutedClient(t, s)
+		c.Issues = NewIssueAPI(c)
+		if _, err := c.Issues.Relabel(context.Background(), "o", "r", []string{"missing"}, "defect", true, false); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Relabel() error = %v, want ErrNotFound", err)
+		}
+	})
+}
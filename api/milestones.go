@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Milestone 表示里程碑
+type Milestone struct {
+	ID           int    `json:"id"`
+	Number       int    `json:"number"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	State        string `json:"state"`
+	OpenIssues   int    `json:"open_issues"`
+	ClosedIssues int    `json:"closed_issues"`
+	DueOn        string `json:"due_on"`
+	Creator      *User  `json:"creator,omitempty"`
+	URL          string `json:"url"`
+	HTMLURL      string `json:"html_url"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+	ClosedAt     string `json:"closed_at"`
+}
+
+// DueTime 解析里程碑的截止时间，没有设置截止时间时返回false
+func (m *Milestone) DueTime() (time.Time, bool) {
+	if m.DueOn == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, m.DueOn); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.DateOnly, m.DueOn); err == nil {
+		// 只有日期时，截止到当天结束
+		return t.Add(24*time.Hour - time.Second), true
+	}
+	return time.Time{}, false
+}
+
+// MilestoneOptions 表示创建或更新里程碑的参数，更新时零值字段保持不变
+type MilestoneOptions struct {
+	Title       string `json:"title,omitempty"`
+	State       string `json:"state,omitempty"` // open或closed
+	Description string `json:"description,omitempty"`
+	DueOn       string `json:"due_on,omitempty"` // RFC3339格式
+}
+
+// MilestoneProgress 表示里程碑的完成进度
+type MilestoneProgress struct {
+	Milestone       Milestone     `json:"milestone"`
+	Open            int           `json:"open"`
+	Closed          int           `json:"closed"`
+	Total           int           `json:"total"`
+	PercentComplete float64       `json:"percent_complete"`
+	Overdue         bool          `json:"overdue"`                // 已过截止时间且仍有未关闭的条目
+	OverdueItems    []OverdueItem `json:"overdue_items"`          // 过期后仍未关闭的Issue和PR
+	OverdueMore     bool          `json:"overdue_more,omitempty"` // OverdueItems达到上限，还有更多未关闭的条目
+}
+
+// OverdueItem 表示过期后仍未关闭的Issue或PR的摘要
+type OverdueItem struct {
+	Number      int      `json:"number"`
+	Title       string   `json:"title"`
+	PullRequest bool     `json:"pull_request,omitempty"`
+	Assignees   []string `json:"assignees,omitempty"`
+	UpdatedAt   string   `json:"updated_at"`
+	HTMLURL     string   `json:"html_url"`
+}
+
+// ListMilestones 列出仓库的里程碑，state可以是open、closed或all，为空时只列出open
+func (api *MilestoneAPI) ListMilestones(ctx context.Context, owner, repo, state string, opts *ListOptions) ([]Milestone, error) {
+	path := fmt.Sprintf("/repos/%s/%s/milestones", owner, repo)
+	values := url.Values{}
+	if state != "" {
+		values.Set("state", state)
+	}
+
+	milestones, err := paginate(ctx, api.Client, path, values, opts, decodeList[Milestone])
+	if err != nil {
+		return nil, err
+	}
+
+	return milestones, nil
+}
+
+// GetMilestone 获取里程碑
+func (api *MilestoneAPI) GetMilestone(ctx context.Context, owner, repo string, number int) (*Milestone, error) {
+	path := fmt.Sprintf("/repos/%s/%s/milestones/%d", owner, repo, number)
+	resp, err := api.Client.GET(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestone Milestone
+	if err := json.Unmarshal(resp, &milestone); err != nil {
+		return nil, fmt.Errorf("解析里程碑详情失败: %w", err)
+	}
+
+	return &milestone, nil
+}
+
+// CreateMilestone 创建里程碑
+func (api *MilestoneAPI) CreateMilestone(ctx context.Context, owner, repo string, options MilestoneOptions) (*Milestone, error) {
+	if options.Title == "" {
+		return nil, fmt.Errorf("%w: 里程碑标题不能为空", ErrValidation)
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/milestones", owner, repo)
+	resp, err := api.Client.POST(ctx, path, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestone Milestone
+	if err := json.Unmarshal(resp, &milestone); err != nil {
+		return nil, fmt.Errorf("解析新里程碑信息失败: %w", err)
+	}
+
+	return &milestone, nil
+}
+
+// UpdateMilestone 更新里程碑
+func (api *MilestoneAPI) UpdateMilestone(ctx context.Context, owner, repo string, number int, options MilestoneOptions) (*Milestone, error) {
+	path := fmt.Sprintf("/repos/%s/%s/milestones/%d", owner, repo, number)
+	resp, err := api.Client.PATCH(ctx, path, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestone Milestone
+	if err := json.Unmarshal(resp, &milestone); err != nil {
+		return nil, fmt.Errorf("解析更新后的里程碑信息失败: %w", err)
+	}
+
+	return &milestone, nil
+}
+
+// DeleteMilestone 删除里程碑
+func (api *MilestoneAPI) DeleteMilestone(ctx context.Context, owner, repo string, number int) error {
+	path := fmt.Sprintf("/repos/%s/%s/milestones/%d", owner, repo, number)
+	_, err := api.Client.DELETE(ctx, path, nil)
+	return err
+}
+
+// GetProgress 统计里程碑的完成进度；已过截止时间时列出仍未关闭的Issue和PR，maxItems大于0时最多列出maxItems个
+func (api *MilestoneAPI) GetProgress(ctx context.Context, owner, repo string, number, maxItems int) (*MilestoneProgress, error) {
+	milestone, err := api.GetMilestone(ctx, owner, repo, number)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &MilestoneProgress{
+		Milestone:    *milestone,
+		Open:         milestone.OpenIssues,
+		Closed:       milestone.ClosedIssues,
+		Total:        milestone.OpenIssues + milestone.ClosedIssues,
+		OverdueItems: []OverdueItem{},
+	}
+	if progress.Total > 0 {
+		progress.PercentComplete = float64(progress.Closed*1000/progress.Total) / 10
+	}
+
+	due, ok := milestone.DueTime()
+	if !ok || time.Now().Before(due) || progress.Open == 0 {
+		return progress, nil
+	}
+
+	progress.Overdue = true
+	filter := &ListIssuesOptions{State: "open", Milestone: strconv.Itoa(number)}
+	opts := &ListOptions{PerPage: MaxPerPage, MaxItems: maxItems}
+	items, err := api.Client.Issues.ListIssues(ctx, owner, repo, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("获取里程碑中未关闭的条目失败: %w", err)
+	}
+	for _, item := range items {
+		overdue := OverdueItem{
+			Number:      item.Number,
+			Title:       item.Title,
+			PullRequest: item.PullRequest != nil,
+			UpdatedAt:   item.UpdatedAt,
+			HTMLURL:     item.HTMLURL,
+		}
+		for _, assignee := range item.Assignees {
+			overdue.Assignees = append(overdue.Assignees, assignee.Username)
+		}
+		progress.OverdueItems = append(progress.OverdueItems, overdue)
+	}
+	progress.OverdueMore = opts.NextPage > 0
+
+	return progress, nil
+}
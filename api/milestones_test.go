@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMilestoneProgressOverdueItems(t *testing.T) {
+	s := newScriptedServer(t,
+		scriptedResponse{status: 200, body: `{"number": 3, "title": "v1.0", "open_issues": 3, "closed_issues": 1, "due_on": "2020-01-01"}`},
+		scriptedResponse{status: 200, body: `[
+			{"number": 10, "title": "a", "state": "open", "milestone": {"number": 3}, "body": "long body", "assignees": [{"username": "alice"}], "updated_at": "2020-01-02T00:00:00Z"},
+			{"number": 11, "title": "b", "state": "open", "milestone": {"number": 3}, "pull_request": {"url": "https://api.gitcode.com/api/v5/repos/o/r/pulls/11"}},
+			{"number": 12, "title": "c", "state": "open", "milestone": {"number": 3}}
+		]`},
+	)
+	c := newTestClient(t, s, RetryPolicy{})
+	c.Issues = NewIssueAPI(c)
+	c.Milestones = NewMilestoneAPI(c)
+
+	progress, err := c.Milestones.GetProgress(context.Background(), "o", "r", 3, 2)
+	if err != nil {
+		t.Fatalf("GetProgress() error = %v", err)
+	}
+	if !progress.Overdue || progress.Total != 4 || progress.PercentComplete != 25 {
+		t.Fatalf("GetProgress() = %+v", progress)
+	}
+	if len(progress.OverdueItems) != 2 || !progress.OverdueMore {
+		t.Fatalf("OverdueItems = %+v, OverdueMore = %v, want 2 items and more", progress.OverdueItems, progress.OverdueMore)
+	}
+	first, second := progress.OverdueItems[0], progress.OverdueItems[1]
+	if first.Number != 10 || first.PullRequest || len(first.Assignees) != 1 || first.Assignees[0] != "alice" {
+		t.Fatalf("OverdueItems[0] = %+v", first)
+	}
+	if second.Number != 11 || !second.PullRequest {
+		t.Fatalf("OverdueItems[1] = %+v", second)
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrPolicyDenied 仓库访问策略不允许访问仓库
+var ErrPolicyDenied = errors.New("仓库访问策略拒绝")
+
+// RepoRules 表示一组仓库访问规则，模式为owner/repo格式的glob，如myorg/*；不含/的模式表示该所有者的全部仓库
+type RepoRules struct {
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"` // 为空时允许所有未被拒绝的仓库
+	Deny  []string `json:"deny,omitempty" yaml:"deny,omitempty"`   // 优先于Allow
+}
+
+// RepoPolicy 表示仓库访问策略，读取和写入分别配置规则。写操作需要同时满足读取和写入规则
+type RepoPolicy struct {
+	Read  RepoRules `json:"read" yaml:"read"`
+	Write RepoRules `json:"write" yaml:"write"`
+}
+
+// LoadRepoPolicy 从YAML或JSON文件加载仓库访问策略
+func LoadRepoPolicy(path string) (*RepoPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取仓库访问策略文件失败: %w", err)
+	}
+	return ParseRepoPolicy(data)
+}
+
+// ParseRepoPolicy 解析YAML或JSON格式的仓库访问策略并规范化其中的模式
+func ParseRepoPolicy(data []byte) (*RepoPolicy, error) {
+	var policy RepoPolicy
+	// JSON是YAML的子集，两种格式都用YAML解析
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	// 拼错的字段会导致规则被忽略，因此不允许未知字段
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: 解析仓库访问策略失败: %v", ErrValidation, err)
+	}
+
+	for _, list := range []*[]string{&policy.Read.Allow, &policy.Read.Deny, &policy.Write.Allow, &policy.Write.Deny} {
+		for i, pattern := range *list {
+			normalized, err := normalizeRepoPattern(pattern)
+			if err != nil {
+				return nil, err
+			}
+			(*list)[i] = normalized
+		}
+	}
+	return &policy, nil
+}
+
+// normalizeRepoPattern 将模式转为小写，不含/的模式补全为owner/*
+func normalizeRepoPattern(pattern string) (string, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	switch strings.Count(pattern, "/") {
+	case 0:
+		if pattern == "" {
+			return "", fmt.Errorf("%w: 仓库访问策略中不能有空模式", ErrValidation)
+		}
+		return pattern + "/*", nil
+	case 1:
+		if strings.HasPrefix(pattern, "/") || strings.HasSuffix(pattern, "/") {
+			break
+		}
+		return pattern, nil
+	}
+	return "", fmt.Errorf("%w: 仓库访问策略模式 %q 应为owner/repo格式", ErrValidation, pattern)
+}
+
+// Check 检查是否允许读取或写入仓库，policy为nil时不做限制。仓库名称不区分大小写
+func (p *RepoPolicy) Check(owner, repo string, write bool) error {
+	if p == nil {
+		return nil
+	}
+	name := strings.ToLower(owner + "/" + repo)
+
+	if reason := p.Read.check(name); reason != "" {
+		return fmt.Errorf("%w: 不允许读取仓库 %s/%s，%s", ErrPolicyDenied, owner, repo, reason)
+	}
+	if !write {
+		return nil
+	}
+	if reason := p.Write.check(name); reason != "" {
+		return fmt.Errorf("%w: 不允许写入仓库 %s/%s，%s", ErrPolicyDenied, owner, repo, reason)
+	}
+	return nil
+}
+
+// check 返回规则拒绝仓库的原因，允许时返回空字符串
+func (r RepoRules) check(name string) string {
+	for _, pattern := range r.Deny {
+		if MatchGlob(pattern, name) {
+			return fmt.Sprintf("匹配拒绝规则 %s", pattern)
+		}
+	}
+	if len(r.Allow) > 0 && !MatchAnyGlob(r.Allow, name) {
+		return "不在允许列表中"
+	}
+	return ""
+}
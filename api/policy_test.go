@@ -0,0 +1,149 @@
+package api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRepoPolicyCheck(t *testing.T) {
+	policy := &RepoPolicy{
+		Read: RepoRules{
+			Allow: []string{"myorg/*", "partner/public-*"},
+			Deny:  []string{"myorg/secret"},
+		},
+		Write: RepoRules{
+			Allow: []string{"myorg/app-*"},
+			Deny:  []string{"myorg/app-legacy"},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		owner string
+		repo  string
+		write bool
+		want  bool
+	}{
+		{"读取允许列表中的仓库", "myorg", "docs", false, true},
+		{"glob匹配前缀", "partner", "public-sdk", false, true},
+		{"不在读取允许列表中", "partner", "internal", false, false},
+		{"拒绝优先于允许", "myorg", "secret", false, false},
+		{"写入允许列表中的仓库", "myorg", "app-web", true, true},
+		{"写入拒绝优先于允许", "myorg", "app-legacy", true, false},
+		{"可读但不在写入允许列表中", "myorg", "docs", true, false},
+		{"写入同样要求可读", "partner", "public-sdk", true, false},
+		{"不区分大小写", "MyOrg", "App-Web", true, true},
+		{"*不跨越/", "myorg", "app-web/extra", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.owner, tt.repo, tt.write)
+			if got := err == nil; got != tt.want {
+				t.Fatalf("Check(%s, %s, %v) = %v, want allowed=%v", tt.owner, tt.repo, tt.write, err, tt.want)
+			}
+			if err != nil && !errors.Is(err, ErrPolicyDenied) {
+				t.Fatalf("错误应包装ErrPolicyDenied: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepoPolicyDefaultAction(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *RepoPolicy
+		write  bool
+		want   bool
+	}{
+		{"没有加载策略", nil, true, true},
+		{"空策略允许读取", &RepoPolicy{}, false, true},
+		{"空策略允许写入", &RepoPolicy{}, true, true},
+		{"只有拒绝规则时允许其他仓库", &RepoPolicy{Read: RepoRules{Deny: []string{"other/*"}}}, false, true},
+		{"只有写入允许列表时仍可读取", &RepoPolicy{Write: RepoRules{Allow: []string{"other/*"}}}, false, true},
+		{"只有写入允许列表时不可写入其他仓库", &RepoPolicy{Write: RepoRules{Allow: []string{"other/*"}}}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check("myorg", "app", tt.write)
+			if got := err == nil; got != tt.want {
+				t.Fatalf("Check = %v, want allowed=%v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRepoPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *RepoPolicy
+		wantErr bool
+	}{
+		{
+			name: "YAML",
+			data: "read:\n  allow: [MyOrg, partner/public-*]\n  deny: [myorg/secret]\nwrite:\n  allow: [myorg/app-*]\n",
+			want: &RepoPolicy{
+				Read:  RepoRules{Allow: []string{"myorg/*", "partner/public-*"}, Deny: []string{"myorg/secret"}},
+				Write: RepoRules{Allow: []string{"myorg/app-*"}},
+			},
+		},
+		{
+			name: "JSON",
+			data: `{"read": {"deny": ["other"]}, "write": {"allow": ["myorg/app"]}}`,
+			want: &RepoPolicy{
+				Read:  RepoRules{Deny: []string{"other/*"}},
+				Write: RepoRules{Allow: []string{"myorg/app"}},
+			},
+		},
+		{name: "空文件", data: "", want: &RepoPolicy{}},
+		{name: "未知字段", data: "reed:\n  allow: [myorg]\n", wantErr: true},
+		{name: "多级路径", data: "read:\n  deny: [a/b/c]\n", wantErr: true},
+		{name: "缺少仓库名", data: "read:\n  deny: [myorg/]\n", wantErr: true},
+		{name: "空模式", data: `{"write": {"deny": [" "]}}`, wantErr: true},
+		{name: "格式错误", data: "read: [", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRepoPolicy([]byte(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidation) {
+					t.Fatalf("ParseRepoPolicy() error = %v, want ErrValidation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRepoPolicy() error = %v", err)
+			}
+			if !equalRules(got.Read, tt.want.Read) || !equalRules(got.Write, tt.want.Write) {
+				t.Fatalf("ParseRepoPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRepoPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(path, []byte(`{"read": {"allow": ["myorg"]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadRepoPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadRepoPolicy() error = %v", err)
+	}
+	if err := policy.Check("other", "repo", false); err == nil {
+		t.Fatal("不在允许列表中的仓库应被拒绝")
+	}
+
+	if _, err := LoadRepoPolicy(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Fatal("文件不存在时应返回错误")
+	}
+}
+
+func equalRules(a, b RepoRules) bool {
+	return slices.Equal(a.Allow, b.Allow) && slices.Equal(a.Deny, b.Deny)
+}
@@ -1,8 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 )
 
 // PullRequest 表示Pull Request信息
@@ -23,8 +27,9 @@ type PullRequest struct {
 	Assignees       []User    `json:"assignees"`
 	RequestedReviewers []User `json:"requested_reviewers"`
 	Labels          []Label   `json:"labels"`
-	Base            PRRef     `json:"base"`
-	Head            PRRef     `json:"head"`
+	Milestone       *Milestone `json:"milestone"`
+	Base            PRBranch  `json:"base"`
+	Head            PRBranch  `json:"head"`
 	Merged          bool      `json:"merged"`
 	Mergeable       bool      `json:"mergeable"`
 	Rebaseable      bool      `json:"rebaseable"`
@@ -44,6 +49,68 @@ type PRBranch struct {
 	Repo  Repository `json:"repo"`
 }
 
+// Review 表示PR的代码审查
+type Review struct {
+	ID             int    `json:"id"`
+	User           User   `json:"user"`
+	Body           string `json:"body"`
+	State          string `json:"state"` // APPROVED、CHANGES_REQUESTED、COMMENTED、PENDING或DISMISSED
+	CommitID       string `json:"commit_id"`
+	HTMLURL        string `json:"html_url"`
+	PullRequestURL string `json:"pull_request_url"`
+	SubmittedAt    string `json:"submitted_at"`
+}
+
+// ReviewComment 表示PR代码审查中的行内评论
+type ReviewComment struct {
+	ID                  int    `json:"id"`
+	PullRequestReviewID int    `json:"pull_request_review_id"`
+	InReplyToID         int    `json:"in_reply_to_id,omitempty"`
+	Path                string `json:"path"`
+	Position            int    `json:"position"`
+	OriginalPosition    int    `json:"original_position"`
+	Line                int    `json:"line,omitempty"`
+	Side                string `json:"side,omitempty"`
+	CommitID            string `json:"commit_id"`
+	OriginalCommitID    string `json:"original_commit_id"`
+	DiffHunk            string `json:"diff_hunk"`
+	Body                string `json:"body"`
+	User                User   `json:"user"`
+	HTMLURL             string `json:"html_url"`
+	CreatedAt           string `json:"created_at"`
+	UpdatedAt           string `json:"updated_at"`
+	Resolved            *bool  `json:"resolved,omitempty"`
+}
+
+// DraftReviewComment 表示提交代码审查时附带的行内评论，位置用Line和Side或Position指定
+type DraftReviewComment struct {
+	Path     string `json:"path"`
+	Position int    `json:"position,omitempty"` // 在该文件diff中的行位置
+	Line     int    `json:"line,omitempty"`     // 文件中的行号，提交前会换算为Position
+	Side     string `json:"side,omitempty"`     // RIGHT表示新文件（默认），LEFT表示原文件
+	Body     string `json:"body"`
+}
+
+// ReviewThread 表示一个行内评论及其回复组成的讨论
+type ReviewThread struct {
+	ID       int             `json:"id"` // 第一条评论的ID，回复和解决讨论时使用
+	Path     string          `json:"path"`
+	Line     int             `json:"line,omitempty"`
+	Position int             `json:"position"`
+	Resolved *bool           `json:"resolved,omitempty"` // 平台未返回时为空
+	Comments []ReviewComment `json:"comments"`
+}
+
+// PRFile 表示PR修改的文件，字段与提交中修改的文件相同
+type PRFile = CommitFile
+
+// MergeResult 表示合并PR或分支的结果
+type MergeResult struct {
+	Merged  bool   `json:"merged"`
+	SHA     string `json:"sha"`
+	Message string `json:"message"`
+}
+
 // CreatePullRequestOptions 表示创建PR的参数
 type CreatePullRequestOptions struct {
 	Title               string   `json:"title"`
@@ -52,14 +119,16 @@ type CreatePullRequestOptions struct {
 	Base                string   `json:"base"`
 	Draft               bool     `json:"draft,omitempty"`
 	MaintainerCanModify bool     `json:"maintainer_can_modify,omitempty"`
+	Milestone           int      `json:"milestone,omitempty"`
 }
 
 // UpdatePullRequestOptions 表示更新PR的参数
 type UpdatePullRequestOptions struct {
 	Title   string `json:"title,omitempty"`
 	Body    string `json:"body,omitempty"`
-	State   string `json:"state,omitempty"`
-	Base    string `json:"base,omitempty"`
+	State     string `json:"state,omitempty"`
+	Base      string `json:"base,omitempty"`
+	Milestone *int   `json:"milestone,omitempty"` // 为nil时不修改，指向0时移除里程碑
 }
 
 // MergeOptions 表示合并PR的参数
@@ -72,25 +141,27 @@ type MergeOptions struct {
 }
 
 // ListPullRequests 列出仓库的Pull Requests
-func (api *PullRequestAPI) ListPullRequests(owner, repo string) ([]PullRequest, error) {
-	path := fmt.Sprintf("/repos/%s/%s/pulls", owner, repo)
-	resp, err := api.Client.GET(path, nil)
-	if err != nil {
+func (api *PullRequestAPI) ListPullRequests(ctx context.Context, owner, repo string, filter *ListPullRequestsOptions, opts *ListOptions) ([]PullRequest, error) {
+	if err := filter.validate(); err != nil {
 		return nil, err
 	}
 	
-	var pulls []PullRequest
-	if err := json.Unmarshal(resp, &pulls); err != nil {
-		return nil, fmt.Errorf("解析Pull Requests列表失败: %w", err)
+	path := fmt.Sprintf("/repos/%s/%s/pulls", owner, repo)
+	pulls, err := paginateFiltered(ctx, api.Client, path, filter.values(), opts, decodeList[PullRequest], func(pr PullRequest) bool {
+		return filter.match(pullRequestListItem(pr))
+	})
+	if err != nil {
+		return nil, err
 	}
 	
+	sortItems(pulls, filter, pullRequestListItem)
 	return pulls, nil
 }
 
 // GetPullRequest 获取特定Pull Request的详细信息
-func (api *PullRequestAPI) GetPullRequest(owner, repo string, pullNumber int) (*PullRequest, error) {
+func (api *PullRequestAPI) GetPullRequest(ctx context.Context, owner, repo string, pullNumber int) (*PullRequest, error) {
 	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", owner, repo, pullNumber)
-	resp, err := api.Client.GET(path, nil)
+	resp, err := api.Client.GET(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -104,16 +175,9 @@ func (api *PullRequestAPI) GetPullRequest(owner, repo string, pullNumber int) (*
 }
 
 // CreatePullRequest 创建新Pull Request
-func (api *PullRequestAPI) CreatePullRequest(owner, repo, title, head, base, body string) (*PullRequest, error) {
+func (api *PullRequestAPI) CreatePullRequest(ctx context.Context, owner, repo string, options CreatePullRequestOptions) (*PullRequest, error) {
 	path := fmt.Sprintf("/repos/%s/%s/pulls", owner, repo)
-	options := CreatePullRequestOptions{
-		Title: title,
-		Head:  head,
-		Base:  base,
-		Body:  body,
-	}
-	
-	resp, err := api.Client.POST(path, nil, options)
+	resp, err := api.Client.POST(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
@@ -127,9 +191,9 @@ func (api *PullRequestAPI) CreatePullRequest(owner, repo, title, head, base, bod
 }
 
 // UpdatePullRequest 更新Pull Request
-func (api *PullRequestAPI) UpdatePullRequest(owner, repo string, pullNumber int, options UpdatePullRequestOptions) (*PullRequest, error) {
+func (api *PullRequestAPI) UpdatePullRequest(ctx context.Context, owner, repo string, pullNumber int, options UpdatePullRequestOptions) (*PullRequest, error) {
 	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", owner, repo, pullNumber)
-	resp, err := api.Client.PATCH(path, nil, options)
+	resp, err := api.Client.PATCH(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
@@ -143,50 +207,62 @@ func (api *PullRequestAPI) UpdatePullRequest(owner, repo string, pullNumber int,
 }
 
 // ClosePullRequest 关闭Pull Request
-func (api *PullRequestAPI) ClosePullRequest(owner, repo string, pullNumber int) (*PullRequest, error) {
-	return api.UpdatePullRequest(owner, repo, pullNumber, UpdatePullRequestOptions{
+func (api *PullRequestAPI) ClosePullRequest(ctx context.Context, owner, repo string, pullNumber int) (*PullRequest, error) {
+	return api.UpdatePullRequest(ctx, owner, repo, pullNumber, UpdatePullRequestOptions{
 		State: "closed",
 	})
 }
 
 // MergePullRequest 合并Pull Request
-func (api *PullRequestAPI) MergePullRequest(owner, repo string, pullNumber int, options MergeOptions) (bool, error) {
+func (api *PullRequestAPI) MergePullRequest(ctx context.Context, owner, repo string, pullNumber int, options MergeOptions) (*MergeResult, error) {
 	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/merge", owner, repo, pullNumber)
-	resp, err := api.Client.PUT(path, nil, options)
+	resp, err := api.Client.PUT(ctx, path, nil, options)
 	if err != nil {
-		return false, err
-	}
-	
-	var result struct {
-		Merged  bool   `json:"merged"`
-		Message string `json:"message"`
+		return nil, err
 	}
 	
+	var result MergeResult
 	if err := json.Unmarshal(resp, &result); err != nil {
-		return false, fmt.Errorf("解析合并结果失败: %w", err)
+		return nil, fmt.Errorf("解析合并结果失败: %w", err)
 	}
 	
-	return result.Merged, nil
+	return &result, nil
 }
 
 // ListPRReviews 列出PR的代码审查
-func (api *PullRequestAPI) ListPRReviews(owner, repo string, pullNumber int) ([]interface{}, error) {
+func (api *PullRequestAPI) ListPRReviews(ctx context.Context, owner, repo string, pullNumber int, opts *ListOptions) ([]Review, error) {
 	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", owner, repo, pullNumber)
-	resp, err := api.Client.GET(path, nil)
+	reviews, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Review])
 	if err != nil {
 		return nil, err
 	}
 	
-	var reviews []interface{}
-	if err := json.Unmarshal(resp, &reviews); err != nil {
-		return nil, fmt.Errorf("解析代码审查列表失败: %w", err)
-	}
-	
 	return reviews, nil
 }
 
-// CreatePRReview 创建PR代码审查
-func (api *PullRequestAPI) CreatePRReview(owner, repo string, pullNumber int, body, event string, comments []interface{}) (interface{}, error) {
+// CreatePRReview 创建PR代码审查，event为APPROVE、REQUEST_CHANGES或COMMENT
+// 带有行内评论时先获取PR的diff，检查每条评论的位置并把行号换算为position，有任何无效位置时不会提交
+func (api *PullRequestAPI) CreatePRReview(ctx context.Context, owner, repo string, pullNumber int, body, event string, comments []DraftReviewComment) (*Review, error) {
+	switch event {
+	case "APPROVE":
+	case "REQUEST_CHANGES", "COMMENT":
+		if body == "" && len(comments) == 0 {
+			return nil, fmt.Errorf("%w: %s需要提供审查意见或行内评论", ErrValidation, event)
+		}
+	default:
+		return nil, fmt.Errorf("%w: event只能是APPROVE、REQUEST_CHANGES或COMMENT", ErrValidation)
+	}
+	
+	if len(comments) > 0 {
+		diffs, err := api.GetDiff(WithNoCache(ctx), owner, repo, pullNumber)
+		if err != nil {
+			return nil, fmt.Errorf("获取PR的diff失败: %w", err)
+		}
+		if comments, err = ValidateReviewComments(diffs, comments); err != nil {
+			return nil, err
+		}
+	}
+	
 	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", owner, repo, pullNumber)
 	options := map[string]interface{}{
 		"body":     body,
@@ -194,38 +270,125 @@ func (api *PullRequestAPI) CreatePRReview(owner, repo string, pullNumber int, bo
 		"comments": comments,
 	}
 	
-	resp, err := api.Client.POST(path, nil, options)
+	resp, err := api.Client.POST(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
 	
-	var review interface{}
+	var review Review
 	if err := json.Unmarshal(resp, &review); err != nil {
 		return nil, fmt.Errorf("解析新代码审查信息失败: %w", err)
 	}
 	
-	return review, nil
+	return &review, nil
 }
 
 // ListPRComments 列出PR的评论
-func (api *PullRequestAPI) ListPRComments(owner, repo string, pullNumber int) ([]Comment, error) {
+func (api *PullRequestAPI) ListPRComments(ctx context.Context, owner, repo string, pullNumber int, opts *ListOptions) ([]Comment, error) {
 	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/comments", owner, repo, pullNumber)
-	resp, err := api.Client.GET(path, nil)
+	comments, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Comment])
 	if err != nil {
 		return nil, err
 	}
 	
-	var comments []Comment
-	if err := json.Unmarshal(resp, &comments); err != nil {
-		return nil, fmt.Errorf("解析PR评论列表失败: %w", err)
+	return comments, nil
+}
+
+// ListReviewComments 列出PR的行内评论
+func (api *PullRequestAPI) ListReviewComments(ctx context.Context, owner, repo string, pullNumber int, opts *ListOptions) ([]ReviewComment, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/comments", owner, repo, pullNumber)
+	comments, err := paginate(ctx, api.Client, path, nil, opts, decodeList[ReviewComment])
+	if err != nil {
+		return nil, err
 	}
 	
 	return comments, nil
 }
 
+// ListReviewThreads 将PR的行内评论按回复关系整理为讨论
+func (api *PullRequestAPI) ListReviewThreads(ctx context.Context, owner, repo string, pullNumber int) ([]ReviewThread, error) {
+	comments, err := api.ListReviewComments(ctx, owner, repo, pullNumber, &ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	
+	threads := []ReviewThread{}
+	index := map[int]int{} // 评论ID到所在讨论的下标
+	for _, comment := range comments {
+		if i, ok := index[comment.InReplyToID]; ok && comment.InReplyToID != 0 {
+			threads[i].Comments = append(threads[i].Comments, comment)
+			if comment.Resolved != nil {
+				threads[i].Resolved = comment.Resolved
+			}
+			index[comment.ID] = i
+			continue
+		}
+		index[comment.ID] = len(threads)
+		threads = append(threads, ReviewThread{
+			ID:       comment.ID,
+			Path:     comment.Path,
+			Line:     comment.Line,
+			Position: comment.Position,
+			Resolved: comment.Resolved,
+			Comments: []ReviewComment{comment},
+		})
+	}
+	
+	return threads, nil
+}
+
+// ReplyToReviewComment 回复行内评论所在的讨论
+func (api *PullRequestAPI) ReplyToReviewComment(ctx context.Context, owner, repo string, pullNumber, commentID int, body string) (*ReviewComment, error) {
+	if body == "" {
+		return nil, fmt.Errorf("%w: 回复内容不能为空", ErrValidation)
+	}
+	
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/comments", owner, repo, pullNumber)
+	options := map[string]interface{}{
+		"body":        body,
+		"in_reply_to": commentID,
+	}
+	
+	resp, err := api.Client.POST(ctx, path, nil, options)
+	if err != nil {
+		return nil, err
+	}
+	
+	var comment ReviewComment
+	if err := json.Unmarshal(resp, &comment); err != nil {
+		return nil, fmt.Errorf("解析回复信息失败: %w", err)
+	}
+	
+	return &comment, nil
+}
+
+// ResolveReviewThread 将行内评论所在的讨论标记为已解决或未解决，commentID为讨论中第一条评论的ID
+func (api *PullRequestAPI) ResolveReviewThread(ctx context.Context, owner, repo string, commentID int, resolved bool) (*ReviewComment, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/comments/%d", owner, repo, commentID)
+	options := map[string]interface{}{
+		"resolved": resolved,
+	}
+	
+	resp, err := api.Client.PATCH(ctx, path, nil, options)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.Code == http.StatusMethodNotAllowed || apiErr.Code == http.StatusNotImplemented) {
+			return nil, fmt.Errorf("平台不支持解决讨论: %w", err)
+		}
+		return nil, err
+	}
+	
+	var comment ReviewComment
+	if err := json.Unmarshal(resp, &comment); err != nil {
+		return nil, fmt.Errorf("解析评论信息失败: %w", err)
+	}
+	
+	return &comment, nil
+}
+
 // IsPRMergeable 检查PR是否可合并
-func (api *PullRequestAPI) IsPRMergeable(owner, repo string, pullNumber int) (bool, error) {
-	pr, err := api.GetPullRequest(owner, repo, pullNumber)
+func (api *PullRequestAPI) IsPRMergeable(ctx context.Context, owner, repo string, pullNumber int) (bool, error) {
+	pr, err := api.GetPullRequest(ctx, owner, repo, pullNumber)
 	if err != nil {
 		return false, err
 	}
@@ -234,33 +397,46 @@ func (api *PullRequestAPI) IsPRMergeable(owner, repo string, pullNumber int) (bo
 }
 
 // ListFiles 列出PR包含的文件
-func (api *PullRequestAPI) ListFiles(owner, repo string, pullNumber int) ([]interface{}, error) {
+func (api *PullRequestAPI) ListFiles(ctx context.Context, owner, repo string, pullNumber int, opts *ListOptions) ([]PRFile, error) {
 	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/files", owner, repo, pullNumber)
-	resp, err := api.Client.GET(path, nil)
+	files, err := paginate(ctx, api.Client, path, nil, opts, decodeList[PRFile])
 	if err != nil {
 		return nil, err
 	}
 	
-	var files []interface{}
-	if err := json.Unmarshal(resp, &files); err != nil {
-		return nil, fmt.Errorf("解析PR文件列表失败: %w", err)
-	}
-	
 	return files, nil
 }
 
 // ListCommits 列出PR包含的提交
-func (api *PullRequestAPI) ListCommits(owner, repo string, pullNumber int) ([]interface{}, error) {
+func (api *PullRequestAPI) ListCommits(ctx context.Context, owner, repo string, pullNumber int, opts *ListOptions) ([]Commit, error) {
 	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/commits", owner, repo, pullNumber)
-	resp, err := api.Client.GET(path, nil)
+	commits, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Commit])
+	if err != nil {
+		return nil, err
+	}
+	
+	return commits, nil
+}
+
+// AddLabelsToPullRequest 为PR添加标签
+func (api *PullRequestAPI) AddLabelsToPullRequest(ctx context.Context, owner, repo string, pullNumber int, labels []string) ([]Label, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/labels", owner, repo, pullNumber)
+	resp, err := api.Client.POST(ctx, path, nil, labels)
 	if err != nil {
 		return nil, err
 	}
 	
-	var commits []interface{}
-	if err := json.Unmarshal(resp, &commits); err != nil {
-		return nil, fmt.Errorf("解析PR提交列表失败: %w", err)
+	var resultLabels []Label
+	if err := json.Unmarshal(resp, &resultLabels); err != nil {
+		return nil, fmt.Errorf("解析添加标签结果失败: %w", err)
 	}
 	
-	return commits, nil
-} 
\ No newline at end of file
+	return resultLabels, nil
+}
+
+// RemoveLabelFromPullRequest 从PR移除标签
+func (api *PullRequestAPI) RemoveLabelFromPullRequest(ctx context.Context, owner, repo string, pullNumber int, label string) error {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/labels/%s", owner, repo, pullNumber, url.PathEscape(label))
+	_, err := api.Client.DELETE(ctx, path, nil)
+	return err
+}
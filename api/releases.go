@@ -0,0 +1,346 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Release 表示发布版本
+type Release struct {
+	ID              int            `json:"id"`
+	TagName         string         `json:"tag_name"`
+	TargetCommitish string         `json:"target_commitish"`
+	Name            string         `json:"name"`
+	Body            string         `json:"body"`
+	Draft           bool           `json:"draft"`
+	Prerelease      bool           `json:"prerelease"`
+	Author          User           `json:"author"`
+	URL             string         `json:"url"`
+	HTMLURL         string         `json:"html_url"`
+	TarballURL      string         `json:"tarball_url"`
+	ZipballURL      string         `json:"zipball_url"`
+	CreatedAt       string         `json:"created_at"`
+	PublishedAt     string         `json:"published_at"`
+	Assets          []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset 表示发布版本的附件
+type ReleaseAsset struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	Label              string `json:"label"`
+	ContentType        string `json:"content_type"`
+	Size               int64  `json:"size"`
+	DownloadCount      int    `json:"download_count"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Uploader           *User  `json:"uploader,omitempty"`
+	CreatedAt          string `json:"created_at"`
+	UpdatedAt          string `json:"updated_at"`
+}
+
+// ReleaseOptions 表示创建或更新发布版本的参数，更新时零值字段保持不变
+type ReleaseOptions struct {
+	TagName         string `json:"tag_name,omitempty"`
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Body            string `json:"body,omitempty"`
+	Draft           *bool  `json:"draft,omitempty"`
+	Prerelease      *bool  `json:"prerelease,omitempty"`
+}
+
+// Tag 表示标签
+type Tag struct {
+	Name       string       `json:"name"`
+	Message    string       `json:"message,omitempty"`
+	Commit     CommitParent `json:"commit"`
+	ZipballURL string       `json:"zipball_url"`
+	TarballURL string       `json:"tarball_url"`
+}
+
+// CreateTagOptions 表示创建标签的参数
+type CreateTagOptions struct {
+	TagName    string `json:"tag_name"`
+	Refs       string `json:"refs"`                  // 标签指向的分支名或提交SHA
+	TagMessage string `json:"tag_message,omitempty"` // 不为空时创建附注标签
+}
+
+// ListReleases 列出仓库的发布版本
+func (api *ReleaseAPI) ListReleases(ctx context.Context, owner, repo string, opts *ListOptions) ([]Release, error) {
+	path := fmt.Sprintf("/repos/%s/%s/releases", owner, repo)
+	releases, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Release])
+	if err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// GetRelease 根据ID获取发布版本
+func (api *ReleaseAPI) GetRelease(ctx context.Context, owner, repo string, id int) (*Release, error) {
+	return api.getRelease(ctx, fmt.Sprintf("/repos/%s/%s/releases/%d", owner, repo, id))
+}
+
+// GetReleaseByTag 根据标签名获取发布版本
+func (api *ReleaseAPI) GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	return api.getRelease(ctx, fmt.Sprintf("/repos/%s/%s/releases/tags/%s", owner, repo, url.PathEscape(tag)))
+}
+
+// GetLatestRelease 获取最新的正式发布版本，不包括草稿和预发布版本
+func (api *ReleaseAPI) GetLatestRelease(ctx context.Context, owner, repo string) (*Release, error) {
+	return api.getRelease(ctx, fmt.Sprintf("/repos/%s/%s/releases/latest", owner, repo))
+}
+
+// getRelease 获取并解析单个发布版本
+func (api *ReleaseAPI) getRelease(ctx context.Context, path string) (*Release, error) {
+	resp, err := api.Client.GET(ctx, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var release Release
+	if err := json.Unmarshal(resp, &release); err != nil {
+		return nil, fmt.Errorf("解析发布版本详情失败: %w", err)
+	}
+
+	return &release, nil
+}
+
+// CreateRelease 创建发布版本，标签不存在时基于TargetCommitish创建
+func (api *ReleaseAPI) CreateRelease(ctx context.Context, owner, repo string, options ReleaseOptions) (*Release, error) {
+	if options.TagName == "" {
+		return nil, fmt.Errorf("%w: 标签名不能为空", ErrValidation)
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/releases", owner, repo)
+	resp, err := api.Client.POST(ctx, path, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var release Release
+	if err := json.Unmarshal(resp, &release); err != nil {
+		return nil, fmt.Errorf("解析新发布版本信息失败: %w", err)
+	}
+
+	return &release, nil
+}
+
+// UpdateRelease 更新发布版本
+func (api *ReleaseAPI) UpdateRelease(ctx context.Context, owner, repo string, id int, options ReleaseOptions) (*Release, error) {
+	path := fmt.Sprintf("/repos/%s/%s/releases/%d", owner, repo, id)
+	resp, err := api.Client.PATCH(ctx, path, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var release Release
+	if err := json.Unmarshal(resp, &release); err != nil {
+		return nil, fmt.Errorf("解析更新后的发布版本信息失败: %w", err)
+	}
+
+	return &release, nil
+}
+
+// DeleteRelease 删除发布版本，对应的标签不会被删除
+func (api *ReleaseAPI) DeleteRelease(ctx context.Context, owner, repo string, id int) error {
+	path := fmt.Sprintf("/repos/%s/%s/releases/%d", owner, repo, id)
+	_, err := api.Client.DELETE(ctx, path, nil)
+	return err
+}
+
+// ListReleaseAssets 列出发布版本的附件
+func (api *ReleaseAPI) ListReleaseAssets(ctx context.Context, owner, repo string, id int, opts *ListOptions) ([]ReleaseAsset, error) {
+	path := fmt.Sprintf("/repos/%s/%s/releases/%d/assets", owner, repo, id)
+	assets, err := paginate(ctx, api.Client, path, nil, opts, decodeList[ReleaseAsset])
+	if err != nil {
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+// UploadReleaseAsset 将本地文件上传为发布版本的附件，name为空时使用文件名
+// 文件以流的方式上传，不会整体读入内存；maxBytes大于0时，超过该大小的文件返回ErrTooLarge
+func (api *ReleaseAPI) UploadReleaseAsset(ctx context.Context, owner, repo string, id int, filePath, name string, maxBytes int64) (*ReleaseAsset, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("读取文件信息失败: %w", err)
+	}
+	// FIFO、设备和/proc下的文件大小没有意义，无法事先判断是否超过上限
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: %s 不是普通文件", ErrValidation, filePath)
+	}
+	if maxBytes > 0 && info.Size() > maxBytes {
+		return nil, fmt.Errorf("%w: 文件大小为%d字节，上限为%d字节", ErrTooLarge, info.Size(), maxBytes)
+	}
+	if name == "" {
+		name = filepath.Base(filePath)
+	}
+
+	return api.uploadAsset(ctx, owner, repo, id, name, file, maxBytes)
+}
+
+// UploadReleaseAssetContent 将内存中的内容上传为发布版本的附件，供无法提供服务器本地文件的远程客户端使用；
+// maxBytes大于0时，超过该大小的内容返回ErrTooLarge
+func (api *ReleaseAPI) UploadReleaseAssetContent(ctx context.Context, owner, repo string, id int, name string, content []byte, maxBytes int64) (*ReleaseAsset, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: 上传内容时必须提供附件名称", ErrValidation)
+	}
+	if maxBytes > 0 && int64(len(content)) > maxBytes {
+		return nil, fmt.Errorf("%w: 内容大小为%d字节，上限为%d字节", ErrTooLarge, len(content), maxBytes)
+	}
+
+	return api.uploadAsset(ctx, owner, repo, id, name, bytes.NewReader(content), maxBytes)
+}
+
+// uploadAsset 以multipart请求体上传附件内容；maxBytes大于0时，读到的内容超过该大小会中止上传并返回ErrTooLarge，
+// 即使文件在检查大小之后继续增长也不会超过上限
+func (api *ReleaseAPI) uploadAsset(ctx context.Context, owner, repo string, id int, name string, content io.Reader, maxBytes int64) (*ReleaseAsset, error) {
+	if maxBytes > 0 {
+		// 多读一个字节用于判断是否超过上限
+		content = io.LimitReader(content, maxBytes+1)
+	}
+
+	// 通过管道边读内容边写multipart请求体
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	copied := make(chan error, 1)
+	go func() {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": name}))
+		header.Set("Content-Type", assetContentType(name))
+		part, err := form.CreatePart(header)
+		if err == nil {
+			var n int64
+			n, err = io.Copy(part, content)
+			if err == nil && maxBytes > 0 && n > maxBytes {
+				err = fmt.Errorf("%w: 文件超过%d字节", ErrTooLarge, maxBytes)
+			}
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		pw.CloseWithError(err)
+		copied <- err
+	}()
+
+	path := fmt.Sprintf("/repos/%s/%s/releases/%d/assets", owner, repo, id)
+	values := url.Values{}
+	values.Set("name", name)
+	resp, err := api.Client.Upload(ctx, path, values, form.FormDataContentType(), pr)
+	pr.Close()
+	if copyErr := <-copied; errors.Is(copyErr, ErrTooLarge) {
+		return nil, copyErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var asset ReleaseAsset
+	if err := json.Unmarshal(resp, &asset); err != nil {
+		return nil, fmt.Errorf("解析附件信息失败: %w", err)
+	}
+
+	return &asset, nil
+}
+
+// DownloadReleaseAsset 下载附件并保存到本地路径，返回写入的字节数
+// 先写入同目录下的临时文件，完成后再重命名，失败时不会留下不完整的文件
+func (api *ReleaseAPI) DownloadReleaseAsset(ctx context.Context, asset *ReleaseAsset, destPath string, maxBytes int64) (int64, error) {
+	if asset.BrowserDownloadURL == "" {
+		return 0, fmt.Errorf("附件 %s 没有下载地址", asset.Name)
+	}
+	if maxBytes > 0 && asset.Size > maxBytes {
+		return 0, fmt.Errorf("%w: 附件大小为%d字节，上限为%d字节", ErrTooLarge, asset.Size, maxBytes)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), "."+filepath.Base(destPath)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := api.Client.Download(ctx, asset.BrowserDownloadURL, tmp, maxBytes)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("写入文件失败: %w", closeErr)
+	}
+	if err != nil {
+		return n, err
+	}
+
+	if err := os.Rename(tmp.Name(), destPath); err != nil {
+		return n, fmt.Errorf("保存文件失败: %w", err)
+	}
+	return n, nil
+}
+
+// DownloadReleaseAssetContent 下载附件并返回其内容，供无法读取服务器本地文件的远程客户端使用
+func (api *ReleaseAPI) DownloadReleaseAssetContent(ctx context.Context, asset *ReleaseAsset, maxBytes int64) ([]byte, error) {
+	if asset.BrowserDownloadURL == "" {
+		return nil, fmt.Errorf("附件 %s 没有下载地址", asset.Name)
+	}
+	if maxBytes > 0 && asset.Size > maxBytes {
+		return nil, fmt.Errorf("%w: 附件大小为%d字节，上限为%d字节", ErrTooLarge, asset.Size, maxBytes)
+	}
+
+	var buf bytes.Buffer
+	if _, err := api.Client.Download(ctx, asset.BrowserDownloadURL, &buf, maxBytes); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ListTags 列出仓库的标签
+func (api *ReleaseAPI) ListTags(ctx context.Context, owner, repo string, opts *ListOptions) ([]Tag, error) {
+	path := fmt.Sprintf("/repos/%s/%s/tags", owner, repo)
+	tags, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Tag])
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// CreateTag 创建标签
+func (api *ReleaseAPI) CreateTag(ctx context.Context, owner, repo string, options CreateTagOptions) (*Tag, error) {
+	if options.TagName == "" || options.Refs == "" {
+		return nil, fmt.Errorf("%w: 标签名和指向的引用不能为空", ErrValidation)
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/tags", owner, repo)
+	resp, err := api.Client.POST(ctx, path, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var tag Tag
+	if err := json.Unmarshal(resp, &tag); err != nil {
+		return nil, fmt.Errorf("解析新标签信息失败: %w", err)
+	}
+
+	return &tag, nil
+}
+
+// assetContentType 根据文件扩展名推断附件的MIME类型
+func assetContentType(name string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -42,25 +43,20 @@ type CreateRepoOptions struct {
 }
 
 // ListUserRepos 列出当前用户的仓库
-func (api *RepositoryAPI) ListUserRepos() ([]Repository, error) {
+func (api *RepositoryAPI) ListUserRepos(ctx context.Context, opts *ListOptions) ([]Repository, error) {
 	path := "/user/repos"
-	resp, err := api.Client.GET(path, nil)
+	repos, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Repository])
 	if err != nil {
 		return nil, err
 	}
 	
-	var repos []Repository
-	if err := json.Unmarshal(resp, &repos); err != nil {
-		return nil, fmt.Errorf("解析仓库列表失败: %w", err)
-	}
-	
 	return repos, nil
 }
 
 // GetRepo 获取特定仓库的详细信息
-func (api *RepositoryAPI) GetRepo(owner, repo string) (*Repository, error) {
+func (api *RepositoryAPI) GetRepo(ctx context.Context, owner, repo string) (*Repository, error) {
 	path := fmt.Sprintf("/repos/%s/%s", owner, repo)
-	resp, err := api.Client.GET(path, nil)
+	resp, err := api.Client.GET(ctx, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +69,23 @@ func (api *RepositoryAPI) GetRepo(owner, repo string) (*Repository, error) {
 	return &repository, nil
 }
 
+// GetCurrentUser 获取当前令牌所属的用户
+func (api *RepositoryAPI) GetCurrentUser(ctx context.Context) (*User, error) {
+	resp, err := api.Client.GET(ctx, "/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	
+	var user User
+	if err := json.Unmarshal(resp, &user); err != nil {
+		return nil, fmt.Errorf("解析用户信息失败: %w", err)
+	}
+	
+	return &user, nil
+}
+
 // CreateRepo 创建新仓库
-func (api *RepositoryAPI) CreateRepo(name, description string, private bool) (*Repository, error) {
+func (api *RepositoryAPI) CreateRepo(ctx context.Context, name, description string, private bool) (*Repository, error) {
 	options := CreateRepoOptions{
 		Name:        name,
 		Description: description,
@@ -82,7 +93,7 @@ func (api *RepositoryAPI) CreateRepo(name, description string, private bool) (*R
 		AutoInit:    true,
 	}
 	
-	resp, err := api.Client.POST("/user/repos", nil, options)
+	resp, err := api.Client.POST(ctx, "/user/repos", nil, options)
 	if err != nil {
 		return nil, err
 	}
@@ -96,48 +107,38 @@ func (api *RepositoryAPI) CreateRepo(name, description string, private bool) (*R
 }
 
 // ListReposByOrg 列出组织的仓库
-func (api *RepositoryAPI) ListReposByOrg(org string) ([]Repository, error) {
+func (api *RepositoryAPI) ListReposByOrg(ctx context.Context, org string, opts *ListOptions) ([]Repository, error) {
 	path := fmt.Sprintf("/orgs/%s/repos", org)
-	resp, err := api.Client.GET(path, nil)
+	repos, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Repository])
 	if err != nil {
 		return nil, err
 	}
 	
-	var repos []Repository
-	if err := json.Unmarshal(resp, &repos); err != nil {
-		return nil, fmt.Errorf("解析组织仓库列表失败: %w", err)
-	}
-	
 	return repos, nil
 }
 
 // ListReposByUser 列出用户的仓库
-func (api *RepositoryAPI) ListReposByUser(username string) ([]Repository, error) {
+func (api *RepositoryAPI) ListReposByUser(ctx context.Context, username string, opts *ListOptions) ([]Repository, error) {
 	path := fmt.Sprintf("/users/%s/repos", username)
-	resp, err := api.Client.GET(path, nil)
+	repos, err := paginate(ctx, api.Client, path, nil, opts, decodeList[Repository])
 	if err != nil {
 		return nil, err
 	}
 	
-	var repos []Repository
-	if err := json.Unmarshal(resp, &repos); err != nil {
-		return nil, fmt.Errorf("解析用户仓库列表失败: %w", err)
-	}
-	
 	return repos, nil
 }
 
 // DeleteRepo 删除仓库
-func (api *RepositoryAPI) DeleteRepo(owner, repo string) error {
+func (api *RepositoryAPI) DeleteRepo(ctx context.Context, owner, repo string) error {
 	path := fmt.Sprintf("/repos/%s/%s", owner, repo)
-	_, err := api.Client.DELETE(path, nil)
+	_, err := api.Client.DELETE(ctx, path, nil)
 	return err
 }
 
 // UpdateRepo 更新仓库信息
-func (api *RepositoryAPI) UpdateRepo(owner, repo string, options map[string]interface{}) (*Repository, error) {
+func (api *RepositoryAPI) UpdateRepo(ctx context.Context, owner, repo string, options map[string]interface{}) (*Repository, error) {
 	path := fmt.Sprintf("/repos/%s/%s", owner, repo)
-	resp, err := api.Client.PATCH(path, nil, options)
+	resp, err := api.Client.PATCH(ctx, path, nil, options)
 	if err != nil {
 		return nil, err
 	}
@@ -151,13 +152,13 @@ func (api *RepositoryAPI) UpdateRepo(owner, repo string, options map[string]inte
 }
 
 // TransferRepo 转移仓库所有权
-func (api *RepositoryAPI) TransferRepo(owner, repo, newOwner string) (*Repository, error) {
+func (api *RepositoryAPI) TransferRepo(ctx context.Context, owner, repo, newOwner string) (*Repository, error) {
 	path := fmt.Sprintf("/repos/%s/%s/transfer", owner, repo)
 	body := map[string]string{
 		"new_owner": newOwner,
 	}
 	
-	resp, err := api.Client.POST(path, nil, body)
+	resp, err := api.Client.POST(ctx, path, nil, body)
 	if err != nil {
 		return nil, err
 	}
@@ -171,39 +172,34 @@ func (api *RepositoryAPI) TransferRepo(owner, repo, newOwner string) (*Repositor
 }
 
 // ListStargazers 列出仓库的星标用户
-func (api *RepositoryAPI) ListStargazers(owner, repo string) ([]User, error) {
+func (api *RepositoryAPI) ListStargazers(ctx context.Context, owner, repo string, opts *ListOptions) ([]User, error) {
 	path := fmt.Sprintf("/repos/%s/%s/stargazers", owner, repo)
-	resp, err := api.Client.GET(path, nil)
+	users, err := paginate(ctx, api.Client, path, nil, opts, decodeList[User])
 	if err != nil {
 		return nil, err
 	}
 	
-	var users []User
-	if err := json.Unmarshal(resp, &users); err != nil {
-		return nil, fmt.Errorf("解析星标用户列表失败: %w", err)
-	}
-	
 	return users, nil
 }
 
 // StarRepo 为仓库添加星标
-func (api *RepositoryAPI) StarRepo(owner, repo string) error {
+func (api *RepositoryAPI) StarRepo(ctx context.Context, owner, repo string) error {
 	path := fmt.Sprintf("/user/starred/%s/%s", owner, repo)
-	_, err := api.Client.PUT(path, nil, nil)
+	_, err := api.Client.PUT(ctx, path, nil, nil)
 	return err
 }
 
 // UnstarRepo 取消仓库星标
-func (api *RepositoryAPI) UnstarRepo(owner, repo string) error {
+func (api *RepositoryAPI) UnstarRepo(ctx context.Context, owner, repo string) error {
 	path := fmt.Sprintf("/user/starred/%s/%s", owner, repo)
-	_, err := api.Client.DELETE(path, nil)
+	_, err := api.Client.DELETE(ctx, path, nil)
 	return err
 }
 
 // CheckIfStarred 检查当前用户是否已为仓库添加星标
-func (api *RepositoryAPI) CheckIfStarred(owner, repo string) (bool, error) {
+func (api *RepositoryAPI) CheckIfStarred(ctx context.Context, owner, repo string) (bool, error) {
 	path := fmt.Sprintf("/user/starred/%s/%s", owner, repo)
-	_, err := api.Client.GET(path, nil)
+	_, err := api.Client.GET(ctx, path, nil)
 	if err != nil {
 		// 检查是否为404错误，如果是404表示未星标
 		if apiErr, ok := err.(*APIError); ok && apiErr.Code == 404 {
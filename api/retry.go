@@ -0,0 +1,187 @@
+package api
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy 表示请求失败后的重试策略
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数，0表示不重试
+	BaseDelay  time.Duration // 指数退避的基础等待时间
+	MaxDelay   time.Duration // 单次等待的上限
+}
+
+// isIdempotent 判断请求方法是否幂等，只有幂等请求才会自动重试
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryableStatus 判断状态码是否值得重试
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff 计算第attempt次重试（从0开始）前的等待时间
+// 优先使用Retry-After或限流重置响应头，否则使用带抖动的指数退避；
+// 服务端要求的等待时间超过MaxDelay时返回false，表示不再重试
+func (p RetryPolicy) backoff(attempt int, header http.Header) (time.Duration, bool) {
+	if wait, ok := serverRetryDelay(header); ok {
+		if p.MaxDelay > 0 && wait > p.MaxDelay {
+			return 0, false
+		}
+		return wait, true
+	}
+
+	delay := p.BaseDelay << uint(attempt)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0, true
+	}
+
+	// 在[delay/2, delay)区间内随机抖动，避免多个客户端同时重试
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1)), true
+}
+
+// serverRetryDelay 解析服务端返回的重试等待时间
+func serverRetryDelay(header http.Header) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+
+	// Retry-After 可以是秒数或HTTP日期
+	if v := header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(time.Until(t)), true
+		}
+	}
+
+	// 限流重置时间，可能是Unix时间戳或剩余秒数
+	for _, name := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		v := header.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			continue
+		}
+		if n > 1000000000 {
+			return nonNegative(time.Until(time.Unix(n, 0))), true
+		}
+		return time.Duration(n) * time.Second, true
+	}
+
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// circuitBreaker 在API持续失败时快速失败，避免继续冲击服务端
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int           // 连续失败阈值，0表示关闭熔断
+	cooldown  time.Duration // 打开后的冷却时间
+	failures  int           // 当前连续失败次数
+	openUntil time.Time     // 熔断器打开截止时间
+	probing   bool          // 冷却结束后是否已有探测请求在进行
+}
+
+// newCircuitBreaker 创建熔断器
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow 判断当前是否允许发送请求
+// 冷却结束后只放行一个探测请求，其成功与否决定熔断器关闭还是重新打开
+func (b *circuitBreaker) allow() error {
+	if b == nil || b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+
+	if remaining := time.Until(b.openUntil); remaining > 0 {
+		return fmt.Errorf("%w，请在%s后重试", ErrCircuitOpen, remaining.Round(time.Second))
+	}
+
+	if b.probing {
+		return fmt.Errorf("%w，正在探测服务是否恢复", ErrCircuitOpen)
+	}
+	b.probing = true
+	return nil
+}
+
+// success 记录一次成功请求
+func (b *circuitBreaker) success() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// release 放弃一次已放行的请求，既不计为成功也不计为失败。
+// 请求被调用方取消时调用，避免被取消的探测请求使熔断器一直处于探测中而无法关闭
+func (b *circuitBreaker) release() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// failure 记录一次失败请求，连续失败达到阈值时打开熔断器
+func (b *circuitBreaker) failure() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
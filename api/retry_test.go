@@ -0,0 +1,346 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gitcode-org-com/gitcode-mcp/config"
+)
+
+// scriptedResponse 是测试服务器按顺序返回的一个响应
+type scriptedResponse struct {
+	status int
+	header map[string]string
+	body   string
+}
+
+// scriptedServer 按脚本依次返回响应，脚本用完后重复最后一个响应，并记录收到的请求
+type scriptedServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	responses []scriptedResponse
+	requests  []*http.Request
+}
+
+func newScriptedServer(t *testing.T, responses ...scriptedResponse) *scriptedServer {
+	t.Helper()
+	s := &scriptedServer{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		i := len(s.requests)
+		s.requests = append(s.requests, r.Clone(context.Background()))
+		s.mu.Unlock()
+
+		resp := s.responses[min(i, len(s.responses)-1)]
+		for k, v := range resp.header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// count 返回服务器收到的请求数
+func (s *scriptedServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// request 返回收到的第i个请求
+func (s *scriptedServer) request(i int) *http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[i]
+}
+
+// routedServer 按"方法 路径"返回响应，没有对应路由时返回404，并按顺序记录收到的请求
+type routedServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	routes map[string]scriptedResponse
+	calls  []string
+	bodies map[string][]byte
+}
+
+func newRoutedServer(t *testing.T, routes map[string]scriptedResponse) *routedServer {
+	t.Helper()
+	s := &routedServer{routes: routes, bodies: map[string][]byte{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Method + " " + r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.calls = append(s.calls, route)
+		s.bodies[route] = body
+		resp, ok := s.routes[route]
+		s.mu.Unlock()
+
+		if !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		for k, v := range resp.header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// set 修改路由的响应
+func (s *routedServer) set(route string, resp scriptedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[route] = resp
+}
+
+// requests 返回按顺序收到的请求，格式为"方法 路径"
+func (s *routedServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+// writes 返回收到的非GET请求
+func (s *routedServer) writes() []string {
+	var writes []string
+	for _, call := range s.requests() {
+		if !strings.HasPrefix(call, http.MethodGet+" ") {
+			writes = append(writes, call)
+		}
+	}
+	return writes
+}
+
+// body 返回路由最近一次收到的请求体
+func (s *routedServer) body(route string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return string(s.bodies[route])
+}
+
+// newRoutedClient 创建访问路由测试服务器的客户端，使用独立的内存缓存且不开启熔断
+func newRoutedClient(t *testing.T, s *routedServer) *GitCodeAPI {
+	t.Helper()
+	cache := config.NewCacheManager(time.Minute, 0, 0, 0, time.Hour)
+	t.Cleanup(cache.Close)
+	return &GitCodeAPI{
+		Token:      "test-token",
+		BaseURL:    s.URL,
+		HTTPClient: s.Client(),
+		Cache:      cache,
+	}
+}
+
+// newTestClient 创建访问测试服务器的客户端，使用独立的内存缓存且不开启熔断
+func newTestClient(t *testing.T, s *scriptedServer, retry RetryPolicy) *GitCodeAPI {
+	t.Helper()
+	cache := config.NewCacheManager(time.Minute, 0, 0, 0, time.Hour)
+	t.Cleanup(cache.Close)
+	return &GitCodeAPI{
+		Token:      "test-token",
+		BaseURL:    s.URL,
+		HTTPClient: s.Client(),
+		Retry:      retry,
+		Cache:      cache,
+	}
+}
+
+var fastRetry = RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Second}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		script    []scriptedResponse
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "5xx后重试成功",
+			method:    http.MethodGet,
+			script:    []scriptedResponse{{status: 503}, {status: 502}, {status: 200, body: `{}`}},
+			wantCalls: 3,
+		},
+		{
+			name:      "超过最大重试次数",
+			method:    http.MethodGet,
+			script:    []scriptedResponse{{status: 500}},
+			wantCalls: 3,
+			wantErr:   ErrServer,
+		},
+		{
+			name:      "非幂等请求不重试",
+			method:    http.MethodPost,
+			script:    []scriptedResponse{{status: 503}, {status: 200, body: `{}`}},
+			wantCalls: 1,
+			wantErr:   ErrServer,
+		},
+		{
+			name:      "4xx不重试",
+			method:    http.MethodGet,
+			script:    []scriptedResponse{{status: 404, body: `{"message":"not found"}`}, {status: 200, body: `{}`}},
+			wantCalls: 1,
+			wantErr:   ErrNotFound,
+		},
+		{
+			name:      "429后重试成功",
+			method:    http.MethodPut,
+			script:    []scriptedResponse{{status: 429, header: map[string]string{"Retry-After": "0"}}, {status: 200, body: `{}`}},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScriptedServer(t, tt.script...)
+			c := newTestClient(t, s, fastRetry)
+
+			_, err := c.Request(context.Background(), tt.method, "/repos/o/r", nil, nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Request() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Request() error = %v", err)
+			}
+			if n := s.count(); n != tt.wantCalls {
+				t.Fatalf("请求次数 = %d, want %d", n, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	s := newScriptedServer(t,
+		scriptedResponse{status: 429, header: map[string]string{"Retry-After": "1"}},
+		scriptedResponse{status: 200, body: `{}`},
+	)
+	c := newTestClient(t, s, RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Second})
+
+	start := time.Now()
+	if _, err := c.GET(context.Background(), "/repos/o/r", nil); err != nil {
+		t.Fatalf("GET() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("应按Retry-After等待1秒，实际等待%s", elapsed)
+	}
+}
+
+func TestRetryAfterExceedsMaxDelay(t *testing.T) {
+	s := newScriptedServer(t,
+		scriptedResponse{status: 429, header: map[string]string{"Retry-After": "3600"}},
+		scriptedResponse{status: 200, body: `{}`},
+	)
+	c := newTestClient(t, s, fastRetry)
+
+	_, err := c.GET(context.Background(), "/repos/o/r", nil)
+	if !errors.Is(err, ErrRateLimit) {
+		t.Fatalf("GET() error = %v, want ErrRateLimit", err)
+	}
+	if n := s.count(); n != 1 {
+		t.Fatalf("请求次数 = %d, want 1", n)
+	}
+}
+
+func TestRetryCancelledWhileWaiting(t *testing.T) {
+	s := newScriptedServer(t, scriptedResponse{status: 503, header: map[string]string{"Retry-After": "60"}})
+	c := newTestClient(t, s, RetryPolicy{MaxRetries: 1, MaxDelay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.GET(ctx, "/repos/o/r", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GET() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestServerRetryDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{"没有相关响应头", http.Header{}, 0, false},
+		{"Retry-After秒数", http.Header{"Retry-After": {"7"}}, 7 * time.Second, true},
+		{"Retry-After为过去的日期", http.Header{"Retry-After": {"Mon, 01 Jan 2001 00:00:00 GMT"}}, 0, true},
+		{"限流重置剩余秒数", http.Header{"X-Ratelimit-Reset": {"30"}}, 30 * time.Second, true},
+		{"无效的Retry-After", http.Header{"Retry-After": {"soon"}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := serverRetryDelay(tt.header)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("serverRetryDelay() = %s, %v, want %s, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBackoffBounds(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		delay, ok := p.backoff(attempt, nil)
+		if !ok || delay < want/2 || delay > want {
+			t.Fatalf("backoff(%d) = %s, %v, want [%s, %s]", attempt, delay, ok, want/2, want)
+		}
+	}
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	s := newScriptedServer(t, scriptedResponse{status: 500}, scriptedResponse{status: 500}, scriptedResponse{status: 200, body: `{}`})
+	c := newTestClient(t, s, RetryPolicy{})
+	c.breaker = newCircuitBreaker(2, 100*time.Millisecond)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GET(ctx, "/repos/o/r", nil); !errors.Is(err, ErrServer) {
+			t.Fatalf("第%d次GET() error = %v, want ErrServer", i+1, err)
+		}
+	}
+
+	// 连续失败达到阈值后直接失败，不再请求服务端
+	if _, err := c.GET(ctx, "/repos/o/r", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("GET() error = %v, want ErrCircuitOpen", err)
+	}
+	if n := s.count(); n != 2 {
+		t.Fatalf("熔断期间请求次数 = %d, want 2", n)
+	}
+
+	// 冷却结束后放行探测请求，成功后熔断器关闭
+	time.Sleep(150 * time.Millisecond)
+	if _, err := c.GET(ctx, "/repos/o/r", nil); err != nil {
+		t.Fatalf("探测请求 error = %v", err)
+	}
+	if err := c.breaker.allow(); err != nil {
+		t.Fatalf("探测成功后熔断器应关闭: %v", err)
+	}
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	b := newCircuitBreaker(1, 0)
+	b.failure()
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("冷却结束后应放行探测请求: %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("探测进行中应拒绝其他请求, got %v", err)
+	}
+
+	// 探测请求被取消后允许新的探测
+	b.release()
+	if err := b.allow(); err != nil {
+		t.Fatalf("探测请求取消后应放行新的探测: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -41,113 +42,81 @@ type MatchInfo struct {
 }
 
 // SearchCode 搜索代码
-func (api *SearchAPI) SearchCode(query string) (*CodeSearchResult, error) {
+func (api *SearchAPI) SearchCode(ctx context.Context, query string, opts *ListOptions) (*CodeSearchResult, error) {
 	values := url.Values{}
 	values.Set("q", query)
 	
-	resp, err := api.Client.GET("/search/code", values)
+	var result CodeSearchResult
+	items, err := paginate(ctx, api.Client, "/search/code", values, opts, func(body []byte) ([]CodeMatch, error) {
+		var page CodeSearchResult
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, err
+		}
+		result.TotalCount = page.TotalCount
+		return page.Items, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	
-	var result CodeSearchResult
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return nil, fmt.Errorf("解析代码搜索结果失败: %w", err)
-	}
-	
+	result.Items = items
 	return &result, nil
 }
 
 // SearchRepositories 搜索仓库
-func (api *SearchAPI) SearchRepositories(query string) ([]Repository, error) {
+func (api *SearchAPI) SearchRepositories(ctx context.Context, query string, opts *ListOptions) ([]Repository, error) {
 	values := url.Values{}
 	values.Set("q", query)
 	
-	resp, err := api.Client.GET("/search/repositories", values)
+	items, err := paginate(ctx, api.Client, "/search/repositories", values, opts, decodeSearchItems[Repository])
 	if err != nil {
 		return nil, err
 	}
 	
-	var result struct {
-		TotalCount int          `json:"total_count"`
-		Items      []Repository `json:"items"`
-	}
-	
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return nil, fmt.Errorf("解析仓库搜索结果失败: %w", err)
-	}
-	
-	return result.Items, nil
+	return items, nil
 }
 
 // SearchIssues 搜索Issues
-func (api *SearchAPI) SearchIssues(query string) ([]Issue, error) {
+func (api *SearchAPI) SearchIssues(ctx context.Context, query string, opts *ListOptions) ([]Issue, error) {
 	values := url.Values{}
 	values.Set("q", query)
 	
-	resp, err := api.Client.GET("/search/issues", values)
+	items, err := paginate(ctx, api.Client, "/search/issues", values, opts, decodeSearchItems[Issue])
 	if err != nil {
 		return nil, err
 	}
 	
-	var result struct {
-		TotalCount int     `json:"total_count"`
-		Items      []Issue `json:"items"`
-	}
-	
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return nil, fmt.Errorf("解析Issues搜索结果失败: %w", err)
-	}
-	
-	return result.Items, nil
+	return items, nil
 }
 
 // SearchUsers 搜索用户
-func (api *SearchAPI) SearchUsers(query string) ([]User, error) {
+func (api *SearchAPI) SearchUsers(ctx context.Context, query string, opts *ListOptions) ([]User, error) {
 	values := url.Values{}
 	values.Set("q", query)
 	
-	resp, err := api.Client.GET("/search/users", values)
+	items, err := paginate(ctx, api.Client, "/search/users", values, opts, decodeSearchItems[User])
 	if err != nil {
 		return nil, err
 	}
 	
-	var result struct {
-		TotalCount int    `json:"total_count"`
-		Items      []User `json:"items"`
-	}
-	
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return nil, fmt.Errorf("解析用户搜索结果失败: %w", err)
-	}
-	
-	return result.Items, nil
+	return items, nil
 }
 
 // SearchCommits 搜索提交
-func (api *SearchAPI) SearchCommits(query string) ([]interface{}, error) {
+func (api *SearchAPI) SearchCommits(ctx context.Context, query string, opts *ListOptions) ([]Commit, error) {
 	values := url.Values{}
 	values.Set("q", query)
 	
-	resp, err := api.Client.GET("/search/commits", values)
+	items, err := paginate(ctx, api.Client, "/search/commits", values, opts, decodeSearchItems[Commit])
 	if err != nil {
 		return nil, err
 	}
 	
-	var result struct {
-		TotalCount int           `json:"total_count"`
-		Items      []interface{} `json:"items"`
-	}
-	
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return nil, fmt.Errorf("解析提交搜索结果失败: %w", err)
-	}
-	
-	return result.Items, nil
+	return items, nil
 }
 
 // SearchLabels 搜索标签
-func (api *SearchAPI) SearchLabels(owner, repo, query string) ([]Label, error) {
+func (api *SearchAPI) SearchLabels(ctx context.Context, owner, repo, query string, opts *ListOptions) ([]Label, error) {
 	path := fmt.Sprintf("/repos/%s/%s/labels", owner, repo)
 	values := url.Values{}
 	
@@ -155,15 +124,10 @@ func (api *SearchAPI) SearchLabels(owner, repo, query string) ([]Label, error) {
 		values.Set("q", query)
 	}
 	
-	resp, err := api.Client.GET(path, values)
+	labels, err := paginate(ctx, api.Client, path, values, opts, decodeList[Label])
 	if err != nil {
 		return nil, err
 	}
 	
-	var labels []Label
-	if err := json.Unmarshal(resp, &labels); err != nil {
-		return nil, fmt.Errorf("解析标签搜索结果失败: %w", err)
-	}
-	
 	return labels, nil
-} 
\ No newline at end of file
+}
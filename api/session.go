@@ -0,0 +1,47 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gitcode-org-com/gitcode-mcp/config"
+)
+
+type clientKey struct{}
+
+// NewSessionGitCodeAPI 为单个MCP会话创建API客户端。令牌必须由会话提供，不会回退到配置中的令牌；
+// 客户端使用独立的内存缓存，不同会话之间不会共享缓存的数据
+func NewSessionGitCodeAPI(token string) (*GitCodeAPI, error) {
+	if token == "" {
+		return nil, errors.New("会话未提供GitCode令牌")
+	}
+
+	client, err := NewGitCodeAPI(token)
+	if err != nil {
+		return nil, err
+	}
+	client.Cache = config.NewMemoryCache()
+	client.ownsCache = true
+
+	return client, nil
+}
+
+// Close 释放客户端独占的资源，使用全局缓存的客户端调用Close不会有任何影响
+func (c *GitCodeAPI) Close() {
+	if c.ownsCache {
+		c.Cache.Close()
+	}
+}
+
+// WithClient 返回携带会话API客户端的上下文
+func WithClient(ctx context.Context, client *GitCodeAPI) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// ClientFromContext 返回上下文中的会话API客户端，没有时返回fallback
+func ClientFromContext(ctx context.Context, fallback *GitCodeAPI) *GitCodeAPI {
+	if client, ok := ctx.Value(clientKey{}).(*GitCodeAPI); ok && client != nil {
+		return client
+	}
+	return fallback
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrTooLarge 表示上传或下载的内容超过大小限制
+var ErrTooLarge = errors.New("内容超过大小限制")
+
+// transferTimeout 上传和下载文件允许的最长时间
+const transferTimeout = 10 * time.Minute
+
+// Upload 以流的方式发送请求体，用于上传文件
+// 请求体只能读取一次，因此不会重试；成功后与其他写操作一样使相关缓存失效
+func (c *GitCodeAPI) Upload(ctx context.Context, path string, params url.Values, contentType string, body io.Reader) ([]byte, error) {
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	reqURL := c.buildURL(path, params)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, body)
+	if err != nil {
+		c.breaker.release()
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	c.authorize(req)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+
+	log.Printf("API上传: %s %s", req.Method, reqURL)
+	c.counters.upstream.Add(1)
+	resp, err := c.uploadClient().Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.breaker.failure()
+		} else {
+			c.breaker.release()
+		}
+		return nil, fmt.Errorf("HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.breaker.release()
+		return nil, fmt.Errorf("读取响应体失败: %w", err)
+	}
+
+	if resp.StatusCode >= 500 {
+		c.breaker.failure()
+	} else {
+		c.breaker.success()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(resp.StatusCode, respBody)
+	}
+
+	c.invalidate(path)
+	return respBody, nil
+}
+
+// Download 下载rawURL指向的内容并写入w，返回写入的字节数
+// maxBytes大于0时，内容超过该大小会返回ErrTooLarge；令牌只会发送给与BaseURL同一主机的地址
+func (c *GitCodeAPI) Download(ctx context.Context, rawURL string, w io.Writer, maxBytes int64) (int64, error) {
+	if err := c.breaker.allow(); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		c.breaker.release()
+		return 0, fmt.Errorf("创建请求失败: %w", err)
+	}
+	if base, err := url.Parse(c.BaseURL); err == nil && base.Host == req.URL.Host {
+		c.authorize(req)
+	} else {
+		req.Header.Set("User-Agent", "GitCode-MCP-Go-Client/1.0.0")
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+
+	log.Printf("API下载: %s", rawURL)
+	c.counters.upstream.Add(1)
+	resp, err := c.uploadClient().Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.breaker.failure()
+		} else {
+			c.breaker.release()
+		}
+		return 0, fmt.Errorf("HTTP请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		c.breaker.failure()
+	} else {
+		c.breaker.success()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return 0, newAPIError(resp.StatusCode, respBody)
+	}
+
+	if maxBytes > 0 && resp.ContentLength > maxBytes {
+		return 0, fmt.Errorf("%w: 文件大小为%d字节，上限为%d字节", ErrTooLarge, resp.ContentLength, maxBytes)
+	}
+
+	reader := io.Reader(resp.Body)
+	if maxBytes > 0 {
+		// 多读一个字节用于判断是否超过上限
+		reader = io.LimitReader(resp.Body, maxBytes+1)
+	}
+	n, err := io.Copy(w, reader)
+	if err != nil {
+		return n, fmt.Errorf("下载失败: %w", err)
+	}
+	if maxBytes > 0 && n > maxBytes {
+		return n, fmt.Errorf("%w: 文件超过%d字节", ErrTooLarge, maxBytes)
+	}
+	return n, nil
+}
+
+// uploadClient 返回用于传输文件的HTTP客户端
+// 文件传输耗时与大小有关，使用比普通API请求更长的超时时间
+func (c *GitCodeAPI) uploadClient() *http.Client {
+	client := *c.HTTPClient
+	if client.Timeout < transferTimeout {
+		client.Timeout = transferTimeout
+	}
+	return &client
+}
@@ -1,64 +1,197 @@
 package config
 
 import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"path/filepath"
 	"sync"
 	"time"
 )
 
+// Cache 缓存后端接口，内存缓存和磁盘缓存都实现了该接口
+type Cache interface {
+	// Get 获取未过期的缓存值
+	Get(key string) (interface{}, bool)
+	// Set 使用默认TTL设置缓存值
+	Set(key string, value interface{})
+	// SetWithTTL 设置缓存值并指定TTL，TTL不大于0时不缓存
+	SetWithTTL(key string, value interface{}, ttl time.Duration)
+	// SetItem 设置完整的缓存项，TTL不大于0时不缓存
+	SetItem(item *CacheItem, ttl time.Duration)
+	// Lookup 查找缓存项，已过期但可重新验证的缓存项也会返回
+	Lookup(key string) (*CacheItem, bool)
+	// Refresh 延长缓存项的有效期
+	Refresh(key string, ttl time.Duration)
+	// Delete 删除缓存项
+	Delete(key string)
+	// DeleteFunc 删除所有键满足条件的缓存项，返回删除的数量
+	DeleteFunc(match func(key string) bool) int
+	// Clear 清空所有缓存项
+	Clear()
+	// Stats 返回当前的条目数和占用字节数
+	Stats() (entries, bytes int)
+	// Close 停止后台清理协程
+	Close()
+}
+
+// Sizer 由可以报告自身占用字节数的缓存值实现
+type Sizer interface {
+	Size() int
+}
+
 // 缓存项结构体
 type CacheItem struct {
-	Value      interface{} // 缓存的值
-	Expiration time.Time   // 过期时间
+	Key          string      // 缓存键
+	Value        interface{} // 缓存的值
+	Expiration   time.Time   // 过期时间
+	Size         int         // 占用字节数
+	ETag         string      // 响应的ETag，用于条件请求
+	LastModified string      // 响应的Last-Modified，用于条件请求
+}
+
+// Expired 判断缓存项是否已过期
+func (item *CacheItem) Expired() bool {
+	return time.Now().After(item.Expiration)
 }
 
-// 缓存管理器
+// Revalidatable 判断缓存项是否可以通过条件请求重新验证
+func (item *CacheItem) Revalidatable() bool {
+	return item.ETag != "" || item.LastModified != ""
+}
+
+// 缓存管理器，按条目数和字节数限制容量，超出时淘汰最久未使用的缓存项
 type CacheManager struct {
-	mu    sync.RWMutex          // 读写锁
-	items map[string]*CacheItem // 缓存项映射
-	ttl   time.Duration         // 默认TTL
+	mu         sync.Mutex               // 互斥锁，Get也会调整LRU顺序
+	items      map[string]*list.Element // 缓存项映射
+	lru        *list.List               // LRU链表，表头为最近使用
+	ttl        time.Duration            // 默认TTL
+	maxEntries int                      // 最大条目数，0表示不限制
+	maxBytes   int                      // 最大字节数，0表示不限制
+	usedBytes  int                      // 当前占用字节数
+	staleTTL   time.Duration            // 可重新验证的缓存项过期后继续保留的时间
+
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
-// 创建新的缓存管理器
-func NewCacheManager() *CacheManager {
-	// 使用5分钟的固定TTL
-	cacheTTL := 5 * time.Minute
-	
-	return &CacheManager{
-		items: make(map[string]*CacheItem),
-		ttl:   cacheTTL,
+// 创建新的缓存管理器，cleanupInterval大于0时启动后台清理过期项的协程
+// 带有ETag或Last-Modified的缓存项过期后仍保留staleTTL时间，以便通过条件请求重新验证
+func NewCacheManager(ttl time.Duration, maxEntries, maxBytes int, cleanupInterval, staleTTL time.Duration) *CacheManager {
+	c := &CacheManager{
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		staleTTL:   staleTTL,
+		stop:       make(chan struct{}),
 	}
+
+	if cleanupInterval > 0 {
+		go c.janitor(cleanupInterval)
+	}
+
+	return c
 }
 
-// 设置缓存项
+// 设置缓存项，使用默认TTL
 func (c *CacheManager) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// 设置缓存项并指定TTL，TTL不大于0时不缓存
+func (c *CacheManager) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	c.SetItem(&CacheItem{Key: key, Value: value}, ttl)
+}
+
+// 设置完整的缓存项（可带条件请求所需的验证信息），TTL不大于0时不缓存
+func (c *CacheManager) SetItem(item *CacheItem, ttl time.Duration) {
+	if ttl <= 0 {
+		c.Delete(item.Key)
+		return
+	}
+
+	item.Size = sizeOf(item.Value)
+	item.Expiration = time.Now().Add(ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 单个值超过总容量时不缓存
+	if c.maxBytes > 0 && item.Size > c.maxBytes {
+		if elem, found := c.items[item.Key]; found {
+			c.removeElement(elem)
+		}
+		return
+	}
+
+	if elem, found := c.items[item.Key]; found {
+		c.usedBytes -= elem.Value.(*CacheItem).Size
+		elem.Value = item
+		c.lru.MoveToFront(elem)
+	} else {
+		c.items[item.Key] = c.lru.PushFront(item)
+	}
+	c.usedBytes += item.Size
+
+	c.evict()
+}
+
+// 查找缓存项，与Get不同，已过期但可重新验证的缓存项也会返回
+// 返回的是缓存项的副本，调用方可以在不持有锁的情况下读取
+func (c *CacheManager) Lookup(key string) (*CacheItem, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	
-	expiration := time.Now().Add(c.ttl)
-	item := &CacheItem{
-		Value:      value,
-		Expiration: expiration,
+
+	elem, found := c.items[key]
+	if !found {
+		return nil, false
+	}
+
+	item := elem.Value.(*CacheItem)
+	if item.Expired() && !item.Revalidatable() {
+		c.removeElement(elem)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	copied := *item
+	return &copied, true
+}
+
+// 重新验证成功后延长缓存项的有效期
+func (c *CacheManager) Refresh(key string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.items[key]; found {
+		elem.Value.(*CacheItem).Expiration = time.Now().Add(ttl)
+		c.lru.MoveToFront(elem)
 	}
-	
-	// 添加新的缓存项
-	c.items[key] = item
 }
 
 // 获取缓存项
 func (c *CacheManager) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	
-	item, found := c.items[key]
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.items[key]
 	if !found {
 		return nil, false
 	}
-	
-	// 如果已过期，则返回未找到
-	if time.Now().After(item.Expiration) {
+
+	// 如果已过期，则返回未找到；可重新验证的缓存项继续保留
+	item := elem.Value.(*CacheItem)
+	if item.Expired() {
+		if !item.Revalidatable() {
+			c.removeElement(elem)
+		}
 		return nil, false
 	}
-	
+
+	c.lru.MoveToFront(elem)
 	return item.Value, true
 }
 
@@ -66,22 +199,158 @@ func (c *CacheManager) Get(key string) (interface{}, bool) {
 func (c *CacheManager) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	
-	delete(c.items, key)
+
+	if elem, found := c.items[key]; found {
+		c.removeElement(elem)
+	}
+}
+
+// 删除所有键满足条件的缓存项，返回删除的数量
+func (c *CacheManager) DeleteFunc(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, elem := range c.items {
+		if match(key) {
+			c.removeElement(elem)
+			removed++
+		}
+	}
+	return removed
 }
 
 // 清空所有缓存项
 func (c *CacheManager) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	
-	c.items = make(map[string]*CacheItem)
+
+	c.items = make(map[string]*list.Element)
+	c.lru.Init()
+	c.usedBytes = 0
 }
 
-// 全局缓存管理器实例
-var GlobalCache *CacheManager
+// 返回当前的条目数和占用字节数
+func (c *CacheManager) Stats() (entries, bytes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-// 初始化缓存管理器
+	return c.lru.Len(), c.usedBytes
+}
+
+// 停止后台清理协程
+func (c *CacheManager) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// 删除所有已过期的缓存项，可重新验证的缓存项在保留期结束后删除
+func (c *CacheManager) DeleteExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		item := elem.Value.(*CacheItem)
+		deadline := item.Expiration
+		if item.Revalidatable() {
+			deadline = deadline.Add(c.staleTTL)
+		}
+		if now.After(deadline) {
+			c.removeElement(elem)
+		}
+		elem = prev
+	}
+}
+
+// 定期清理过期缓存项
+func (c *CacheManager) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.DeleteExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// 淘汰最久未使用的缓存项直到满足容量限制，调用方需持有锁
+func (c *CacheManager) evict() {
+	for c.lru.Len() > 0 &&
+		((c.maxEntries > 0 && c.lru.Len() > c.maxEntries) || (c.maxBytes > 0 && c.usedBytes > c.maxBytes)) {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// 移除缓存项，调用方需持有锁
+func (c *CacheManager) removeElement(elem *list.Element) {
+	item := c.lru.Remove(elem).(*CacheItem)
+	delete(c.items, item.Key)
+	c.usedBytes -= item.Size
+}
+
+// 估算缓存值占用的字节数
+func sizeOf(value interface{}) int {
+	switch v := value.(type) {
+	case Sizer:
+		return v.Size()
+	case []byte:
+		return len(v)
+	case string:
+		return len(v)
+	default:
+		return 0
+	}
+}
+
+// 全局缓存实例
+var GlobalCache Cache
+
+// 初始化缓存，CACHE_BACKEND=disk时使用磁盘缓存，失败时回退到内存缓存
 func InitCache() {
-	GlobalCache = NewCacheManager()
-} 
\ No newline at end of file
+	if GlobalCache != nil {
+		GlobalCache.Close()
+	}
+
+	ttl := CacheTTL(CacheClassDefault)
+	cleanupInterval := time.Duration(GlobalConfig.CacheCleanupInterval) * time.Second
+	staleTTL := time.Duration(GlobalConfig.CacheStaleRetention) * time.Second
+
+	if GlobalConfig.CacheBackend == CacheBackendDisk {
+		dir := filepath.Join(GlobalConfig.CacheDir, CacheIdentity(GlobalConfig.GitCodeToken))
+		cache, err := NewDiskCache(dir, ttl, GlobalConfig.CacheMaxEntries, GlobalConfig.CacheDiskMaxBytes, cleanupInterval, staleTTL)
+		if err == nil {
+			GlobalCache = cache
+			return
+		}
+		log.Printf("初始化磁盘缓存失败: %v，将使用内存缓存", err)
+	}
+
+	GlobalCache = NewMemoryCache()
+}
+
+// NewMemoryCache 按当前配置创建一个独立的内存缓存，用于每个会话各自的API客户端
+func NewMemoryCache() *CacheManager {
+	return NewCacheManager(
+		CacheTTL(CacheClassDefault),
+		GlobalConfig.CacheMaxEntries,
+		GlobalConfig.CacheMaxBytes,
+		time.Duration(GlobalConfig.CacheCleanupInterval)*time.Second,
+		time.Duration(GlobalConfig.CacheStaleRetention)*time.Second,
+	)
+}
+
+// CacheIdentity 根据令牌生成缓存目录名，不同令牌的缓存互相隔离且不会暴露令牌本身
+func CacheIdentity(token string) string {
+	if token == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// sized 是报告固定字节数的缓存值
+type sized int
+
+func (s sized) Size() int { return int(s) }
+
+func TestCacheManagerLRU(t *testing.T) {
+	t.Run("按条目数淘汰", func(t *testing.T) {
+		c := NewCacheManager(time.Minute, 3, 0, 0, 0)
+		defer c.Close()
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Set("c", 3)
+		c.Get("a")    // a变为最近使用
+		c.Lookup("b") // Lookup同样调整顺序
+		c.Set("d", 4)
+
+		if _, ok := c.Get("c"); ok {
+			t.Fatal("最久未使用的c应被淘汰")
+		}
+		for _, key := range []string{"a", "b", "d"} {
+			if _, ok := c.Get(key); !ok {
+				t.Fatalf("Get(%s) 未命中", key)
+			}
+		}
+	})
+
+	t.Run("按字节数淘汰", func(t *testing.T) {
+		c := NewCacheManager(time.Minute, 0, 10, 0, 0)
+		defer c.Close()
+		c.Set("a", "aaaa")
+		c.Set("b", []byte("bbbb"))
+		c.Set("a", "aaa") // 覆盖时重新计算大小并移到表头
+		c.Set("c", sized(4))
+
+		if _, ok := c.Get("b"); ok {
+			t.Fatal("超出容量时应淘汰b")
+		}
+		if entries, bytes := c.Stats(); entries != 2 || bytes != 7 {
+			t.Fatalf("Stats() = %d, %d, want 2, 7", entries, bytes)
+		}
+
+		// 单个值超过总容量时不缓存，并删除已有的旧值
+		c.Set("a", sized(11))
+		if _, ok := c.Get("a"); ok {
+			t.Fatal("超过总容量的值不应缓存")
+		}
+		if entries, bytes := c.Stats(); entries != 1 || bytes != 4 {
+			t.Fatalf("Stats() = %d, %d, want 1, 4", entries, bytes)
+		}
+	})
+}
+
+func TestCacheManagerExpiry(t *testing.T) {
+	c := NewCacheManager(time.Minute, 0, 0, 0, time.Hour)
+	defer c.Close()
+	c.SetWithTTL("plain", "1", time.Millisecond)
+	c.SetItem(&CacheItem{Key: "tagged", Value: "2", ETag: `"v1"`}, time.Millisecond)
+	c.SetItem(&CacheItem{Key: "dated", Value: "3", LastModified: "Mon, 02 Jan 2006 15:04:05 GMT"}, time.Millisecond)
+	c.Set("fresh", "4")
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get("plain"); ok {
+		t.Fatal("Get不应返回过期的缓存项")
+	}
+	if entries, _ := c.Stats(); entries != 3 {
+		t.Fatalf("过期且不可重新验证的缓存项应在Get时删除, entries = %d", entries)
+	}
+
+	// 可重新验证的缓存项过期后Get未命中，但仍可通过Lookup取出用于条件请求
+	for _, key := range []string{"tagged", "dated"} {
+		if _, ok := c.Get(key); ok {
+			t.Fatalf("Get(%s) 不应返回过期的缓存项", key)
+		}
+		item, ok := c.Lookup(key)
+		if !ok || !item.Expired() || !item.Revalidatable() {
+			t.Fatalf("Lookup(%s) = %+v, %v", key, item, ok)
+		}
+	}
+
+	c.Refresh("tagged", time.Minute)
+	if v, ok := c.Get("tagged"); !ok || v != "2" {
+		t.Fatalf("Refresh后 Get(tagged) = %v, %v", v, ok)
+	}
+
+	c.SetWithTTL("fresh", "5", 0)
+	if _, ok := c.Get("fresh"); ok {
+		t.Fatal("TTL为0时应删除缓存项")
+	}
+}
+
+func TestCacheManagerStaleRetention(t *testing.T) {
+	c := NewCacheManager(time.Minute, 0, 0, 0, 20*time.Millisecond)
+	defer c.Close()
+	c.SetWithTTL("plain", "1", time.Millisecond)
+	c.SetItem(&CacheItem{Key: "tagged", Value: "2", ETag: `"v1"`}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// 保留期内只清理不可重新验证的缓存项
+	c.DeleteExpired()
+	if _, ok := c.Lookup("plain"); ok {
+		t.Fatal("不可重新验证的过期缓存项应被清理")
+	}
+	if _, ok := c.Lookup("tagged"); !ok {
+		t.Fatal("保留期内可重新验证的缓存项应继续保留")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	c.DeleteExpired()
+	if _, ok := c.Lookup("tagged"); ok {
+		t.Fatal("保留期结束后应清理可重新验证的缓存项")
+	}
+	if entries, bytes := c.Stats(); entries != 0 || bytes != 0 {
+		t.Fatalf("Stats() = %d, %d", entries, bytes)
+	}
+}
+
+func TestCacheManagerLookupCopy(t *testing.T) {
+	c := NewCacheManager(time.Minute, 0, 0, 0, 0)
+	defer c.Close()
+	c.SetItem(&CacheItem{Key: "k", Value: "v", ETag: `"v1"`}, time.Minute)
+
+	item, _ := c.Lookup("k")
+	item.ETag = `"changed"`
+	if again, _ := c.Lookup("k"); again.ETag != `"v1"` {
+		t.Fatalf("修改Lookup返回的副本不应影响缓存, ETag = %q", again.ETag)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	saved := GlobalConfig
+	t.Cleanup(func() { GlobalConfig = saved })
+
+	t.Setenv("GITCODE_TOKEN", "token")
+	t.Setenv("CACHE_TTL_REPO", "42")
+	t.Setenv("CACHE_TTL_SEARCH", "0")
+	if err := Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		class string
+		want  time.Duration
+	}{
+		{CacheClassRepo, 42 * time.Second},
+		{CacheClassSearch, 0},
+		{CacheClassIssue, 60 * time.Second},
+		{CacheClassUser, 900 * time.Second},
+		{"unknown", 300 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := CacheTTL(tt.class); got != tt.want {
+			t.Errorf("CacheTTL(%s) = %v, want %v", tt.class, got, tt.want)
+		}
+	}
+
+	// 环境变量不应修改默认配置，重新初始化后恢复默认值
+	t.Setenv("CACHE_TTL_REPO", "")
+	if err := Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if got := CacheTTL(CacheClassRepo); got != 900*time.Second {
+		t.Fatalf("CacheTTL(repo) = %v, want 15m", got)
+	}
+
+	t.Setenv("CACHE_TTL_PULL", "-1")
+	if err := Init(); err == nil || !strings.Contains(err.Error(), "pull") {
+		t.Fatalf("Init() error = %v, want 负数缓存时间的错误", err)
+	}
+}
+
+func TestCacheIdentity(t *testing.T) {
+	if got := CacheIdentity(""); got != "anonymous" {
+		t.Fatalf("CacheIdentity(\"\") = %q, want anonymous", got)
+	}
+
+	a := CacheIdentity("token-a")
+	if a != CacheIdentity("token-a") {
+		t.Fatal("同一令牌的缓存目录名应保持不变")
+	}
+	if a == CacheIdentity("token-b") {
+		t.Fatal("不同令牌的缓存目录名不应相同")
+	}
+	if !cacheIdentityPattern.MatchString(a) || strings.Contains(a, "token") {
+		t.Fatalf("CacheIdentity() = %q, want 不含令牌的16位十六进制", a)
+	}
+}
@@ -3,8 +3,29 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
+)
+
+// 缓存端点类别，不同类别的数据使用不同的缓存时间
+const (
+	CacheClassDefault = "default" // 未归类的接口
+	CacheClassRepo    = "repo"    // 仓库元数据
+	CacheClassBranch  = "branch"  // 分支
+	CacheClassIssue   = "issue"   // Issue、评论和标签
+	CacheClassPull    = "pull"    // Pull Request
+	CacheClassSearch  = "search"  // 搜索结果
+	CacheClassUser    = "user"    // 用户信息
+)
+
+// 缓存后端类型
+const (
+	CacheBackendMemory = "memory" // 内存缓存，进程退出后失效
+	CacheBackendDisk   = "disk"   // 磁盘缓存，重启后仍然有效
 )
 
 // 配置结构体
@@ -14,17 +35,88 @@ type Config struct {
 	GitCodeAPIURL string // GitCode API基础URL
 	APITimeout    int    // API请求超时时间（秒）
 
+	// 重试与熔断配置
+	APIMaxRetries           int // 幂等请求失败后的最大重试次数，0表示不重试
+	APIRetryBaseDelay       int // 重试退避的基础等待时间（毫秒）
+	APIRetryMaxDelay        int // 单次重试的最大等待时间（秒）
+	CircuitBreakerThreshold int // 连续失败多少次后打开熔断器，0表示关闭熔断
+	CircuitBreakerCooldown  int // 熔断器打开后的冷却时间（秒）
+
+	// 缓存配置
+	CacheBackend         string         // 缓存后端 (memory或disk)
+	CacheDir             string         // 磁盘缓存根目录
+	CacheDiskMaxBytes    int            // 磁盘缓存最大字节数，0表示不限制
+	CacheMaxEntries      int            // 最大缓存条目数，0表示不限制
+	CacheMaxBytes        int            // 最大缓存字节数，0表示不限制
+	CacheCleanupInterval int            // 后台清理过期缓存的间隔（秒），0表示不清理
+	CacheStaleRetention  int            // 带ETag/Last-Modified的缓存过期后继续保留用于条件请求的时间（秒）
+	CacheTTLs            map[string]int // 各端点类别的缓存时间（秒），0表示不缓存
+
+	// 本地文件配置
+	ReleaseAssetMaxBytes int    // 上传和下载发布附件的最大字节数，0表示不限制
+	LocalFileDir         string // 工具读写本地文件的根目录，SSE/HTTP模式下未设置时不允许使用本地文件路径
+
 	// MCP配置
-	MCPTransport string // MCP传输方式 (stdio或sse)
+	MCPTransport string // MCP传输方式 (stdio、sse或http)
 	MCPSSEPort   int    // SSE服务器端口
+	MCPHTTPPort  int    // Streamable HTTP服务器端口
+	MCPHTTPPath  string // Streamable HTTP端点路径
+
+	// MCPAllowedHosts SSE/HTTP模式下允许的Host和Origin主机名，本机地址总是允许
+	MCPAllowedHosts []string
+
+	// OAuth配置，设置MCPOAuthIssuer后SSE/HTTP模式要求客户端提供OAuth访问令牌
+	MCPOAuthIssuer           string // 授权服务器的issuer
+	MCPOAuthResource         string // 本服务器的资源标识（规范URL），访问令牌的aud必须包含该值
+	MCPOAuthIntrospectionURL string // 令牌内省端点，为空时从授权服务器元数据中获取
+	MCPOAuthClientID         string // 调用内省端点使用的客户端ID
+	MCPOAuthClientSecret     string // 调用内省端点使用的客户端密钥
+
+	// 工具注册配置
+	ReadOnly   bool     // 只注册不修改数据的工具
+	Toolsets   []string // 启用的工具集，为空时启用全部
+	ToolsAllow []string // 只注册名称匹配任意一个glob模式的工具，为空时不限制
+	ToolsDeny  []string // 不注册名称匹配任意一个glob模式的工具
+
+	// 仓库访问策略文件，为空时不限制工具访问的仓库
+	RepoPolicyFile string
 }
 
+// Toolsets 所有可用的工具集，与mcp/tools中的工具分组一致
+var Toolsets = []string{"repos", "branches", "issues", "pulls", "search"}
+
 // 默认配置值
 var defaultConfig = Config{
 	GitCodeAPIURL: "https://api.gitcode.com/api/v5",
 	MCPTransport:  "stdio",
 	MCPSSEPort:    8000,
+	MCPHTTPPort:   8000,
+	MCPHTTPPath:   "/mcp",
 	APITimeout:    30,
+
+	APIMaxRetries:           3,
+	APIRetryBaseDelay:       500,
+	APIRetryMaxDelay:        30,
+	CircuitBreakerThreshold: 5,
+	CircuitBreakerCooldown:  30,
+
+	CacheBackend:         CacheBackendMemory,
+	CacheDiskMaxBytes:    256 << 20,
+	CacheMaxEntries:      1000,
+	CacheMaxBytes:        64 << 20,
+	CacheCleanupInterval: 60,
+	CacheStaleRetention:  3600,
+	CacheTTLs: map[string]int{
+		CacheClassDefault: 300,
+		CacheClassRepo:    900,
+		CacheClassBranch:  300,
+		CacheClassIssue:   60,
+		CacheClassPull:    60,
+		CacheClassSearch:  0,
+		CacheClassUser:    900,
+	},
+
+	ReleaseAssetMaxBytes: 100 << 20,
 }
 
 // 全局配置实例
@@ -34,6 +126,10 @@ var GlobalConfig Config
 func Init() error {
 	// 默认使用默认配置
 	GlobalConfig = defaultConfig
+	GlobalConfig.CacheTTLs = make(map[string]int, len(defaultConfig.CacheTTLs))
+	for class, ttl := range defaultConfig.CacheTTLs {
+		GlobalConfig.CacheTTLs[class] = ttl
+	}
 
 	// 从环境变量读取配置，如果未设置，使用默认值
 	if token := os.Getenv("GITCODE_TOKEN"); token != "" {
@@ -53,6 +149,66 @@ func Init() error {
 			GlobalConfig.MCPSSEPort = port
 		}
 	}
+
+	if httpPort := os.Getenv("MCP_HTTP_PORT"); httpPort != "" {
+		if port, err := strconv.Atoi(httpPort); err == nil {
+			GlobalConfig.MCPHTTPPort = port
+		}
+	}
+
+	if httpPath := os.Getenv("MCP_HTTP_PATH"); httpPath != "" {
+		GlobalConfig.MCPHTTPPath = httpPath
+	}
+
+	if allowedHosts := os.Getenv("MCP_ALLOWED_HOSTS"); allowedHosts != "" {
+		GlobalConfig.MCPAllowedHosts = splitList(allowedHosts)
+	}
+
+	if issuer := os.Getenv("MCP_OAUTH_ISSUER"); issuer != "" {
+		GlobalConfig.MCPOAuthIssuer = issuer
+	}
+
+	if resource := os.Getenv("MCP_OAUTH_RESOURCE"); resource != "" {
+		GlobalConfig.MCPOAuthResource = resource
+	}
+
+	if introspectionURL := os.Getenv("MCP_OAUTH_INTROSPECTION_URL"); introspectionURL != "" {
+		GlobalConfig.MCPOAuthIntrospectionURL = introspectionURL
+	}
+
+	if clientID := os.Getenv("MCP_OAUTH_CLIENT_ID"); clientID != "" {
+		GlobalConfig.MCPOAuthClientID = clientID
+	}
+
+	if clientSecret := os.Getenv("MCP_OAUTH_CLIENT_SECRET"); clientSecret != "" {
+		GlobalConfig.MCPOAuthClientSecret = clientSecret
+	}
+
+	if readOnly := os.Getenv("GITCODE_READ_ONLY"); readOnly != "" {
+		value, err := strconv.ParseBool(readOnly)
+		if err != nil {
+			// 无法识别时按只读处理，避免误配置暴露写操作
+			log.Printf("警告: GITCODE_READ_ONLY配置无效: %s，将以只读模式运行", readOnly)
+			value = true
+		}
+		GlobalConfig.ReadOnly = value
+	}
+
+	if toolsets := os.Getenv("GITCODE_TOOLSETS"); toolsets != "" {
+		GlobalConfig.Toolsets = splitList(toolsets)
+	}
+
+	if allow := os.Getenv("GITCODE_TOOLS_ALLOW"); allow != "" {
+		GlobalConfig.ToolsAllow = splitList(allow)
+	}
+
+	if deny := os.Getenv("GITCODE_TOOLS_DENY"); deny != "" {
+		GlobalConfig.ToolsDeny = splitList(deny)
+	}
+
+	if policyFile := os.Getenv("GITCODE_REPO_POLICY"); policyFile != "" {
+		GlobalConfig.RepoPolicyFile = policyFile
+	}
 	
 	if apiTimeout := os.Getenv("API_TIMEOUT"); apiTimeout != "" {
 		if timeout, err := strconv.Atoi(apiTimeout); err == nil {
@@ -60,6 +216,94 @@ func Init() error {
 		}
 	}
 
+	if maxRetries := os.Getenv("API_MAX_RETRIES"); maxRetries != "" {
+		if retries, err := strconv.Atoi(maxRetries); err == nil {
+			GlobalConfig.APIMaxRetries = retries
+		}
+	}
+
+	if baseDelay := os.Getenv("API_RETRY_BASE_DELAY_MS"); baseDelay != "" {
+		if delay, err := strconv.Atoi(baseDelay); err == nil {
+			GlobalConfig.APIRetryBaseDelay = delay
+		}
+	}
+
+	if maxDelay := os.Getenv("API_RETRY_MAX_DELAY"); maxDelay != "" {
+		if delay, err := strconv.Atoi(maxDelay); err == nil {
+			GlobalConfig.APIRetryMaxDelay = delay
+		}
+	}
+
+	if threshold := os.Getenv("API_CIRCUIT_BREAKER_THRESHOLD"); threshold != "" {
+		if n, err := strconv.Atoi(threshold); err == nil {
+			GlobalConfig.CircuitBreakerThreshold = n
+		}
+	}
+
+	if cooldown := os.Getenv("API_CIRCUIT_BREAKER_COOLDOWN"); cooldown != "" {
+		if n, err := strconv.Atoi(cooldown); err == nil {
+			GlobalConfig.CircuitBreakerCooldown = n
+		}
+	}
+
+	if backend := os.Getenv("CACHE_BACKEND"); backend != "" {
+		GlobalConfig.CacheBackend = strings.ToLower(backend)
+	}
+
+	GlobalConfig.CacheDir = defaultCacheDir()
+	if cacheDir := os.Getenv("CACHE_DIR"); cacheDir != "" {
+		GlobalConfig.CacheDir = cacheDir
+	}
+
+	if diskMaxBytes := os.Getenv("CACHE_DISK_MAX_BYTES"); diskMaxBytes != "" {
+		if n, err := strconv.Atoi(diskMaxBytes); err == nil {
+			GlobalConfig.CacheDiskMaxBytes = n
+		}
+	}
+
+	if maxEntries := os.Getenv("CACHE_MAX_ENTRIES"); maxEntries != "" {
+		if n, err := strconv.Atoi(maxEntries); err == nil {
+			GlobalConfig.CacheMaxEntries = n
+		}
+	}
+
+	if maxBytes := os.Getenv("CACHE_MAX_BYTES"); maxBytes != "" {
+		if n, err := strconv.Atoi(maxBytes); err == nil {
+			GlobalConfig.CacheMaxBytes = n
+		}
+	}
+
+	if interval := os.Getenv("CACHE_CLEANUP_INTERVAL"); interval != "" {
+		if n, err := strconv.Atoi(interval); err == nil {
+			GlobalConfig.CacheCleanupInterval = n
+		}
+	}
+
+	if retention := os.Getenv("CACHE_STALE_RETENTION"); retention != "" {
+		if n, err := strconv.Atoi(retention); err == nil {
+			GlobalConfig.CacheStaleRetention = n
+		}
+	}
+
+	// 各端点类别的缓存时间，例如 CACHE_TTL_REPO、CACHE_TTL_SEARCH
+	for class := range GlobalConfig.CacheTTLs {
+		if ttl := os.Getenv("CACHE_TTL_" + strings.ToUpper(class)); ttl != "" {
+			if n, err := strconv.Atoi(ttl); err == nil {
+				GlobalConfig.CacheTTLs[class] = n
+			}
+		}
+	}
+
+	if assetMaxBytes := os.Getenv("RELEASE_ASSET_MAX_BYTES"); assetMaxBytes != "" {
+		if n, err := strconv.Atoi(assetMaxBytes); err == nil {
+			GlobalConfig.ReleaseAssetMaxBytes = n
+		}
+	}
+
+	if localFileDir := os.Getenv("GITCODE_LOCAL_FILE_DIR"); localFileDir != "" {
+		GlobalConfig.LocalFileDir = localFileDir
+	}
+
 	// 验证配置
 	return validateConfig()
 }
@@ -71,10 +315,137 @@ func validateConfig() error {
 		log.Println("警告: 未设置GitCode API令牌，某些功能可能无法正常工作")
 	}
 
+	// 验证传输方式
+	switch strings.ToLower(GlobalConfig.MCPTransport) {
+	case "stdio", "sse", "http":
+	default:
+		return fmt.Errorf("MCP传输方式配置无效: %s，可选值为stdio、sse或http", GlobalConfig.MCPTransport)
+	}
+
 	// 验证SSE服务器端口范围
-	if GlobalConfig.MCPTransport == "sse" && (GlobalConfig.MCPSSEPort <= 0 || GlobalConfig.MCPSSEPort > 65535) {
+	if strings.EqualFold(GlobalConfig.MCPTransport, "sse") && (GlobalConfig.MCPSSEPort <= 0 || GlobalConfig.MCPSSEPort > 65535) {
 		return fmt.Errorf("SSE服务器端口配置无效: %d，有效范围为1-65535", GlobalConfig.MCPSSEPort)
 	}
 
+	// 验证Streamable HTTP服务器端口和路径
+	if strings.EqualFold(GlobalConfig.MCPTransport, "http") {
+		if GlobalConfig.MCPHTTPPort <= 0 || GlobalConfig.MCPHTTPPort > 65535 {
+			return fmt.Errorf("HTTP服务器端口配置无效: %d，有效范围为1-65535", GlobalConfig.MCPHTTPPort)
+		}
+		if !strings.HasPrefix(GlobalConfig.MCPHTTPPath, "/") || strings.ContainsAny(GlobalConfig.MCPHTTPPath, "?# ") {
+			return fmt.Errorf("HTTP端点路径配置无效: %q，必须以/开头且不能包含查询参数", GlobalConfig.MCPHTTPPath)
+		}
+	}
+
+	// 验证OAuth配置
+	if GlobalConfig.MCPOAuthIssuer != "" {
+		if err := validateOAuthURL("MCP_OAUTH_ISSUER", GlobalConfig.MCPOAuthIssuer); err != nil {
+			return err
+		}
+		if GlobalConfig.MCPOAuthResource == "" {
+			return fmt.Errorf("OAuth配置无效: 启用OAuth时必须设置MCP_OAUTH_RESOURCE")
+		}
+		if err := validateOAuthURL("MCP_OAUTH_RESOURCE", GlobalConfig.MCPOAuthResource); err != nil {
+			return err
+		}
+		if GlobalConfig.MCPOAuthIntrospectionURL != "" {
+			if err := validateOAuthURL("MCP_OAUTH_INTROSPECTION_URL", GlobalConfig.MCPOAuthIntrospectionURL); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 验证工具集
+	for _, toolset := range GlobalConfig.Toolsets {
+		valid := false
+		for _, name := range Toolsets {
+			valid = valid || toolset == name
+		}
+		if !valid {
+			return fmt.Errorf("工具集配置无效: %s，可选值为%s", toolset, strings.Join(Toolsets, "、"))
+		}
+	}
+
+	// 验证重试与熔断配置
+	if GlobalConfig.APIMaxRetries < 0 || GlobalConfig.APIRetryBaseDelay < 0 || GlobalConfig.APIRetryMaxDelay < 0 {
+		return fmt.Errorf("API重试配置无效: 重试次数和等待时间不能为负数")
+	}
+	if GlobalConfig.CircuitBreakerThreshold < 0 || GlobalConfig.CircuitBreakerCooldown < 0 {
+		return fmt.Errorf("熔断器配置无效: 阈值和冷却时间不能为负数")
+	}
+
+	// 验证缓存配置
+	if GlobalConfig.CacheBackend != CacheBackendMemory && GlobalConfig.CacheBackend != CacheBackendDisk {
+		return fmt.Errorf("缓存后端配置无效: %s，可选值为memory或disk", GlobalConfig.CacheBackend)
+	}
+	if GlobalConfig.CacheDiskMaxBytes < 0 {
+		return fmt.Errorf("缓存配置无效: 磁盘缓存容量不能为负数")
+	}
+	if GlobalConfig.CacheMaxEntries < 0 || GlobalConfig.CacheMaxBytes < 0 || GlobalConfig.CacheCleanupInterval < 0 || GlobalConfig.CacheStaleRetention < 0 {
+		return fmt.Errorf("缓存配置无效: 容量、清理间隔和保留时间不能为负数")
+	}
+	for class, ttl := range GlobalConfig.CacheTTLs {
+		if ttl < 0 {
+			return fmt.Errorf("缓存配置无效: %s类接口的缓存时间不能为负数", class)
+		}
+	}
+
+	// 验证发布附件配置
+	if GlobalConfig.ReleaseAssetMaxBytes < 0 {
+		return fmt.Errorf("发布附件配置无效: 最大字节数不能为负数")
+	}
+
+	return nil
+}
+
+// splitList 将逗号分隔的配置拆分为列表，忽略空项
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// validateOAuthURL 验证OAuth相关的URL：必须是不带片段的绝对URL，只有本机地址允许使用http
+func validateOAuthURL(name, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("OAuth配置无效: %s必须是绝对URL: %s", name, raw)
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("OAuth配置无效: %s不能包含片段: %s", name, raw)
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		switch u.Hostname() {
+		case "localhost", "127.0.0.1", "::1":
+		default:
+			return fmt.Errorf("OAuth配置无效: %s必须使用https，只有本机地址可以使用http: %s", name, raw)
+		}
+	default:
+		return fmt.Errorf("OAuth配置无效: %s必须使用https: %s", name, raw)
+	}
 	return nil
-} 
\ No newline at end of file
+}
+
+// CacheTTL 返回指定端点类别的缓存时间，未配置的类别使用默认缓存时间
+func CacheTTL(class string) time.Duration {
+	ttl, ok := GlobalConfig.CacheTTLs[class]
+	if !ok {
+		ttl = GlobalConfig.CacheTTLs[CacheClassDefault]
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// defaultCacheDir 返回默认的磁盘缓存目录，位于用户缓存目录下
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "gitcode-mcp")
+}
@@ -0,0 +1,524 @@
+package config
+
+import (
+	"bufio"
+	"container/list"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diskCacheExt 磁盘缓存文件的扩展名
+const diskCacheExt = ".cache"
+
+// diskEntryMeta 磁盘缓存文件头部的元数据，加载索引时只需读取这一部分
+type diskEntryMeta struct {
+	Key          string
+	Expiration   time.Time
+	ETag         string
+	LastModified string
+}
+
+// diskEntryValue 磁盘缓存文件中的缓存值
+// 值通过gob编码，自定义类型需要事先调用gob.Register注册
+type diskEntryValue struct {
+	Value interface{}
+}
+
+// diskEntry 磁盘缓存在内存中的索引项
+type diskEntry struct {
+	meta diskEntryMeta
+	file string
+	size int
+}
+
+// 磁盘缓存，缓存项保存在目录下的独立文件中，进程重启后仍然有效
+// 内存中只保存索引，按条目数和字节数限制容量，超出时淘汰最久未使用的缓存项
+type DiskCache struct {
+	mu         sync.Mutex               // 互斥锁
+	dir        string                   // 缓存目录
+	items      map[string]*list.Element // 缓存键到索引项的映射
+	lru        *list.List               // LRU链表，表头为最近使用
+	ttl        time.Duration            // 默认TTL
+	maxEntries int                      // 最大条目数，0表示不限制
+	maxBytes   int                      // 最大字节数，0表示不限制
+	usedBytes  int                      // 当前占用字节数
+	staleTTL   time.Duration            // 可重新验证的缓存项过期后继续保留的时间
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// 创建磁盘缓存并加载目录中已有的缓存项
+func NewDiskCache(dir string, ttl time.Duration, maxEntries, maxBytes int, cleanupInterval, staleTTL time.Duration) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+
+	c := &DiskCache{
+		dir:        dir,
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		staleTTL:   staleTTL,
+		stop:       make(chan struct{}),
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	if cleanupInterval > 0 {
+		go c.janitor(cleanupInterval)
+	}
+
+	return c, nil
+}
+
+// 设置缓存项，使用默认TTL
+func (c *DiskCache) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// 设置缓存项并指定TTL，TTL不大于0时不缓存
+func (c *DiskCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	c.SetItem(&CacheItem{Key: key, Value: value}, ttl)
+}
+
+// 设置完整的缓存项，TTL不大于0时不缓存
+func (c *DiskCache) SetItem(item *CacheItem, ttl time.Duration) {
+	if ttl <= 0 {
+		c.Delete(item.Key)
+		return
+	}
+
+	item.Expiration = time.Now().Add(ttl)
+	meta := diskEntryMeta{
+		Key:          item.Key,
+		Expiration:   item.Expiration,
+		ETag:         item.ETag,
+		LastModified: item.LastModified,
+	}
+
+	// 编码和写临时文件不持有锁，只在重命名和更新索引时加锁
+	file := c.fileFor(item.Key)
+	tmp, size, err := writeDiskTemp(c.dir, meta, item.Value)
+	if err != nil {
+		log.Printf("写入磁盘缓存失败: %v", err)
+		return
+	}
+	defer os.Remove(tmp)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 单个值超过总容量时不缓存
+	if c.maxBytes > 0 && size > c.maxBytes {
+		if elem, found := c.items[item.Key]; found {
+			c.removeElement(elem)
+		}
+		return
+	}
+
+	if err := os.Rename(tmp, file); err != nil {
+		log.Printf("写入磁盘缓存失败: %v", err)
+		return
+	}
+
+	entry := &diskEntry{meta: meta, file: file, size: size}
+	if elem, found := c.items[item.Key]; found {
+		c.usedBytes -= elem.Value.(*diskEntry).size
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+	} else {
+		c.items[item.Key] = c.lru.PushFront(entry)
+	}
+	c.usedBytes += size
+	item.Size = size
+
+	c.evict()
+}
+
+// 获取缓存项
+func (c *DiskCache) Get(key string) (interface{}, bool) {
+	item, found := c.Lookup(key)
+	if !found || item.Expired() {
+		return nil, false
+	}
+	return item.Value, true
+}
+
+// 查找缓存项，已过期但可重新验证的缓存项也会返回
+func (c *DiskCache) Lookup(key string) (*CacheItem, bool) {
+	elem, entry, found := c.entry(key)
+	if !found {
+		return nil, false
+	}
+	item := &CacheItem{
+		Key:          entry.meta.Key,
+		Expiration:   entry.meta.Expiration,
+		Size:         entry.size,
+		ETag:         entry.meta.ETag,
+		LastModified: entry.meta.LastModified,
+	}
+	if item.Expired() && !item.Revalidatable() {
+		c.mu.Lock()
+		c.removeEntry(elem, entry)
+		c.mu.Unlock()
+		return nil, false
+	}
+
+	// 读取和解码文件不持有锁，文件通过重命名整体替换，不会读到写了一半的内容
+	meta, value, err := readDiskEntry(entry.file, true)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		// 文件可能已被其他进程清理或损坏，视为未命中
+		c.removeEntry(elem, entry)
+		return nil, false
+	}
+	// 读取期间文件可能被重新写入，以文件中的元数据为准
+	item.Value = value
+	item.Expiration, item.ETag, item.LastModified = meta.Expiration, meta.ETag, meta.LastModified
+
+	if elem.Value == entry {
+		c.lru.MoveToFront(elem)
+	}
+	return item, true
+}
+
+// 重新验证成功后延长缓存项的有效期
+func (c *DiskCache) Refresh(key string, ttl time.Duration) {
+	elem, entry, found := c.entry(key)
+	if !found {
+		return
+	}
+
+	meta, value, err := readDiskEntry(entry.file, true)
+	if err != nil {
+		c.mu.Lock()
+		c.removeEntry(elem, entry)
+		c.mu.Unlock()
+		return
+	}
+	meta.Expiration = time.Now().Add(ttl)
+	tmp, size, err := writeDiskTemp(c.dir, meta, value)
+	if err != nil {
+		log.Printf("更新磁盘缓存失败: %v", err)
+		return
+	}
+	defer os.Remove(tmp)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 读写文件期间缓存项已被替换或删除时放弃更新，避免覆盖新写入的值
+	if c.items[key] != elem || elem.Value != entry {
+		return
+	}
+	if err := os.Rename(tmp, entry.file); err != nil {
+		log.Printf("更新磁盘缓存失败: %v", err)
+		return
+	}
+	elem.Value = &diskEntry{meta: meta, file: entry.file, size: size}
+	c.usedBytes += size - entry.size
+	c.lru.MoveToFront(elem)
+	c.evict()
+}
+
+// 删除缓存项
+func (c *DiskCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.items[key]; found {
+		c.removeElement(elem)
+	}
+}
+
+// 删除所有键满足条件的缓存项，返回删除的数量
+func (c *DiskCache) DeleteFunc(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, elem := range c.items {
+		if match(key) {
+			c.removeElement(elem)
+			removed++
+		}
+	}
+	return removed
+}
+
+// 清空所有缓存项
+func (c *DiskCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, elem := range c.items {
+		c.removeElement(elem)
+	}
+}
+
+// 返回当前的条目数和占用字节数
+func (c *DiskCache) Stats() (entries, bytes int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len(), c.usedBytes
+}
+
+// 停止后台清理协程
+func (c *DiskCache) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// 删除所有已过期的缓存项，可重新验证的缓存项在保留期结束后删除
+func (c *DiskCache) DeleteExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		meta := elem.Value.(*diskEntry).meta
+		deadline := meta.Expiration
+		if meta.ETag != "" || meta.LastModified != "" {
+			deadline = deadline.Add(c.staleTTL)
+		}
+		if now.After(deadline) {
+			c.removeElement(elem)
+		}
+		elem = prev
+	}
+}
+
+// 定期清理过期缓存项
+func (c *DiskCache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.DeleteExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// 扫描缓存目录建立索引，按文件修改时间恢复LRU顺序
+func (c *DiskCache) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("读取缓存目录失败: %w", err)
+	}
+
+	type loaded struct {
+		entry   *diskEntry
+		modTime time.Time
+	}
+	var entries []loaded
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), diskCacheExt) {
+			continue
+		}
+		file := filepath.Join(c.dir, f.Name())
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		meta, _, err := readDiskEntry(file, false)
+		if err != nil {
+			// 无法解析的文件直接删除
+			os.Remove(file)
+			continue
+		}
+		entries = append(entries, loaded{
+			entry:   &diskEntry{meta: meta, file: file, size: int(info.Size())},
+			modTime: info.ModTime(),
+		})
+	}
+
+	// 最近修改的文件放在表头
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.After(entries[j].modTime)
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range entries {
+		c.items[e.entry.meta.Key] = c.lru.PushBack(e.entry)
+		c.usedBytes += e.entry.size
+	}
+	c.evict()
+
+	return nil
+}
+
+// 根据缓存键生成文件路径
+func (c *DiskCache) fileFor(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+diskCacheExt)
+}
+
+// 返回缓存键对应的索引项
+func (c *DiskCache) entry(key string) (*list.Element, *diskEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.items[key]
+	if !found {
+		return nil, nil, false
+	}
+	return elem, elem.Value.(*diskEntry), true
+}
+
+// 缓存项仍是entry时将其移除，调用方需持有锁
+func (c *DiskCache) removeEntry(elem *list.Element, entry *diskEntry) {
+	if c.items[entry.meta.Key] == elem && elem.Value == entry {
+		c.removeElement(elem)
+	}
+}
+
+// 淘汰最久未使用的缓存项直到满足容量限制，调用方需持有锁
+func (c *DiskCache) evict() {
+	for c.lru.Len() > 0 &&
+		((c.maxEntries > 0 && c.lru.Len() > c.maxEntries) || (c.maxBytes > 0 && c.usedBytes > c.maxBytes)) {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// 移除缓存项及其文件，调用方需持有锁
+func (c *DiskCache) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*diskEntry)
+	delete(c.items, entry.meta.Key)
+	c.usedBytes -= entry.size
+	os.Remove(entry.file)
+}
+
+// 将缓存项写入目录下的临时文件，返回临时文件路径和大小
+// 调用方将其重命名为缓存文件，避免其他进程读到写了一半的文件
+func writeDiskTemp(dir string, meta diskEntryMeta, value interface{}) (string, int, error) {
+	tmp, err := os.CreateTemp(dir, "tmp-*")
+	if err != nil {
+		return "", 0, err
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := gob.NewEncoder(w)
+	err = enc.Encode(meta)
+	if err == nil {
+		err = enc.Encode(diskEntryValue{Value: value})
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	var info os.FileInfo
+	if err == nil {
+		info, err = tmp.Stat()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, err
+	}
+	return tmp.Name(), int(info.Size()), nil
+}
+
+// 从文件读取缓存项，withValue为false时只读取元数据
+func readDiskEntry(file string, withValue bool) (diskEntryMeta, interface{}, error) {
+	var meta diskEntryMeta
+
+	f, err := os.Open(file)
+	if err != nil {
+		return meta, nil, err
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(bufio.NewReader(f))
+	if err := dec.Decode(&meta); err != nil {
+		return meta, nil, err
+	}
+	if !withValue {
+		return meta, nil, nil
+	}
+
+	var value diskEntryValue
+	if err := dec.Decode(&value); err != nil {
+		return meta, nil, err
+	}
+	return meta, value.Value, nil
+}
+
+var (
+	// cacheIdentityPattern 匹配CacheIdentity生成的目录名
+	cacheIdentityPattern = regexp.MustCompile(`^(anonymous|[0-9a-f]{16})$`)
+	// cacheFilePattern 匹配缓存文件和写入缓存时残留的临时文件
+	cacheFilePattern = regexp.MustCompile(`^([0-9a-f]{64}\` + diskCacheExt + `|tmp-[0-9]+)$`)
+)
+
+// ClearDiskCache 删除磁盘缓存根目录下各令牌的缓存目录及其中的缓存文件。
+// 为避免CACHE_DIR配置错误（如指向用户主目录）时误删其他文件，目录中只要有不是本包写入的内容就拒绝清除
+func ClearDiskCache(dir string) error {
+	identities, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取缓存目录失败: %w", err)
+	}
+
+	// 先检查全部内容，确认都是缓存再删除
+	var files, dirs []string
+	for _, identity := range identities {
+		sub := filepath.Join(dir, identity.Name())
+		if !identity.IsDir() || !cacheIdentityPattern.MatchString(identity.Name()) {
+			return fmt.Errorf("缓存目录 %s 中包含不属于缓存的 %s，已拒绝清除，请检查CACHE_DIR配置", dir, sub)
+		}
+		entries, err := os.ReadDir(sub)
+		if err != nil {
+			return fmt.Errorf("读取缓存目录失败: %w", err)
+		}
+		for _, entry := range entries {
+			file := filepath.Join(sub, entry.Name())
+			if !entry.Type().IsRegular() || !cacheFilePattern.MatchString(entry.Name()) {
+				return fmt.Errorf("缓存目录 %s 中包含不属于缓存的 %s，已拒绝清除，请检查CACHE_DIR配置", dir, file)
+			}
+			files = append(files, file)
+		}
+		dirs = append(dirs, sub)
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除缓存文件失败: %w", err)
+		}
+	}
+	for _, sub := range dirs {
+		if err := os.Remove(sub); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除缓存目录失败: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestDiskCache(t *testing.T, dir string, maxEntries int) *DiskCache {
+	t.Helper()
+	c, err := NewDiskCache(dir, time.Minute, maxEntries, 0, 0, time.Minute)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestDiskCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestDiskCache(t, dir, 0)
+	c.Set("a", "1")
+	c.SetItem(&CacheItem{Key: "b", Value: []byte("2"), ETag: `"v1"`}, time.Minute)
+	c.Set("c", "3")
+	c.Get("a")
+
+	// 重新打开后缓存项和验证信息仍然有效，LRU顺序按文件修改时间恢复
+	reopened := newTestDiskCache(t, dir, 0)
+	if v, ok := reopened.Get("a"); !ok || v != "1" {
+		t.Fatalf("Get(a) = %v, %v", v, ok)
+	}
+	item, ok := reopened.Lookup("b")
+	if !ok || string(item.Value.([]byte)) != "2" || item.ETag != `"v1"` {
+		t.Fatalf("Lookup(b) = %+v, %v", item, ok)
+	}
+	if entries, bytes := reopened.Stats(); entries != 3 || bytes == 0 {
+		t.Fatalf("Stats() = %d, %d", entries, bytes)
+	}
+
+	c.Delete("a")
+	if _, ok := reopened.Get("a"); ok {
+		t.Fatal("其他实例删除的缓存文件应视为未命中")
+	}
+	if entries, _ := reopened.Stats(); entries != 2 {
+		t.Fatalf("Stats() entries = %d, want 2", entries)
+	}
+}
+
+func TestDiskCacheLoadOrder(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestDiskCache(t, dir, 0)
+	for i, key := range []string{"old", "mid", "new"} {
+		c.Set(key, key)
+		mtime := time.Now().Add(time.Duration(i-3) * time.Hour)
+		os.Chtimes(c.fileFor(key), mtime, mtime)
+	}
+
+	// 容量不足时淘汰修改时间最早的缓存项
+	reopened := newTestDiskCache(t, dir, 2)
+	if _, ok := reopened.Get("old"); ok {
+		t.Fatal("最久未修改的缓存项应被淘汰")
+	}
+	for _, key := range []string{"mid", "new"} {
+		if _, ok := reopened.Get(key); !ok {
+			t.Fatalf("Get(%s) 未命中", key)
+		}
+	}
+}
+
+func TestDiskCacheExpiry(t *testing.T) {
+	c := newTestDiskCache(t, t.TempDir(), 0)
+	c.SetWithTTL("plain", "1", time.Millisecond)
+	c.SetItem(&CacheItem{Key: "tagged", Value: "2", ETag: `"v1"`}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Lookup("plain"); ok {
+		t.Fatal("过期且不可重新验证的缓存项不应返回")
+	}
+	if _, err := os.Stat(c.fileFor("plain")); !os.IsNotExist(err) {
+		t.Fatalf("过期缓存项的文件应被删除: %v", err)
+	}
+
+	if _, ok := c.Get("tagged"); ok {
+		t.Fatal("Get不应返回过期的缓存项")
+	}
+	item, ok := c.Lookup("tagged")
+	if !ok || !item.Expired() || item.Value != "2" {
+		t.Fatalf("Lookup(tagged) = %+v, %v, want 过期但可重新验证", item, ok)
+	}
+
+	c.Refresh("tagged", time.Minute)
+	if v, ok := c.Get("tagged"); !ok || v != "2" {
+		t.Fatalf("Refresh后 Get(tagged) = %v, %v", v, ok)
+	}
+	if item, _ := c.Lookup("tagged"); item.ETag != `"v1"` {
+		t.Fatalf("Refresh后ETag = %q", item.ETag)
+	}
+
+	c.SetWithTTL("tagged", "3", 0)
+	if _, ok := c.Lookup("tagged"); ok {
+		t.Fatal("TTL为0时应删除缓存项")
+	}
+}
+
+func TestDiskCacheConcurrentAccess(t *testing.T) {
+	c := newTestDiskCache(t, t.TempDir(), 0)
+	c.SetItem(&CacheItem{Key: "k", Value: "0", ETag: `"v"`}, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				switch (i + j) % 3 {
+				case 0:
+					c.SetItem(&CacheItem{Key: "k", Value: "1", ETag: `"v"`}, time.Minute)
+				case 1:
+					c.Refresh("k", time.Minute)
+				default:
+					if item, ok := c.Lookup("k"); ok && item.Value != "0" && item.Value != "1" {
+						t.Errorf("Lookup(k) = %v", item.Value)
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if entries, _ := c.Stats(); entries != 1 {
+		t.Fatalf("Stats() entries = %d, want 1", entries)
+	}
+	files, _ := os.ReadDir(c.dir)
+	if len(files) != 1 {
+		t.Fatalf("缓存目录中有%d个文件，want 1", len(files))
+	}
+}
+
+func TestDiskCacheIdentityIsolation(t *testing.T) {
+	root := t.TempDir()
+	alice := newTestDiskCache(t, filepath.Join(root, CacheIdentity("alice-token")), 0)
+	bob := newTestDiskCache(t, filepath.Join(root, CacheIdentity("bob-token")), 0)
+
+	alice.Set("/user", "alice")
+	if _, ok := bob.Get("/user"); ok {
+		t.Fatal("不同令牌的缓存不应互相可见")
+	}
+	bob.Set("/user", "bob")
+
+	again := newTestDiskCache(t, filepath.Join(root, CacheIdentity("alice-token")), 0)
+	if v, ok := again.Get("/user"); !ok || v != "alice" {
+		t.Fatalf("Get(/user) = %v, %v, want alice", v, ok)
+	}
+
+	if err := ClearDiskCache(root); err != nil {
+		t.Fatalf("ClearDiskCache() error = %v", err)
+	}
+	if files, _ := os.ReadDir(root); len(files) != 0 {
+		t.Fatalf("清除后缓存根目录仍有%d项", len(files))
+	}
+}
+
+func TestClearDiskCacheRefusesForeignFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(root, sub string)
+	}{
+		{"根目录中有其他文件", func(root, sub string) { os.WriteFile(filepath.Join(root, ".bashrc"), nil, 0o600) }},
+		{"根目录中有其他目录", func(root, sub string) { os.Mkdir(filepath.Join(root, "projects"), 0o700) }},
+		{"缓存目录中有其他文件", func(root, sub string) { os.WriteFile(filepath.Join(sub, "notes.txt"), nil, 0o600) }},
+		{"缓存目录中有子目录", func(root, sub string) { os.Mkdir(filepath.Join(sub, "nested"), 0o700) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			sub := filepath.Join(root, CacheIdentity("token"))
+			c := newTestDiskCache(t, sub, 0)
+			c.Set("k", "v")
+			tt.setup(root, sub)
+
+			err := ClearDiskCache(root)
+			if err == nil || !strings.Contains(err.Error(), "已拒绝清除") {
+				t.Fatalf("ClearDiskCache() error = %v, want 拒绝清除", err)
+			}
+			if _, err := os.Stat(c.fileFor("k")); err != nil {
+				t.Fatalf("拒绝清除时不应删除任何文件: %v", err)
+			}
+		})
+	}
+
+	if err := ClearDiskCache(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Fatalf("缓存目录不存在时 ClearDiskCache() error = %v", err)
+	}
+}
@@ -5,11 +5,10 @@ go 1.23
 toolchain go1.24.1
 
 require (
+	github.com/google/uuid v1.6.0
 	github.com/joho/godotenv v1.5.1
 	github.com/mark3labs/mcp-go v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require (
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
-)
+require github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
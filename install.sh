@@ -104,6 +104,7 @@ echo -e "${GREEN}GitCode MCP Server 安装完成！${NC}"
 echo -e "${YELLOW}配置文件位置: $CONFIG_DIR${NC}"
 echo -e "${YELLOW}使用方法:${NC}"
 echo -e "  STDIO模式: gitcode-mcp"
+echo -e "  Streamable HTTP模式: MCP_TRANSPORT=http gitcode-mcp"
 echo -e "  配置文件: 编辑 $CONFIG_DIR/.env 设置您的GitCode令牌"
 echo -e "  平台配置文件: 位于 $CONFIG_DIR/docs/ 目录下"
 echo -e ""
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"strings"
 	
 	"github.com/joho/godotenv"
 	
@@ -26,6 +29,14 @@ func init() {
 }
 
 func main() {
+	// 处理子命令，例如 gitcode-mcp cache clear
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatalf("%v", err)
+		}
+		return
+	}
+	
 	log.Println("正在启动GitCode MCP服务器...")
 	
 	// 创建令牌管理器
@@ -45,4 +56,19 @@ func main() {
 	if err := mcp.Run(server, options); err != nil {
 		log.Fatalf("启动MCP服务器失败: %v", err)
 	}
-} 
\ No newline at end of file
+}
+
+// runCommand 执行命令行子命令
+func runCommand(args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "cache" && args[1] == "clear":
+		config.GlobalCache.Close()
+		if err := config.ClearDiskCache(config.GlobalConfig.CacheDir); err != nil {
+			return err
+		}
+		fmt.Printf("已清除磁盘缓存: %s\n", config.GlobalConfig.CacheDir)
+		return nil
+	default:
+		return fmt.Errorf("未知命令: %s\n用法:\n  gitcode-mcp              启动MCP服务器\n  gitcode-mcp cache clear  清除磁盘缓存", strings.Join(args, " "))
+	}
+}
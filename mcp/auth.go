@@ -0,0 +1,362 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+	"github.com/gitcode-org-com/gitcode-mcp/mcp/tools"
+)
+
+// HeaderGitCodeToken 启用OAuth后，Authorization头用于OAuth访问令牌，GitCode令牌通过该请求头提供
+const HeaderGitCodeToken = "X-GitCode-Token"
+
+var (
+	// ErrMissingToken 连接未携带GitCode令牌且服务器没有配置默认令牌
+	ErrMissingToken = errors.New("缺少GitCode令牌")
+	// ErrTokenMismatch 请求携带的令牌与建立会话时的令牌不一致
+	ErrTokenMismatch = errors.New("令牌与会话不匹配")
+)
+
+// SessionAuth 从SSE/HTTP握手请求中读取GitCode令牌，为每个会话创建独立的API客户端。
+// 未携带令牌的连接使用服务器配置的默认令牌，没有默认令牌时拒绝连接。
+// 启用OAuth时，每个请求都必须携带有效的访问令牌，GitCode令牌改为通过X-GitCode-Token头提供
+type SessionAuth struct {
+	allowDefault bool
+	oauth        *OAuthValidator
+}
+
+// NewSessionAuth 创建会话认证器，allowDefault表示是否允许未携带令牌的连接使用默认令牌，
+// oauth为nil时不要求OAuth访问令牌
+func NewSessionAuth(allowDefault bool, oauth *OAuthValidator) *SessionAuth {
+	return &SessionAuth{allowDefault: allowDefault, oauth: oauth}
+}
+
+// gitcodeToken 读取请求携带的GitCode令牌
+func (a *SessionAuth) gitcodeToken(r *http.Request) string {
+	if a.oauth != nil {
+		return strings.TrimSpace(r.Header.Get(HeaderGitCodeToken))
+	}
+	return BearerToken(r)
+}
+
+// missingToken 返回缺少GitCode令牌的错误，说明应该通过哪个请求头提供
+func (a *SessionAuth) missingToken() error {
+	if a.oauth != nil {
+		return fmt.Errorf("%w，请在%s请求头中提供", ErrMissingToken, HeaderGitCodeToken)
+	}
+	return fmt.Errorf("%w，请在Authorization请求头中提供", ErrMissingToken)
+}
+
+// authenticate 启用OAuth时验证请求携带的访问令牌
+func (a *SessionAuth) authenticate(r *http.Request) (*Grant, error) {
+	if a == nil || a.oauth == nil {
+		return nil, nil
+	}
+	return a.oauth.Validate(r.Context(), BearerToken(r))
+}
+
+// writeError 返回认证失败的响应；启用OAuth时在WWW-Authenticate头中给出受保护资源元数据的地址
+func (a *SessionAuth) writeError(w http.ResponseWriter, err error) {
+	status := http.StatusUnauthorized
+	switch {
+	case errors.Is(err, ErrMissingAccessToken), errors.Is(err, ErrInvalidToken):
+	case errors.Is(err, ErrMissingToken):
+		if a != nil && a.oauth != nil {
+			// 访问令牌有效但缺少GitCode令牌，重新授权无法解决
+			status = http.StatusForbidden
+		}
+	case errors.Is(err, ErrTokenMismatch), errors.Is(err, tools.ErrInsufficientScope):
+		status = http.StatusForbidden
+	default:
+		// 授权服务器不可用等情况，不要求客户端重新授权
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	if a != nil && a.oauth != nil {
+		w.Header().Set("WWW-Authenticate", a.oauth.challenge(err))
+	} else if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="gitcode-mcp"`)
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// sessionClient 表示一个会话使用的API客户端及其令牌摘要
+type sessionClient struct {
+	auth    *SessionAuth
+	client  *api.GitCodeAPI // 为nil时使用默认客户端
+	digest  [sha256.Size]byte
+	subject string // 启用OAuth时建立会话的访问令牌所属的用户
+}
+
+// BearerToken 从Authorization请求头读取令牌，支持"Bearer <token>"和"token <token>"两种格式
+func BearerToken(r *http.Request) string {
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok {
+		return ""
+	}
+	switch strings.ToLower(scheme) {
+	case "bearer", "token":
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// open 根据握手请求创建会话客户端，同时返回访问令牌的授权信息（未启用OAuth时为nil）
+func (a *SessionAuth) open(r *http.Request) (*sessionClient, *Grant, error) {
+	grant, err := a.authenticate(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	session := &sessionClient{auth: a}
+	if grant != nil {
+		session.subject = grant.Subject
+	}
+	if a == nil {
+		return session, nil, nil
+	}
+
+	token := a.gitcodeToken(r)
+	if token == "" {
+		if a.allowDefault {
+			// 使用默认客户端，上下文中不放入会话客户端
+			return session, grant, nil
+		}
+		return nil, nil, a.missingToken()
+	}
+
+	client, err := api.NewSessionGitCodeAPI(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	session.client = client
+	session.digest = sha256.Sum256([]byte(token))
+	return session, grant, nil
+}
+
+// verify 校验会话中后续请求携带的令牌与握手时一致，防止仅凭会话ID使用他人的令牌。
+// 启用OAuth时每个请求都重新验证访问令牌，令牌刷新后只要属于同一用户即可继续使用会话
+func (c *sessionClient) verify(r *http.Request) (*Grant, error) {
+	grant, err := c.auth.authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+	if grant != nil && grant.Subject != c.subject {
+		return nil, ErrTokenMismatch
+	}
+
+	if c.client == nil {
+		return grant, nil
+	}
+	token := c.auth.gitcodeToken(r)
+	if token == "" {
+		return nil, c.auth.missingToken()
+	}
+	digest := sha256.Sum256([]byte(token))
+	if subtle.ConstantTimeCompare(digest[:], c.digest[:]) != 1 {
+		return nil, ErrTokenMismatch
+	}
+	return grant, nil
+}
+
+// context 将会话客户端和访问令牌的scope放入上下文，
+// 工具处理函数通过api.ClientFromContext获取客户端，工具分组按scope授权
+func (c *sessionClient) context(ctx context.Context, grant *Grant) context.Context {
+	if grant != nil {
+		ctx = tools.WithScopes(ctx, grant.Scopes)
+	}
+	if c == nil || c.client == nil {
+		return ctx
+	}
+	return api.WithClient(ctx, c.client)
+}
+
+// close 释放会话客户端的缓存
+func (c *sessionClient) close() {
+	if c != nil && c.client != nil {
+		c.client.Close()
+	}
+}
+
+// sseEndpointPattern 匹配SSE握手时发送的endpoint事件中的会话ID
+var sseEndpointPattern = regexp.MustCompile(`sessionId=([0-9a-fA-F-]+)`)
+
+// sseAuthHandler 为SSE传输的每个连接创建会话客户端。SSE服务器在握手响应的endpoint事件中分配会话ID，
+// 这里从该事件中读取会话ID并登记客户端，之后发送到消息端点的请求按会话ID取回客户端
+type sseAuthHandler struct {
+	auth     *SessionAuth
+	hosts    *HostValidator
+	next     *server.SSEServer
+	sessions sync.Map // 会话ID -> *sseConnection
+}
+
+// sseConnection 表示一个SSE连接的会话客户端，以及响应还没有经过SSE流发出的tools/list请求。
+// SSE服务器把响应同时写入消息请求的响应体和SSE流，两处都要去掉访问令牌无权调用的工具
+type sseConnection struct {
+	client    *sessionClient
+	mu        sync.Mutex
+	toolLists map[string]tools.Scopes // 请求ID -> 访问令牌的scope
+}
+
+// expectToolList 登记一个tools/list请求，其响应经过SSE流时按scopes过滤
+func (c *sseConnection) expectToolList(id json.RawMessage, scopes tools.Scopes) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.toolLists == nil {
+		c.toolLists = map[string]tools.Scopes{}
+	}
+	c.toolLists[string(bytes.TrimSpace(id))] = scopes
+}
+
+// filterEvent 过滤SSE流中已登记的tools/list响应，其他事件原样返回
+func (c *sseConnection) filterEvent(event []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.toolLists) == 0 {
+		return event
+	}
+	data, ok := bytes.CutPrefix(event, []byte("event: message\ndata: "))
+	if !ok {
+		return event
+	}
+	data, ok = bytes.CutSuffix(data, []byte("\n\n"))
+	if !ok {
+		return event
+	}
+	var envelope jsonrpcEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return event
+	}
+	scopes, ok := c.toolLists[string(envelope.ID)]
+	if !ok {
+		return event
+	}
+	delete(c.toolLists, string(envelope.ID))
+	return fmt.Appendf(nil, "event: message\ndata: %s\n\n", filterToolListJSON(data, scopes))
+}
+
+// newSSEHandler 创建带会话认证和Host校验的SSE服务器
+func newSSEHandler(s *server.MCPServer, auth *SessionAuth, hosts *HostValidator) *sseAuthHandler {
+	return &sseAuthHandler{auth: auth, hosts: hosts, next: server.NewSSEServer(s)}
+}
+
+// ServeHTTP 实现http.Handler接口
+func (h *sseAuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.hosts.Valid(r) {
+		http.Error(w, "Forbidden host or origin", http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodPost {
+		value, ok := h.sessions.Load(r.URL.Query().Get("sessionId"))
+		if !ok {
+			// 没有经过握手认证的会话一律拒绝
+			writeJSONRPCError(w, http.StatusNotFound, mcpgo.INVALID_REQUEST, "会话不存在或已过期，请重新连接")
+			return
+		}
+		conn := value.(*sseConnection)
+		grant, err := conn.client.verify(r)
+		if err != nil {
+			h.auth.writeError(w, err)
+			return
+		}
+		if grant != nil {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, httpMaxBodyBytes))
+			if err != nil {
+				writeJSONRPCError(w, http.StatusBadRequest, mcpgo.PARSE_ERROR, "读取请求体失败")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			var envelope jsonrpcEnvelope
+			if json.Unmarshal(body, &envelope) == nil && envelope.Method == string(mcpgo.MethodToolsList) && envelope.isRequest() {
+				conn.expectToolList(envelope.ID, grant.Scopes)
+				w = &toolListWriter{ResponseWriter: w, scopes: grant.Scopes}
+			}
+		}
+		// SSE服务器以请求的上下文作为工具调用的上下文
+		h.next.ServeHTTP(w, r.WithContext(conn.client.context(r.Context(), grant)))
+		return
+	}
+	if r.Method != http.MethodGet {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	session, _, err := h.auth.open(r)
+	if err != nil {
+		h.auth.writeError(w, err)
+		return
+	}
+	defer session.close()
+
+	conn := &sseConnection{client: session}
+	sw := &sessionIDWriter{ResponseWriter: w, conn: conn, found: func(id string) {
+		h.sessions.Store(id, conn)
+	}}
+	defer func() {
+		if sw.id != "" {
+			h.sessions.Delete(sw.id)
+		}
+	}()
+	h.next.ServeHTTP(sw, r)
+}
+
+// sessionIDWriter 在SSE响应中查找第一个endpoint事件，从中读取会话ID，之后的事件交给连接过滤
+type sessionIDWriter struct {
+	http.ResponseWriter
+	conn  *sseConnection
+	id    string
+	found func(id string)
+}
+
+// Write 写入响应并在第一次出现会话ID时回调
+func (w *sessionIDWriter) Write(p []byte) (int, error) {
+	if w.id == "" {
+		if m := sseEndpointPattern.FindSubmatch(p); m != nil {
+			w.id = string(m[1])
+			// 在事件发送给客户端之前登记，保证客户端的第一条消息就能找到会话客户端
+			w.found(w.id)
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	// SSE服务器每个事件只调用一次Write
+	if _, err := w.ResponseWriter.Write(w.conn.filterEvent(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush 实现http.Flusher接口
+func (w *sessionIDWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// toolListWriter 过滤消息请求响应体中的tools/list结果
+type toolListWriter struct {
+	http.ResponseWriter
+	scopes tools.Scopes
+}
+
+// Write 写入过滤后的响应，SSE服务器一次写入整个响应体
+func (w *toolListWriter) Write(p []byte) (int, error) {
+	if _, err := w.ResponseWriter.Write(filterToolListJSON(p, w.scopes)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
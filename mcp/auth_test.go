@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/gitcode-org-com/gitcode-mcp/mcp/tools"
+)
+
+// toolListResponse 是tools/list响应中测试关心的部分
+type toolListResponse struct {
+	ID     int `json:"id"`
+	Result struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	} `json:"result"`
+}
+
+// checkReadOnlyIssueTools 检查tools/list只返回issues:read令牌有权调用的工具
+func checkReadOnlyIssueTools(t *testing.T, transport string, list toolListResponse) {
+	t.Helper()
+	if len(list.Result.Tools) == 0 {
+		t.Fatalf("%s: tools/list没有返回工具", transport)
+	}
+	for _, tool := range list.Result.Tools {
+		info, ok := tools.LookupTool(tool.Name)
+		if !ok || info.Group != tools.GroupIssues || !info.ReadOnly {
+			t.Fatalf("%s: issues:read令牌不应看到工具%s", transport, tool.Name)
+		}
+	}
+}
+
+// sseClient 是测试用的SSE传输客户端
+type sseClient struct {
+	t        *testing.T
+	ts       *httptest.Server
+	token    string
+	events   *bufio.Reader
+	endpoint string
+}
+
+// dialSSE 打开SSE连接并读取endpoint事件
+func dialSSE(t *testing.T, ts *httptest.Server, token string) *sseClient {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/sse", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("SSE握手 status = %d", resp.StatusCode)
+	}
+
+	c := &sseClient{t: t, ts: ts, token: token, events: bufio.NewReader(resp.Body)}
+	event, data := c.next()
+	if event != "endpoint" {
+		t.Fatalf("第一个事件 = %s, want endpoint", event)
+	}
+	c.endpoint = data
+	return c
+}
+
+// next 读取下一个SSE事件
+func (c *sseClient) next() (event, data string) {
+	c.t.Helper()
+	e, err := readSSEEvent(c.events)
+	if err != nil {
+		c.t.Fatalf("读取SSE事件失败: %v", err)
+	}
+	return e.event, e.data
+}
+
+// post 向消息端点发送JSON-RPC消息，返回响应体
+func (c *sseClient) post(body string) string {
+	c.t.Helper()
+	req, err := http.NewRequest(http.MethodPost, c.ts.URL+c.endpoint, strings.NewReader(body))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		c.t.Fatalf("POST %s status = %d", body, resp.StatusCode)
+	}
+	var buf strings.Builder
+	bufio.NewReader(resp.Body).WriteTo(&buf)
+	return buf.String()
+}
+
+func TestToolListFilteredByScope(t *testing.T) {
+	as := newFakeAuthServer(t)
+	as.token("reader", map[string]interface{}{"scope": "issues:read"})
+	v := as.validator()
+
+	t.Run("Streamable HTTP", func(t *testing.T) {
+		ts := newOAuthTestServer(t, v)
+		resp := postMCP(t, ts, "reader", "", initializeRequest)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("initialize status = %d", resp.StatusCode)
+		}
+		resp = postMCP(t, ts, "reader", resp.Header.Get(HeaderSessionID), `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+		var list toolListResponse
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			t.Fatal(err)
+		}
+		checkReadOnlyIssueTools(t, "响应体", list)
+	})
+
+	t.Run("SSE", func(t *testing.T) {
+		s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+		tools.RegisterAllTools(s, nil)
+		ts := httptest.NewServer(withOAuthMetadata(newSSEHandler(s, NewSessionAuth(true, v), nil), v))
+		t.Cleanup(ts.Close)
+
+		c := dialSSE(t, ts, "reader")
+		c.post(initializeRequest)
+		if _, data := c.next(); !strings.Contains(data, `"id":1`) {
+			t.Fatalf("initialize响应 = %s", data)
+		}
+
+		// 响应同时出现在消息请求的响应体和SSE流中，两处都要过滤
+		var list toolListResponse
+		if err := json.Unmarshal([]byte(c.post(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)), &list); err != nil {
+			t.Fatal(err)
+		}
+		checkReadOnlyIssueTools(t, "响应体", list)
+
+		_, data := c.next()
+		list = toolListResponse{}
+		if err := json.Unmarshal([]byte(data), &list); err != nil {
+			t.Fatal(err)
+		}
+		if list.ID != 2 {
+			t.Fatalf("SSE事件 = %s, want id 2", data)
+		}
+		checkReadOnlyIssueTools(t, "SSE流", list)
+	})
+}
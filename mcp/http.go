@@ -0,0 +1,710 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/gitcode-org-com/gitcode-mcp/mcp/tools"
+)
+
+const (
+	// HeaderSessionID 会话ID请求头，初始化成功后由服务器返回，后续请求必须携带
+	HeaderSessionID = "Mcp-Session-Id"
+	// headerLastEventID 客户端断线重连时携带的最后收到的事件ID
+	headerLastEventID = "Last-Event-ID"
+
+	// httpEventLogSize 每个会话保留的最近事件数，用于断线重连后补发
+	httpEventLogSize = 256
+	// httpSessionIdleTimeout 会话在没有请求和连接时的最长保留时间
+	httpSessionIdleTimeout = 30 * time.Minute
+	// httpMaxBodyBytes 单个POST请求体的最大字节数
+	httpMaxBodyBytes = 4 << 20
+
+	// standaloneStream GET请求打开的服务器推送流
+	standaloneStream = "standalone"
+)
+
+// StreamableHTTPServer 实现MCP的Streamable HTTP传输。所有请求通过同一个端点：
+// POST发送JSON-RPC消息，GET打开服务器推送流，DELETE结束会话。
+// 每个SSE事件都带有ID，客户端断线后可以通过Last-Event-ID恢复未收到的事件
+type StreamableHTTPServer struct {
+	server   *server.MCPServer
+	path     string
+	auth     *SessionAuth
+	hosts    *HostValidator
+	sessions sync.Map // 会话ID -> *httpSession
+
+	janitor sync.Once
+}
+
+// NewStreamableHTTPServer 创建Streamable HTTP服务器，path为MCP端点路径，为空时使用/mcp；
+// auth为nil时所有会话都使用默认的API客户端；hosts为nil时只允许本机地址和IP地址形式的Host
+func NewStreamableHTTPServer(s *server.MCPServer, path string, auth *SessionAuth, hosts *HostValidator) *StreamableHTTPServer {
+	if path == "" {
+		path = "/mcp"
+	}
+	return &StreamableHTTPServer{
+		server: s,
+		path:   path,
+		auth:   auth,
+		hosts:  hosts,
+	}
+}
+
+// httpEvent 表示已发送（或待发送）给客户端的SSE事件
+type httpEvent struct {
+	id     int
+	stream string
+	data   []byte
+}
+
+// httpStream 记录一个SSE流的状态；POST请求的流在所有响应发送后结束，GET请求的流随会话结束
+type httpStream struct {
+	lastEvent int
+	done      bool
+}
+
+// httpSession 实现server.ClientSession，同时保存最近的事件用于断线恢复
+type httpSession struct {
+	id            string
+	client        *sessionClient
+	notifications chan mcpgo.JSONRPCNotification
+	initialized   atomic.Bool
+	done          chan struct{}
+	closeOnce     sync.Once
+	ctx           context.Context    // 会话中请求的上下文，会话结束或过期时取消
+	cancel        context.CancelFunc // 取消仍在进行的请求
+
+	mu           sync.Mutex
+	nextEventID  int
+	events       []httpEvent
+	streams      map[string]*httpStream
+	wake         chan struct{} // 有新事件或流结束时关闭并替换
+	connections  int
+	lastActive   time.Time
+	cancelListen context.CancelFunc // 当前GET流的取消函数
+}
+
+func newHTTPSession(client *sessionClient) *httpSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &httpSession{
+		id:            uuid.New().String(),
+		ctx:           ctx,
+		cancel:        cancel,
+		client:        client,
+		notifications: make(chan mcpgo.JSONRPCNotification, 100),
+		done:          make(chan struct{}),
+		streams:       map[string]*httpStream{standaloneStream: {}},
+		wake:          make(chan struct{}),
+		lastActive:    time.Now(),
+	}
+}
+
+// SessionID 返回会话ID
+func (s *httpSession) SessionID() string {
+	return s.id
+}
+
+// NotificationChannel 返回发送给客户端的通知通道
+func (s *httpSession) NotificationChannel() chan<- mcpgo.JSONRPCNotification {
+	return s.notifications
+}
+
+// Initialize 将会话标记为已初始化
+func (s *httpSession) Initialize() {
+	s.initialized.Store(true)
+}
+
+// Initialized 返回会话是否已初始化
+func (s *httpSession) Initialized() bool {
+	return s.initialized.Load()
+}
+
+// close 结束会话，取消仍在进行的请求并唤醒所有等待中的流
+func (s *httpSession) close() {
+	s.closeOnce.Do(func() {
+		s.cancel()
+		close(s.done)
+		s.mu.Lock()
+		s.broadcastLocked()
+		s.mu.Unlock()
+	})
+}
+
+// touch 更新会话的最近活动时间
+func (s *httpSession) touch() {
+	s.mu.Lock()
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+}
+
+// broadcastLocked 唤醒所有等待新事件的流，调用时必须持有锁
+func (s *httpSession) broadcastLocked() {
+	close(s.wake)
+	s.wake = make(chan struct{})
+}
+
+// openStream 创建一个新的流
+func (s *httpSession) openStream(stream string) {
+	s.mu.Lock()
+	s.streams[stream] = &httpStream{}
+	s.mu.Unlock()
+}
+
+// finishStream 标记流已结束
+func (s *httpSession) finishStream(stream string) {
+	s.mu.Lock()
+	if st, ok := s.streams[stream]; ok {
+		st.done = true
+	}
+	s.broadcastLocked()
+	s.mu.Unlock()
+}
+
+// appendEvent 将消息记录到事件日志并返回事件
+func (s *httpSession) appendEvent(stream string, data []byte) httpEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextEventID++
+	event := httpEvent{id: s.nextEventID, stream: stream, data: data}
+	s.events = append(s.events, event)
+	if st, ok := s.streams[stream]; ok {
+		st.lastEvent = event.id
+	}
+
+	if len(s.events) > httpEventLogSize {
+		s.events = append(s.events[:0:0], s.events[len(s.events)-httpEventLogSize:]...)
+		// 已结束且事件都已被淘汰的流不再需要保留
+		oldest := s.events[0].id
+		for name, st := range s.streams {
+			if st.done && st.lastEvent < oldest {
+				delete(s.streams, name)
+			}
+		}
+	}
+
+	s.broadcastLocked()
+	return event
+}
+
+// lookupEvent 根据事件ID查找所属的流，事件已被淘汰或不存在时返回false
+func (s *httpSession) lookupEvent(id int) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range s.events {
+		if event.id == id {
+			return event.stream, true
+		}
+	}
+	return "", false
+}
+
+// pending 返回流中ID大于after的事件，以及流是否已结束
+func (s *httpSession) pending(stream string, after int) ([]httpEvent, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []httpEvent
+	for _, event := range s.events {
+		if event.stream == stream && event.id > after {
+			events = append(events, event)
+		}
+	}
+	st, ok := s.streams[stream]
+	done := !ok || st.done
+	return events, done, s.wake
+}
+
+// latestEventID 返回当前最新的事件ID
+func (s *httpSession) latestEventID() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nextEventID
+}
+
+// forwardNotifications 将服务器通知写入GET流，没有客户端连接时保留在事件日志中
+func (s *httpSession) forwardNotifications() {
+	for {
+		select {
+		case notification := <-s.notifications:
+			data, err := json.Marshal(notification)
+			if err != nil {
+				log.Printf("序列化通知失败: %v", err)
+				continue
+			}
+			s.appendEvent(standaloneStream, data)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// jsonrpcEnvelope 用于判断JSON-RPC消息的类型
+type jsonrpcEnvelope struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+// isRequest 判断消息是否为需要响应的请求
+func (e jsonrpcEnvelope) isRequest() bool {
+	return e.Method != "" && len(e.ID) > 0 && string(e.ID) != "null"
+}
+
+// ServeHTTP 实现http.Handler接口
+func (h *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != h.path {
+		http.NotFound(w, r)
+		return
+	}
+	if !h.hosts.Valid(r) {
+		http.Error(w, "Forbidden host or origin", http.StatusForbidden)
+		return
+	}
+	// 启用OAuth时先验证访问令牌，未认证的请求不会得到会话是否存在的信息
+	if _, err := h.auth.authenticate(r); err != nil {
+		h.auth.writeError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.handlePost(w, r)
+	case http.MethodGet:
+		h.handleGet(w, r)
+	case http.MethodDelete:
+		h.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePost 处理客户端发送的JSON-RPC消息或批量消息
+func (h *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, httpMaxBodyBytes))
+	if err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, mcpgo.PARSE_ERROR, "读取请求体失败")
+		return
+	}
+
+	messages, batch, err := splitJSONRPC(body)
+	if err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, mcpgo.PARSE_ERROR, "Parse error")
+		return
+	}
+
+	envelopes := make([]jsonrpcEnvelope, len(messages))
+	hasRequest, hasInitialize := false, false
+	for i, message := range messages {
+		if err := json.Unmarshal(message, &envelopes[i]); err != nil {
+			writeJSONRPCError(w, http.StatusBadRequest, mcpgo.INVALID_REQUEST, "Invalid request")
+			return
+		}
+		hasRequest = hasRequest || envelopes[i].isRequest()
+		hasInitialize = hasInitialize || envelopes[i].Method == "initialize"
+	}
+
+	var session *httpSession
+	var grant *Grant
+	if hasInitialize {
+		if len(messages) > 1 {
+			writeJSONRPCError(w, http.StatusBadRequest, mcpgo.INVALID_REQUEST, "initialize请求不能与其他消息批量发送")
+			return
+		}
+		var client *sessionClient
+		client, grant, err = h.auth.open(r)
+		if err != nil {
+			h.auth.writeError(w, err)
+			return
+		}
+		session = newHTTPSession(client)
+		if err := h.server.RegisterSession(session); err != nil {
+			http.Error(w, fmt.Sprintf("注册会话失败: %v", err), http.StatusInternalServerError)
+			return
+		}
+		h.sessions.Store(session.id, session)
+		go session.forwardNotifications()
+		h.janitor.Do(func() { go h.expireSessions() })
+		w.Header().Set(HeaderSessionID, session.id)
+	} else if session, grant = h.lookupSession(w, r); session == nil {
+		return
+	}
+
+	session.touch()
+	// 客户端断开后仍然完成处理，结果保留在事件日志中供重连后获取；会话结束或过期时取消处理
+	ctx := h.server.WithContext(session.ctx, session)
+	ctx = session.client.context(ctx, grant)
+	handle := func(i int) mcpgo.JSONRPCMessage {
+		response := h.server.HandleMessage(ctx, messages[i])
+		if envelopes[i].Method == string(mcpgo.MethodToolsList) && grant != nil {
+			response = filterToolList(response, grant.Scopes)
+		}
+		return response
+	}
+
+	if !hasRequest {
+		for i := range messages {
+			handle(i)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if acceptsEventStream(r) {
+		h.streamResponses(w, r, session, len(messages), handle)
+		return
+	}
+
+	var responses []mcpgo.JSONRPCMessage
+	for i := range messages {
+		if response := handle(i); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		json.NewEncoder(w).Encode(responses)
+	} else if len(responses) > 0 {
+		json.NewEncoder(w).Encode(responses[0])
+	}
+}
+
+// streamResponses 以SSE流返回每条消息的响应，流在所有响应发送后结束
+func (h *StreamableHTTPServer) streamResponses(w http.ResponseWriter, r *http.Request, session *httpSession, count int, handle func(i int) mcpgo.JSONRPCMessage) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	stream := uuid.New().String()
+	session.openStream(stream)
+	setEventStreamHeaders(w)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	connected := true
+	for i := 0; i < count; i++ {
+		response := handle(i)
+		if response == nil {
+			continue
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			log.Printf("序列化响应失败: %v", err)
+			continue
+		}
+		event := session.appendEvent(stream, data)
+		if connected && r.Context().Err() == nil {
+			if err := writeEvent(w, event); err != nil {
+				connected = false
+				continue
+			}
+			flusher.Flush()
+		}
+	}
+	session.finishStream(stream)
+}
+
+// handleGet 打开服务器推送流；携带Last-Event-ID时从断开处继续
+func (h *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		http.Error(w, "Accept必须包含text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+	session, _ := h.lookupSession(w, r)
+	if session == nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	stream, after := standaloneStream, session.latestEventID()
+	if lastEventID := r.Header.Get(headerLastEventID); lastEventID != "" {
+		id, err := strconv.Atoi(lastEventID)
+		if err != nil {
+			http.Error(w, "Last-Event-ID无效", http.StatusBadRequest)
+			return
+		}
+		if resumed, ok := session.lookupEvent(id); ok {
+			stream, after = resumed, id
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	session.mu.Lock()
+	if stream == standaloneStream {
+		// 同一时间只保留一个GET流，避免同一事件被发送两次
+		if session.cancelListen != nil {
+			session.cancelListen()
+		}
+		session.cancelListen = cancel
+	}
+	session.connections++
+	session.mu.Unlock()
+	defer func() {
+		session.mu.Lock()
+		session.connections--
+		session.lastActive = time.Now()
+		session.mu.Unlock()
+	}()
+
+	setEventStreamHeaders(w)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		events, done, wake := session.pending(stream, after)
+		for _, event := range events {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			after = event.id
+		}
+		flusher.Flush()
+		if done {
+			return
+		}
+
+		select {
+		case <-wake:
+		case <-session.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleDelete 结束会话
+func (h *StreamableHTTPServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.lookupSession(w, r)
+	if session == nil {
+		return
+	}
+	h.closeSession(session)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lookupSession 根据请求头查找会话并校验请求的凭据；缺少会话ID时返回400，会话不存在或已结束时返回404
+func (h *StreamableHTTPServer) lookupSession(w http.ResponseWriter, r *http.Request) (*httpSession, *Grant) {
+	id := r.Header.Get(HeaderSessionID)
+	if id == "" {
+		writeJSONRPCError(w, http.StatusBadRequest, mcpgo.INVALID_REQUEST, "缺少"+HeaderSessionID+"请求头")
+		return nil, nil
+	}
+	value, ok := h.sessions.Load(id)
+	if !ok {
+		writeJSONRPCError(w, http.StatusNotFound, mcpgo.INVALID_REQUEST, "会话不存在或已过期，请重新初始化")
+		return nil, nil
+	}
+	session := value.(*httpSession)
+	grant, err := session.client.verify(r)
+	if err != nil {
+		h.auth.writeError(w, err)
+		return nil, nil
+	}
+	return session, grant
+}
+
+// filterToolList 从tools/list的结果中去掉访问令牌无权调用的工具
+func filterToolList(response mcpgo.JSONRPCMessage, scopes tools.Scopes) mcpgo.JSONRPCMessage {
+	resp, ok := response.(mcpgo.JSONRPCResponse)
+	if !ok {
+		return response
+	}
+	if result, ok := resp.Result.(mcpgo.ListToolsResult); ok {
+		result.Tools = tools.FilterTools(result.Tools, scopes)
+		resp.Result = result
+	}
+	return resp
+}
+
+// filterToolListJSON 与filterToolList相同，但处理序列化后的响应，不是tools/list结果时原样返回
+func filterToolListJSON(data []byte, scopes tools.Scopes) []byte {
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(data, &resp); err != nil || resp["result"] == nil {
+		return data
+	}
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(resp["result"], &result); err != nil || result["tools"] == nil {
+		return data
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(result["tools"], &list); err != nil {
+		return data
+	}
+
+	kept := make([]json.RawMessage, 0, len(list))
+	for _, raw := range list {
+		var tool struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(raw, &tool); err != nil {
+			return data
+		}
+		if info, ok := tools.LookupTool(tool.Name); !ok || scopes.Allows(info) {
+			kept = append(kept, raw)
+		}
+	}
+
+	result["tools"], _ = json.Marshal(kept)
+	resp["result"], _ = json.Marshal(result)
+	filtered, err := json.Marshal(resp)
+	if err != nil {
+		return data
+	}
+	if bytes.HasSuffix(data, []byte("\n")) {
+		filtered = append(filtered, '\n')
+	}
+	return filtered
+}
+
+// closeSession 注销并关闭会话
+func (h *StreamableHTTPServer) closeSession(session *httpSession) {
+	h.sessions.Delete(session.id)
+	h.server.UnregisterSession(session.id)
+	session.close()
+	session.client.close()
+}
+
+// expireSessions 定期关闭长时间没有活动的会话
+func (h *StreamableHTTPServer) expireSessions() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		h.closeIdleSessions(httpSessionIdleTimeout)
+	}
+}
+
+// closeIdleSessions 关闭没有连接且空闲超过timeout的会话
+func (h *StreamableHTTPServer) closeIdleSessions(timeout time.Duration) {
+	h.sessions.Range(func(_, value any) bool {
+		session := value.(*httpSession)
+		session.mu.Lock()
+		idle := session.connections == 0 && time.Since(session.lastActive) > timeout
+		session.mu.Unlock()
+		if idle {
+			h.closeSession(session)
+		}
+		return true
+	})
+}
+
+// splitJSONRPC 将请求体拆分为单条消息，并返回是否为批量请求
+func splitJSONRPC(body []byte) ([]json.RawMessage, bool, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var messages []json.RawMessage
+		if err := json.Unmarshal(body, &messages); err != nil {
+			return nil, true, err
+		}
+		if len(messages) == 0 {
+			return nil, true, fmt.Errorf("批量请求不能为空")
+		}
+		return messages, true, nil
+	}
+
+	var message json.RawMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		return nil, false, err
+	}
+	return []json.RawMessage{message}, false, nil
+}
+
+// acceptsEventStream 判断客户端是否接受SSE响应
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// HostValidator 校验Host和Origin请求头，防止DNS重绑定攻击：恶意网页把自己的域名解析到本机地址后，
+// 浏览器发出的请求Host和Origin都是攻击者的域名，两者相同也不能说明请求可信
+type HostValidator struct {
+	allowed map[string]bool
+}
+
+// NewHostValidator 创建Host校验器，hosts为允许的主机名（不含端口），本机地址总是允许。
+// hosts为空时还允许IP地址形式的Host，便于通过IP访问；通过域名访问时必须把域名加入hosts
+func NewHostValidator(hosts []string) *HostValidator {
+	allowed := map[string]bool{}
+	for _, host := range hosts {
+		allowed[strings.ToLower(strings.Trim(host, "[]"))] = true
+	}
+	return &HostValidator{allowed: allowed}
+}
+
+// allowedHost 判断主机名是否可信
+func (v *HostValidator) allowedHost(hostname string) bool {
+	hostname = strings.ToLower(hostname)
+	switch hostname {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	if v == nil || len(v.allowed) == 0 {
+		// DNS重绑定需要攻击者控制的域名，IP地址形式的Host不受影响
+		return net.ParseIP(hostname) != nil
+	}
+	return v.allowed[hostname]
+}
+
+// Valid 校验请求的Host和Origin：Host必须是可信主机名；携带Origin时，Origin必须是可信主机名或与Host相同
+func (v *HostValidator) Valid(r *http.Request) bool {
+	host := r.Host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	if !v.allowedHost(strings.Trim(host, "[]")) {
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return u.Host == r.Host || v.allowedHost(u.Hostname())
+}
+
+// setEventStreamHeaders 设置SSE响应头
+func setEventStreamHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+}
+
+// writeEvent 写入一个SSE事件
+func writeEvent(w io.Writer, event httpEvent) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: message\ndata: %s\n\n", event.id, event.data)
+	return err
+}
+
+// writeJSONRPCError 返回不带ID的JSON-RPC错误
+func writeJSONRPCError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(mcpgo.NewJSONRPCError(nil, code, message, nil))
+}
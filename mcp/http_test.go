@@ -0,0 +1,327 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newHTTPTestServer 启动不要求认证的Streamable HTTP服务器
+func newHTTPTestServer(t *testing.T, hosts *HostValidator) (*StreamableHTTPServer, *httptest.Server) {
+	t.Helper()
+	h := NewStreamableHTTPServer(server.NewMCPServer("test", "1.0.0"), "/mcp", nil, hosts)
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return h, ts
+}
+
+// doMCP 向/mcp发送请求，header中的Host会设置为请求的Host
+func doMCP(t *testing.T, ts *httptest.Server, method, body string, header map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+"/mcp", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for k, v := range header {
+		if k == "Host" {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// initializeSession 初始化会话并返回会话ID
+func initializeSession(t *testing.T, ts *httptest.Server) string {
+	t.Helper()
+	resp := doMCP(t, ts, http.MethodPost, initializeRequest, map[string]string{"Accept": "application/json"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("initialize status = %d", resp.StatusCode)
+	}
+	id := resp.Header.Get(HeaderSessionID)
+	if id == "" {
+		t.Fatal("initialize没有返回会话ID")
+	}
+	return id
+}
+
+// sseEvent 是从SSE流中读取的一个事件
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readSSEEvent 从SSE流中读取下一个事件
+func readSSEEvent(r *bufio.Reader) (sseEvent, error) {
+	var e sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return e, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			e.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			e.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		case line == "" && e.event != "":
+			return e, nil
+		}
+	}
+}
+
+// openStream 以GET请求打开推送流，返回读取事件的Reader
+func openStream(t *testing.T, ctx context.Context, ts *httptest.Server, sessionID, lastEventID string) *bufio.Reader {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/mcp", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(HeaderSessionID, sessionID)
+	if lastEventID != "" {
+		req.Header.Set(headerLastEventID, lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET status = %d", resp.StatusCode)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// serverSession 返回服务器中的会话
+func serverSession(t *testing.T, h *StreamableHTTPServer, id string) *httpSession {
+	t.Helper()
+	value, ok := h.sessions.Load(id)
+	if !ok {
+		t.Fatalf("会话%s不存在", id)
+	}
+	return value.(*httpSession)
+}
+
+func TestStreamableHTTPSession(t *testing.T) {
+	_, ts := newHTTPTestServer(t, nil)
+	sessionID := initializeSession(t, ts)
+	ping := `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+
+	tests := []struct {
+		name       string
+		sessionID  string
+		wantStatus int
+	}{
+		{"有效会话", sessionID, http.StatusOK},
+		{"缺少会话ID", "", http.StatusBadRequest},
+		{"未知会话ID", "00000000-0000-0000-0000-000000000000", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := map[string]string{"Accept": "application/json"}
+			if tt.sessionID != "" {
+				header[HeaderSessionID] = tt.sessionID
+			}
+			resp := doMCP(t, ts, http.MethodPost, ping, header)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+
+	t.Run("initialize不能批量发送", func(t *testing.T) {
+		resp := doMCP(t, ts, http.MethodPost, "["+initializeRequest+","+ping+"]", nil)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", resp.StatusCode)
+		}
+	})
+}
+
+func TestStreamableHTTPDelete(t *testing.T) {
+	_, ts := newHTTPTestServer(t, nil)
+	sessionID := initializeSession(t, ts)
+	header := map[string]string{HeaderSessionID: sessionID}
+
+	if resp := doMCP(t, ts, http.MethodDelete, "", header); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d, want 204", resp.StatusCode)
+	}
+	// 会话结束后的请求和重复的DELETE都返回404
+	if resp := doMCP(t, ts, http.MethodPost, `{"jsonrpc":"2.0","id":2,"method":"ping"}`, header); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("POST status = %d, want 404", resp.StatusCode)
+	}
+	if resp := doMCP(t, ts, http.MethodDelete, "", header); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("重复DELETE status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestStreamableHTTPIdleExpiry(t *testing.T) {
+	h, ts := newHTTPTestServer(t, nil)
+	idle := initializeSession(t, ts)
+	active := initializeSession(t, ts)
+
+	session := serverSession(t, h, idle)
+	session.mu.Lock()
+	session.lastActive = time.Now().Add(-2 * httpSessionIdleTimeout)
+	session.mu.Unlock()
+	h.closeIdleSessions(httpSessionIdleTimeout)
+
+	ping := `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+	if resp := doMCP(t, ts, http.MethodPost, ping, map[string]string{HeaderSessionID: idle, "Accept": "application/json"}); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("过期会话 status = %d, want 404", resp.StatusCode)
+	}
+	select {
+	case <-session.done:
+	default:
+		t.Fatal("过期会话应被关闭")
+	}
+	if resp := doMCP(t, ts, http.MethodPost, ping, map[string]string{HeaderSessionID: active, "Accept": "application/json"}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("活动会话 status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestStreamableHTTPResumePostStream(t *testing.T) {
+	_, ts := newHTTPTestServer(t, nil)
+	sessionID := initializeSession(t, ts)
+
+	// 以SSE流返回两条响应
+	batch := `[{"jsonrpc":"2.0","id":2,"method":"ping"},{"jsonrpc":"2.0","id":3,"method":"ping"}]`
+	resp := doMCP(t, ts, http.MethodPost, batch, map[string]string{HeaderSessionID: sessionID})
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	body := bufio.NewReader(resp.Body)
+	first, err := readSSEEvent(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 从第一条响应之后恢复，只补发第二条，流已结束时连接随之关闭
+	events := openStream(t, context.Background(), ts, sessionID, first.id)
+	event, err := readSSEEvent(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(event.data, `"id":3`) {
+		t.Fatalf("补发的事件 = %+v, want id 3的响应", event)
+	}
+	if _, err := readSSEEvent(events); err != io.EOF {
+		t.Fatalf("补发完成后应结束流, got %v", err)
+	}
+}
+
+func TestStreamableHTTPResumeStandaloneStream(t *testing.T) {
+	h, ts := newHTTPTestServer(t, nil)
+	sessionID := initializeSession(t, ts)
+	session := serverSession(t, h, sessionID)
+
+	for i := 1; i <= 3; i++ {
+		session.appendEvent(standaloneStream, []byte(fmt.Sprintf(`{"n":%d}`, i)))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 从事件日志中补发Last-Event-ID之后的事件
+	events := openStream(t, ctx, ts, sessionID, "1")
+	for _, want := range []string{`{"n":2}`, `{"n":3}`} {
+		event, err := readSSEEvent(events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.data != want {
+			t.Fatalf("事件 = %+v, want %s", event, want)
+		}
+	}
+	cancel()
+
+	// 超过事件日志容量后最早的事件被淘汰，用已淘汰的ID重连时只接收新事件
+	for i := 0; i < httpEventLogSize; i++ {
+		session.appendEvent(standaloneStream, []byte(`{"old":true}`))
+	}
+	if _, ok := session.lookupEvent(1); ok {
+		t.Fatal("事件1应已被淘汰")
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events = openStream(t, ctx, ts, sessionID, "1")
+	session.appendEvent(standaloneStream, []byte(`{"new":true}`))
+	event, err := readSSEEvent(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.data != `{"new":true}` {
+		t.Fatalf("事件 = %+v, want 重连后的新事件", event)
+	}
+
+	if resp := doMCP(t, ts, http.MethodGet, "", map[string]string{HeaderSessionID: sessionID, headerLastEventID: "abc", "Accept": "text/event-stream"}); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("无效的Last-Event-ID status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestStreamableHTTPHostValidation(t *testing.T) {
+	_, ts := newHTTPTestServer(t, NewHostValidator([]string{"mcp.example.com"}))
+
+	tests := []struct {
+		name       string
+		header     map[string]string
+		wantStatus int
+	}{
+		{"本机地址", nil, http.StatusOK},
+		{"允许的域名", map[string]string{"Host": "mcp.example.com"}, http.StatusOK},
+		{"其他域名", map[string]string{"Host": "evil.example.com"}, http.StatusForbidden},
+		{"Origin为其他域名", map[string]string{"Origin": "http://evil.example.com"}, http.StatusForbidden},
+		{"Origin为允许的域名", map[string]string{"Origin": "https://mcp.example.com"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := map[string]string{"Accept": "application/json"}
+			for k, v := range tt.header {
+				header[k] = v
+			}
+			resp := doMCP(t, ts, http.MethodPost, initializeRequest, header)
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHostValidatorDefault(t *testing.T) {
+	var v *HostValidator
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"localhost:8080", true},
+		{"[::1]:8080", true},
+		{"192.168.1.10:8080", true},
+		{"rebind.example.com", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		r.Host = tt.host
+		if got := v.Valid(r); got != tt.want {
+			t.Fatalf("Valid(%s) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
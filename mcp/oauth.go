@@ -0,0 +1,367 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gitcode-org-com/gitcode-mcp/config"
+	"github.com/gitcode-org-com/gitcode-mcp/mcp/tools"
+)
+
+const (
+	// protectedResourcePath 受保护资源元数据（RFC 9728）的路径
+	protectedResourcePath = "/.well-known/oauth-protected-resource"
+	// oauthGrantCacheTTL 令牌内省结果的最长缓存时间
+	oauthGrantCacheTTL = time.Minute
+	// oauthGrantCacheSize 超过该数量时清理过期的内省结果
+	oauthGrantCacheSize = 1000
+)
+
+var (
+	// ErrMissingAccessToken 请求未携带OAuth访问令牌
+	ErrMissingAccessToken = errors.New("缺少访问令牌，请在Authorization请求头中提供")
+	// ErrInvalidToken 访问令牌无效、已过期或不是为本服务器签发的
+	ErrInvalidToken = errors.New("访问令牌无效或已过期")
+)
+
+// OAuthOptions OAuth资源服务器配置
+type OAuthOptions struct {
+	Issuer           string // 授权服务器的issuer
+	Resource         string // 本服务器的资源标识，访问令牌的aud必须包含该值
+	IntrospectionURL string // 令牌内省端点，为空时从授权服务器元数据中获取
+	ClientID         string // 调用内省端点使用的客户端ID
+	ClientSecret     string // 调用内省端点使用的客户端密钥
+}
+
+// OAuthOptionsFromConfig 从全局配置读取OAuth配置，没有启用OAuth时返回nil
+func OAuthOptionsFromConfig() *OAuthOptions {
+	if config.GlobalConfig.MCPOAuthIssuer == "" {
+		return nil
+	}
+	return &OAuthOptions{
+		Issuer:           config.GlobalConfig.MCPOAuthIssuer,
+		Resource:         config.GlobalConfig.MCPOAuthResource,
+		IntrospectionURL: config.GlobalConfig.MCPOAuthIntrospectionURL,
+		ClientID:         config.GlobalConfig.MCPOAuthClientID,
+		ClientSecret:     config.GlobalConfig.MCPOAuthClientSecret,
+	}
+}
+
+// Grant 表示访问令牌经验证后的授权信息
+type Grant struct {
+	Subject   string
+	ClientID  string
+	Scopes    tools.Scopes
+	ExpiresAt time.Time // 为零值时表示授权服务器没有返回过期时间
+}
+
+// cachedGrant 缓存的令牌内省结果
+type cachedGrant struct {
+	grant *Grant
+	until time.Time
+}
+
+// OAuthValidator 按MCP授权规范作为OAuth 2.1资源服务器：发布受保护资源元数据，
+// 通过授权服务器的令牌内省端点（RFC 7662）验证访问令牌
+type OAuthValidator struct {
+	options    OAuthOptions
+	httpClient *http.Client
+
+	mu               sync.Mutex
+	introspectionURL string
+	grants           map[[sha256.Size]byte]cachedGrant
+}
+
+// NewOAuthValidator 创建访问令牌验证器
+func NewOAuthValidator(options OAuthOptions) *OAuthValidator {
+	return &OAuthValidator{
+		options:          options,
+		httpClient:       &http.Client{Timeout: 10 * time.Second},
+		introspectionURL: options.IntrospectionURL,
+		grants:           map[[sha256.Size]byte]cachedGrant{},
+	}
+}
+
+// introspectionResponse 令牌内省端点的响应
+type introspectionResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope"`
+	ClientID  string   `json:"client_id"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	Issuer    string   `json:"iss"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+}
+
+// audience 令牌的受众，可以是单个字符串或字符串数组
+type audience []string
+
+// UnmarshalJSON 实现json.Unmarshaler接口
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// contains 判断受众是否包含资源标识，忽略末尾的/
+func (a audience) contains(resource string) bool {
+	resource = strings.TrimSuffix(resource, "/")
+	for _, aud := range a {
+		if strings.TrimSuffix(aud, "/") == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 验证访问令牌，返回令牌的授权信息。令牌必须有效、未过期、由配置的授权服务器签发，
+// 并且受众包含本服务器的资源标识
+func (v *OAuthValidator) Validate(ctx context.Context, token string) (*Grant, error) {
+	if token == "" {
+		return nil, ErrMissingAccessToken
+	}
+
+	key := sha256.Sum256([]byte(token))
+	now := time.Now()
+	v.mu.Lock()
+	if cached, ok := v.grants[key]; ok && now.Before(cached.until) {
+		v.mu.Unlock()
+		return cached.grant, nil
+	}
+	v.mu.Unlock()
+
+	result, err := v.introspect(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !result.Active:
+		return nil, ErrInvalidToken
+	case result.ExpiresAt > 0 && !now.Before(time.Unix(result.ExpiresAt, 0)):
+		return nil, ErrInvalidToken
+	case result.NotBefore > 0 && now.Before(time.Unix(result.NotBefore, 0)):
+		return nil, ErrInvalidToken
+	case result.Issuer != "" && strings.TrimSuffix(result.Issuer, "/") != strings.TrimSuffix(v.options.Issuer, "/"):
+		return nil, fmt.Errorf("%w: 令牌不是由%s签发的", ErrInvalidToken, v.options.Issuer)
+	case !result.Audience.contains(v.options.Resource):
+		return nil, fmt.Errorf("%w: 令牌的受众不包含%s", ErrInvalidToken, v.options.Resource)
+	}
+
+	grant := &Grant{
+		Subject:  result.Subject,
+		ClientID: result.ClientID,
+		Scopes:   tools.ParseScopes(result.Scope),
+	}
+	if grant.Subject == "" {
+		grant.Subject = result.ClientID
+	}
+	until := now.Add(oauthGrantCacheTTL)
+	if result.ExpiresAt > 0 {
+		grant.ExpiresAt = time.Unix(result.ExpiresAt, 0)
+		if grant.ExpiresAt.Before(until) {
+			until = grant.ExpiresAt
+		}
+	}
+
+	v.mu.Lock()
+	if len(v.grants) >= oauthGrantCacheSize {
+		for k, cached := range v.grants {
+			if !now.Before(cached.until) {
+				delete(v.grants, k)
+			}
+		}
+	}
+	v.grants[key] = cachedGrant{grant: grant, until: until}
+	v.mu.Unlock()
+
+	return grant, nil
+}
+
+// introspect 调用授权服务器的令牌内省端点
+func (v *OAuthValidator) introspect(ctx context.Context, token string) (*introspectionResponse, error) {
+	endpoint, err := v.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("创建令牌内省请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if v.options.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(v.options.ClientID), url.QueryEscape(v.options.ClientSecret))
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("令牌内省请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("令牌内省失败: 授权服务器返回HTTP %d", resp.StatusCode)
+	}
+
+	var result introspectionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析令牌内省结果失败: %w", err)
+	}
+	return &result, nil
+}
+
+// endpoint 返回令牌内省端点，没有配置时从授权服务器元数据（RFC 8414）中获取
+func (v *OAuthValidator) endpoint(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	endpoint := v.introspectionURL
+	v.mu.Unlock()
+	if endpoint != "" {
+		return endpoint, nil
+	}
+
+	issuer, err := url.Parse(v.options.Issuer)
+	if err != nil {
+		return "", fmt.Errorf("解析issuer失败: %w", err)
+	}
+	path := strings.TrimSuffix(issuer.Path, "/")
+	candidates := []string{
+		issuer.Scheme + "://" + issuer.Host + "/.well-known/oauth-authorization-server" + path,
+		issuer.Scheme + "://" + issuer.Host + path + "/.well-known/openid-configuration",
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		metadata, err := v.fetchMetadata(ctx, candidate)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(v.options.Issuer, "/") {
+			lastErr = fmt.Errorf("授权服务器元数据中的issuer与配置不一致: %s", metadata.Issuer)
+			continue
+		}
+		if metadata.IntrospectionEndpoint == "" {
+			return "", errors.New("授权服务器没有提供令牌内省端点，请设置MCP_OAUTH_INTROSPECTION_URL")
+		}
+
+		v.mu.Lock()
+		v.introspectionURL = metadata.IntrospectionEndpoint
+		v.mu.Unlock()
+		return metadata.IntrospectionEndpoint, nil
+	}
+	return "", fmt.Errorf("获取授权服务器元数据失败: %w", lastErr)
+}
+
+// authorizationServerMetadata 授权服务器元数据中用到的字段
+type authorizationServerMetadata struct {
+	Issuer                string `json:"issuer"`
+	IntrospectionEndpoint string `json:"introspection_endpoint"`
+}
+
+// fetchMetadata 获取授权服务器元数据
+func (v *OAuthValidator) fetchMetadata(ctx context.Context, endpoint string) (*authorizationServerMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("%s返回HTTP %d", endpoint, resp.StatusCode)
+	}
+
+	var metadata authorizationServerMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("解析%s失败: %w", endpoint, err)
+	}
+	return &metadata, nil
+}
+
+// MetadataPath 返回受保护资源元数据的路径，资源标识包含路径时附加在末尾，如/.well-known/oauth-protected-resource/mcp
+func (v *OAuthValidator) MetadataPath() string {
+	u, err := url.Parse(v.options.Resource)
+	if err != nil {
+		return protectedResourcePath
+	}
+	return protectedResourcePath + strings.TrimSuffix(u.Path, "/")
+}
+
+// MetadataURL 返回受保护资源元数据的完整URL
+func (v *OAuthValidator) MetadataURL() string {
+	u, err := url.Parse(v.options.Resource)
+	if err != nil {
+		return v.MetadataPath()
+	}
+	return u.Scheme + "://" + u.Host + v.MetadataPath()
+}
+
+// ServeHTTP 发布受保护资源元数据（RFC 9728），客户端据此找到授权服务器和可申请的scope
+func (v *OAuthValidator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"resource":                 v.options.Resource,
+		"authorization_servers":    []string{v.options.Issuer},
+		"scopes_supported":         tools.AllScopes(),
+		"bearer_methods_supported": []string{"header"},
+		"resource_name":            "GitCode MCP",
+	})
+}
+
+// challenge 返回401/403响应的WWW-Authenticate头，指向受保护资源元数据
+func (v *OAuthValidator) challenge(err error) string {
+	value := fmt.Sprintf(`Bearer resource_metadata=%q`, v.MetadataURL())
+	switch {
+	case errors.Is(err, ErrInvalidToken):
+		value += `, error="invalid_token"`
+	case errors.Is(err, tools.ErrInsufficientScope):
+		value += `, error="insufficient_scope"`
+	}
+	return value
+}
+
+// Handler 在next之外提供受保护资源元数据端点
+func (v *OAuthValidator) Handler(next http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(protectedResourcePath, v)
+	if path := v.MetadataPath(); path != protectedResourcePath {
+		mux.Handle(path, v)
+	}
+	mux.Handle("/", next)
+	return mux
+}
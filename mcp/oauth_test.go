@@ -0,0 +1,310 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/gitcode-org-com/gitcode-mcp/mcp/tools"
+)
+
+const testResource = "https://mcp.example.com/mcp"
+
+// fakeAuthServer 模拟授权服务器：发布元数据并按tokens返回令牌内省结果
+type fakeAuthServer struct {
+	*httptest.Server
+	tokens         map[string]map[string]interface{}
+	introspections atomic.Int32
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	t.Helper()
+	as := &fakeAuthServer{tokens: map[string]map[string]interface{}{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 as.URL,
+			"introspection_endpoint": as.URL + "/introspect",
+		})
+	})
+	mux.HandleFunc("/introspect", func(w http.ResponseWriter, r *http.Request) {
+		as.introspections.Add(1)
+		if id, secret, ok := r.BasicAuth(); !ok || id != "mcp" || secret != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		result, ok := as.tokens[r.FormValue("token")]
+		if !ok {
+			result = map[string]interface{}{"active": false}
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	as.Server = httptest.NewServer(mux)
+	t.Cleanup(as.Close)
+	return as
+}
+
+// token 登记一个有效的访问令牌，overrides覆盖默认的内省结果字段
+func (as *fakeAuthServer) token(name string, overrides map[string]interface{}) {
+	result := map[string]interface{}{
+		"active":    true,
+		"sub":       "alice",
+		"client_id": "client",
+		"scope":     "issues:read",
+		"iss":       as.URL,
+		"aud":       testResource,
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		result[k] = v
+	}
+	as.tokens[name] = result
+}
+
+func (as *fakeAuthServer) validator() *OAuthValidator {
+	return NewOAuthValidator(OAuthOptions{
+		Issuer:       as.URL,
+		Resource:     testResource,
+		ClientID:     "mcp",
+		ClientSecret: "secret",
+	})
+}
+
+func TestOAuthValidatorIntrospection(t *testing.T) {
+	as := newFakeAuthServer(t)
+	as.token("valid", nil)
+	as.token("audience-list", map[string]interface{}{"aud": []string{"other", testResource + "/"}})
+	as.token("inactive", map[string]interface{}{"active": false})
+	as.token("wrong-audience", map[string]interface{}{"aud": "https://other.example.com/mcp"})
+	as.token("expired", map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()})
+	as.token("not-yet-valid", map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()})
+	as.token("wrong-issuer", map[string]interface{}{"iss": "https://evil.example.com"})
+
+	tests := []struct {
+		token   string
+		wantErr error
+	}{
+		{"valid", nil},
+		{"audience-list", nil},
+		{"", ErrMissingAccessToken},
+		{"unknown", ErrInvalidToken},
+		{"inactive", ErrInvalidToken},
+		{"wrong-audience", ErrInvalidToken},
+		{"expired", ErrInvalidToken},
+		{"not-yet-valid", ErrInvalidToken},
+		{"wrong-issuer", ErrInvalidToken},
+	}
+	v := as.validator()
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			grant, err := v.Validate(context.Background(), tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if grant.Subject != "alice" || !grant.Scopes["issues:read"] {
+				t.Fatalf("Validate() = %+v", grant)
+			}
+		})
+	}
+}
+
+func TestOAuthValidatorCachesGrants(t *testing.T) {
+	as := newFakeAuthServer(t)
+	as.token("valid", nil)
+	v := as.validator()
+
+	for i := 0; i < 3; i++ {
+		if _, err := v.Validate(context.Background(), "valid"); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+	}
+	if n := as.introspections.Load(); n != 1 {
+		t.Fatalf("内省请求次数 = %d, want 1", n)
+	}
+}
+
+func TestOAuthValidatorIntrospectionFailure(t *testing.T) {
+	as := newFakeAuthServer(t)
+	as.token("valid", nil)
+	v := NewOAuthValidator(OAuthOptions{Issuer: as.URL, Resource: testResource, ClientID: "mcp", ClientSecret: "wrong"})
+
+	_, err := v.Validate(context.Background(), "valid")
+	if err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("授权服务器拒绝内省请求时应返回服务错误, got %v", err)
+	}
+	// 授权服务器不可用时不要求客户端重新授权
+	rec := httptest.NewRecorder()
+	NewSessionAuth(false, v).writeError(rec, err)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+}
+
+// newOAuthTestServer 启动启用OAuth的Streamable HTTP服务器
+func newOAuthTestServer(t *testing.T, v *OAuthValidator) *httptest.Server {
+	t.Helper()
+	s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true))
+	tools.RegisterAllTools(s, nil)
+	handler := withOAuthMetadata(NewStreamableHTTPServer(s, "/mcp", NewSessionAuth(true, v), nil), v)
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// postMCP 向/mcp发送JSON-RPC消息
+func postMCP(t *testing.T, ts *httptest.Server, token, sessionID, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if sessionID != "" {
+		req.Header.Set(HeaderSessionID, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+const initializeRequest = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`
+
+func TestOAuthUnauthorizedChallenge(t *testing.T) {
+	as := newFakeAuthServer(t)
+	as.token("wrong-audience", map[string]interface{}{"aud": "https://other.example.com/mcp"})
+	ts := newOAuthTestServer(t, as.validator())
+
+	tests := []struct {
+		name      string
+		token     string
+		wantError string
+	}{
+		{"缺少令牌", "", ""},
+		{"受众错误", "wrong-audience", `error="invalid_token"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := postMCP(t, ts, tt.token, "", initializeRequest)
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", resp.StatusCode)
+			}
+			challenge := resp.Header.Get("WWW-Authenticate")
+			if !strings.Contains(challenge, `resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource/mcp"`) {
+				t.Fatalf("WWW-Authenticate = %q", challenge)
+			}
+			if !strings.Contains(challenge, tt.wantError) {
+				t.Fatalf("WWW-Authenticate = %q, want %s", challenge, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestOAuthInsufficientScope(t *testing.T) {
+	as := newFakeAuthServer(t)
+	as.token("reader", map[string]interface{}{"scope": "issues:read"})
+	ts := newOAuthTestServer(t, as.validator())
+
+	resp := postMCP(t, ts, "reader", "", initializeRequest)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("initialize status = %d", resp.StatusCode)
+	}
+	sessionID := resp.Header.Get(HeaderSessionID)
+
+	// tools/list只返回令牌有权调用的工具
+	resp = postMCP(t, ts, "reader", sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	var list struct {
+		Result struct {
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Result.Tools) == 0 {
+		t.Fatal("tools/list没有返回工具")
+	}
+	for _, tool := range list.Result.Tools {
+		info, ok := tools.LookupTool(tool.Name)
+		if !ok || info.Group != tools.GroupIssues || !info.ReadOnly {
+			t.Fatalf("issues:read令牌不应看到工具%s", tool.Name)
+		}
+	}
+
+	// 调用需要write scope的工具返回错误
+	resp = postMCP(t, ts, "reader", sessionID, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"create_issue","arguments":{"owner":"o","repo":"r","title":"t"}}}`)
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), tools.ErrInsufficientScope.Error()) || !strings.Contains(string(body), "issues:write") {
+		t.Fatalf("create_issue response = %s", body)
+	}
+
+	rec := httptest.NewRecorder()
+	NewSessionAuth(true, as.validator()).writeError(rec, tools.ErrInsufficientScope)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`) {
+		t.Fatalf("status = %d, WWW-Authenticate = %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestOAuthProtectedResourceMetadata(t *testing.T) {
+	as := newFakeAuthServer(t)
+	ts := newOAuthTestServer(t, as.validator())
+
+	for _, path := range []string{"/.well-known/oauth-protected-resource", "/.well-known/oauth-protected-resource/mcp"} {
+		t.Run(path, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d", resp.StatusCode)
+			}
+
+			var metadata struct {
+				Resource             string   `json:"resource"`
+				AuthorizationServers []string `json:"authorization_servers"`
+				ScopesSupported      []string `json:"scopes_supported"`
+				BearerMethods        []string `json:"bearer_methods_supported"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+				t.Fatal(err)
+			}
+			if metadata.Resource != testResource {
+				t.Fatalf("resource = %q", metadata.Resource)
+			}
+			if len(metadata.AuthorizationServers) != 1 || metadata.AuthorizationServers[0] != as.URL {
+				t.Fatalf("authorization_servers = %v", metadata.AuthorizationServers)
+			}
+			scopes := strings.Join(metadata.ScopesSupported, " ")
+			if !strings.Contains(scopes, "issues:write") || strings.Contains(scopes, "search:write") {
+				t.Fatalf("scopes_supported = %v", metadata.ScopesSupported)
+			}
+			if len(metadata.BearerMethods) != 1 || metadata.BearerMethods[0] != "header" {
+				t.Fatalf("bearer_methods_supported = %v", metadata.BearerMethods)
+			}
+		})
+	}
+}
@@ -20,6 +20,11 @@ type MCPServerOptions struct {
 	Version      string
 	Transport    string
 	ServerPort   int
+	HTTPPath     string            // Streamable HTTP端点路径
+	AllowedHosts []string          // SSE/HTTP模式下允许的Host和Origin主机名
+	OAuth        *OAuthOptions     // SSE/HTTP模式的OAuth配置，为nil时不要求访问令牌
+	ToolFilter   *tools.ToolFilter // 工具过滤条件，为nil时注册全部工具
+	RepoPolicy   string            // 仓库访问策略文件路径，为空时不限制访问的仓库
 	TokenManager TokenManager
 }
 
@@ -30,25 +35,66 @@ type TokenManager interface {
 
 // DefaultMCPOptions 创建默认的MCP服务器选项
 func DefaultMCPOptions() MCPServerOptions {
-	return MCPServerOptions{
-		Name:       "GitCode MCP",
-		Version:    "1.0.0",
-		Transport:  config.GlobalConfig.MCPTransport,
-		ServerPort: config.GlobalConfig.MCPSSEPort,
+	options := MCPServerOptions{
+		Name:         "GitCode MCP",
+		Version:      "1.0.0",
+		Transport:    config.GlobalConfig.MCPTransport,
+		ServerPort:   config.GlobalConfig.MCPSSEPort,
+		HTTPPath:     config.GlobalConfig.MCPHTTPPath,
+		AllowedHosts: config.GlobalConfig.MCPAllowedHosts,
+		OAuth:        OAuthOptionsFromConfig(),
+		ToolFilter:   tools.ToolFilterFromConfig(),
+		RepoPolicy:   config.GlobalConfig.RepoPolicyFile,
 	}
+	if strings.EqualFold(options.Transport, "http") {
+		options.ServerPort = config.GlobalConfig.MCPHTTPPort
+	}
+	return options
+}
+
+// token 返回服务器的默认令牌
+func (o MCPServerOptions) token() string {
+	if o.TokenManager != nil {
+		if token := o.TokenManager.GetToken(); token != "" {
+			return token
+		}
+	}
+	return config.GlobalConfig.GitCodeToken
+}
+
+// multiUser 判断传输方式是否支持多个连接各自提供令牌
+func (o MCPServerOptions) multiUser() bool {
+	transport := strings.ToLower(o.Transport)
+	return transport == "sse" || transport == "http"
 }
 
 // NewMCPServer 创建并初始化MCP服务器
 func NewMCPServer(options MCPServerOptions) (*server.MCPServer, error) {
-	// 创建GitCode API客户端
-	token := ""
-	if options.TokenManager != nil {
-		token = options.TokenManager.GetToken()
+	// 创建GitCode API客户端；SSE/HTTP模式下没有配置令牌时，由每个连接提供各自的令牌
+	var apiClient *api.GitCodeAPI
+	if token := options.token(); token != "" || !options.multiUser() {
+		client, err := api.NewGitCodeAPI(token)
+		if err != nil {
+			return nil, fmt.Errorf("创建API客户端失败: %w", err)
+		}
+		apiClient = client
+	} else {
+		header := "Authorization"
+		if options.OAuth != nil {
+			header = HeaderGitCodeToken
+		}
+		log.Printf("未配置GitCode令牌，每个连接需要在%s请求头中提供令牌\n", header)
 	}
-	
-	apiClient, err := api.NewGitCodeAPI(token)
-	if err != nil {
-		return nil, fmt.Errorf("创建API客户端失败: %w", err)
+
+	// 加载仓库访问策略
+	var policy *api.RepoPolicy
+	if options.RepoPolicy != "" {
+		loaded, err := api.LoadRepoPolicy(options.RepoPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("加载仓库访问策略失败: %w", err)
+		}
+		policy = loaded
+		log.Printf("已加载仓库访问策略: %s\n", options.RepoPolicy)
 	}
 
 	// 创建MCP服务器
@@ -57,8 +103,8 @@ func NewMCPServer(options MCPServerOptions) (*server.MCPServer, error) {
 		options.Version,
 	)
 
-	// 注册所有工具
-	tools.RegisterAllTools(s, apiClient)
+	// 注册工具，按只读模式、工具集和allow/deny列表过滤，调用时按仓库访问策略检查
+	tools.RegisterTools(s, apiClient, options.ToolFilter, policy)
 
 	// 注册提示模板
 	prompts.AddPrompts(s, apiClient)
@@ -68,25 +114,57 @@ func NewMCPServer(options MCPServerOptions) (*server.MCPServer, error) {
 
 // Run 启动MCP服务器
 func Run(s *server.MCPServer, options MCPServerOptions) error {
+	// 启用OAuth时每个请求都必须携带有效的访问令牌
+	var oauth *OAuthValidator
+	if options.OAuth != nil && options.multiUser() {
+		oauth = NewOAuthValidator(*options.OAuth)
+		log.Printf("已启用OAuth授权，授权服务器: %s，资源标识: %s\n", options.OAuth.Issuer, options.OAuth.Resource)
+	} else if options.multiUser() {
+		log.Println("警告: 未启用OAuth授权，能访问该端口的任何客户端都可以使用服务器，请只在本机或可信网络中监听")
+	}
+
+	// 连接未携带令牌时，只有配置了默认令牌才允许使用默认客户端
+	auth := NewSessionAuth(options.token() != "", oauth)
+	// 校验Host和Origin，防止DNS重绑定攻击
+	hosts := NewHostValidator(options.AllowedHosts)
+
 	// 根据传输方式启动服务器
 	switch strings.ToLower(options.Transport) {
 	case "sse":
 		address := fmt.Sprintf(":%d", options.ServerPort)
 		log.Printf("GitCode MCP服务器将在 http://localhost%s 上启动 (SSE模式)\n", address)
 		
-		// 创建SSE服务器
-		sseServer := server.NewSSEServer(s)
+		// 创建SSE服务器，每个连接使用各自的令牌
+		sseServer := newSSEHandler(s, auth, hosts)
 		
 		// 设置HTTP服务器
 		httpServer := &http.Server{
 			Addr:    address,
-			Handler: sseServer,
+			Handler: withOAuthMetadata(sseServer, oauth),
 		}
 		
+		// 启动HTTP服务器
+		return httpServer.ListenAndServe()
+	case "http":
+		address := fmt.Sprintf(":%d", options.ServerPort)
+		httpServer := &http.Server{
+			Addr:    address,
+			Handler: withOAuthMetadata(NewStreamableHTTPServer(s, options.HTTPPath, auth, hosts), oauth),
+		}
+		log.Printf("GitCode MCP服务器将在 http://localhost%s%s 上启动 (Streamable HTTP模式)\n", address, options.HTTPPath)
+
 		// 启动HTTP服务器
 		return httpServer.ListenAndServe()
 	default:
 		log.Println("GitCode MCP服务器已启动 (STDIO模式)")
 		return server.ServeStdio(s)
 	}
-} 
\ No newline at end of file
+}
+
+// withOAuthMetadata 启用OAuth时在handler之外提供受保护资源元数据端点
+func withOAuthMetadata(handler http.Handler, oauth *OAuthValidator) http.Handler {
+	if oauth == nil {
+		return handler
+	}
+	return oauth.Handler(handler)
+}
@@ -5,13 +5,12 @@ import (
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	
 	"github.com/gitcode-org-com/gitcode-mcp/api"
 )
 
 // AddBranchTools 添加分支相关工具到MCP服务器
-func AddBranchTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
+func AddBranchTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
 	// 列出分支
 	listBranchesTool := mcp.NewTool("list_branches",
 		mcp.WithDescription("列出仓库的分支"),
@@ -23,16 +22,21 @@ func AddBranchTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("仓库名称"),
 		),
+		WithPagination(),
+		WithFresh(),
 	)
-	s.AddTool(listBranchesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(listBranchesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
 		
-		branches, err := apiClient.Branches.ListBranches(owner, repo)
+		opts := ListOptionsFromRequest(request)
+		branches, err := client.Branches.ListBranches(ctx, owner, repo, opts)
 		if err != nil {
 			return nil, fmt.Errorf("获取分支列表失败: %w", err)
 		}
-		return FormatJSONResult(branches)
+		return FormatListResult(branches, len(branches), opts)
 	})
 	
 	// 获取分支
@@ -50,13 +54,16 @@ func AddBranchTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("分支名称"),
 		),
+		WithFresh(),
 	)
-	s.AddTool(getBranchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(getBranchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
 		branch, _ := request.Params.Arguments["branch"].(string)
 		
-		branchInfo, err := apiClient.Branches.GetBranch(owner, repo, branch)
+		branchInfo, err := client.Branches.GetBranch(ctx, owner, repo, branch)
 		if err != nil {
 			return nil, fmt.Errorf("获取分支详情失败: %w", err)
 		}
@@ -83,16 +90,174 @@ func AddBranchTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Description("基于的引用 (通常为另一个分支名或提交SHA)"),
 		),
 	)
-	s.AddTool(createBranchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddWriteTool(createBranchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
 		branch, _ := request.Params.Arguments["branch"].(string)
 		ref, _ := request.Params.Arguments["ref"].(string)
 		
-		branchInfo, err := apiClient.Branches.CreateBranch(owner, repo, branch, ref)
+		branchInfo, err := client.Branches.CreateBranch(ctx, owner, repo, branch, ref)
 		if err != nil {
 			return nil, fmt.Errorf("创建分支失败: %w", err)
 		}
 		return FormatJSONResult(branchInfo)
 	})
-} 
\ No newline at end of file
+	
+	// 删除分支
+	deleteBranchTool := mcp.NewTool("delete_branch",
+		mcp.WithDescription("删除分支"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("branch",
+			mcp.Required(),
+			mcp.Description("要删除的分支名称"),
+		),
+	)
+	s.AddWriteTool(deleteBranchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		branch, _ := request.Params.Arguments["branch"].(string)
+		
+		if err := client.Branches.DeleteBranch(ctx, owner, repo, branch); err != nil {
+			return nil, fmt.Errorf("删除分支失败: %w", err)
+		}
+		return TextResult("已删除分支 %s", branch)
+	})
+	
+	// 设置分支保护
+	protectBranchTool := mcp.NewTool("protect_branch",
+		mcp.WithDescription("设置分支保护规则"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("branch",
+			mcp.Required(),
+			mcp.Description("分支名称"),
+		),
+		mcp.WithObject("options",
+			mcp.Description("分支保护规则，按GitCode API的字段原样提交"),
+		),
+	)
+	s.AddWriteTool(protectBranchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		branch, _ := request.Params.Arguments["branch"].(string)
+		options, _ := request.Params.Arguments["options"].(map[string]interface{})
+		
+		if err := client.Branches.ProtectBranch(ctx, owner, repo, branch, options); err != nil {
+			return nil, fmt.Errorf("设置分支保护失败: %w", err)
+		}
+		return TextResult("已为分支 %s 设置保护", branch)
+	})
+	
+	// 移除分支保护
+	removeProtectionTool := mcp.NewTool("remove_branch_protection",
+		mcp.WithDescription("移除分支保护规则"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("branch",
+			mcp.Required(),
+			mcp.Description("分支名称"),
+		),
+	)
+	s.AddWriteTool(removeProtectionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		branch, _ := request.Params.Arguments["branch"].(string)
+		
+		if err := client.Branches.RemoveProtection(ctx, owner, repo, branch); err != nil {
+			return nil, fmt.Errorf("移除分支保护失败: %w", err)
+		}
+		return TextResult("已移除分支 %s 的保护", branch)
+	})
+	
+	// 获取分支保护规则
+	getProtectionTool := mcp.NewTool("get_branch_protection",
+		mcp.WithDescription("获取分支保护规则"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("branch",
+			mcp.Required(),
+			mcp.Description("分支名称"),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(getProtectionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		branch, _ := request.Params.Arguments["branch"].(string)
+		
+		protection, err := client.Branches.GetProtection(ctx, owner, repo, branch)
+		if err != nil {
+			return nil, fmt.Errorf("获取分支保护规则失败: %w", err)
+		}
+		return FormatJSONResult(protection)
+	})
+	
+	// 合并分支
+	mergeBranchTool := mcp.NewTool("merge_branch",
+		mcp.WithDescription("将一个分支直接合并到另一个分支"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("base",
+			mcp.Required(),
+			mcp.Description("合并的目标分支"),
+		),
+		mcp.WithString("head",
+			mcp.Required(),
+			mcp.Description("要合并进来的分支或提交SHA"),
+		),
+		mcp.WithString("commit_message",
+			mcp.Description("合并提交的说明"),
+		),
+	)
+	s.AddWriteTool(mergeBranchTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		base, _ := request.Params.Arguments["base"].(string)
+		head, _ := request.Params.Arguments["head"].(string)
+		message, _ := request.Params.Arguments["commit_message"].(string)
+		
+		result, err := client.Branches.MergeBranch(ctx, owner, repo, base, head, message)
+		if err != nil {
+			return nil, fmt.Errorf("合并分支失败: %w", err)
+		}
+		return FormatJSONResult(result)
+	})
+}
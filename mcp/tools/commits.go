@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+)
+
+// DefaultMaxCompareCommits compare_refs默认最多返回的提交数
+const DefaultMaxCompareCommits = 100
+
+// commitSummary 提交的摘要信息，用于比较结果中的提交列表
+type commitSummary struct {
+	SHA     string `json:"sha"`
+	Message string `json:"message"` // 提交信息的第一行
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+}
+
+// comparisonResult compare_refs返回的比较结果
+type comparisonResult struct {
+	Status       string           `json:"status"`
+	AheadBy      int              `json:"ahead_by"`
+	BehindBy     int              `json:"behind_by"`
+	TotalCommits int              `json:"total_commits"`
+	MergeBase    string           `json:"merge_base,omitempty"`
+	Commits      []commitSummary  `json:"commits"`
+	Files        []api.CommitFile `json:"files"`
+	HTMLURL      string           `json:"html_url,omitempty"`
+}
+
+// summarizeCommit 生成提交的摘要信息
+func summarizeCommit(commit api.Commit) commitSummary {
+	message, _, _ := strings.Cut(commit.Commit.Message, "\n")
+	return commitSummary{
+		SHA:     commit.SHA,
+		Message: message,
+		Author:  commit.Commit.Author.Name,
+		Date:    commit.Commit.Author.Date,
+	}
+}
+
+// limitPatches 让文件patch的总大小不超过maxBytes，超出后的文件不再返回patch，返回被省略patch的文件数
+// includePatch为false时移除所有patch
+func limitPatches(files []api.CommitFile, includePatch bool, maxBytes int) int {
+	omitted := 0
+	used := 0
+	for i := range files {
+		if files[i].Patch == "" {
+			continue
+		}
+		if !includePatch {
+			files[i].Patch = ""
+			continue
+		}
+		if used+len(files[i].Patch) > maxBytes {
+			files[i].Patch = ""
+			omitted++
+			continue
+		}
+		used += len(files[i].Patch)
+	}
+	return omitted
+}
+
+// WithPatchOptions 为返回文件修改的工具添加include_patch和max_bytes参数
+func WithPatchOptions(defaultInclude bool) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithBoolean("include_patch",
+			mcp.Description(fmt.Sprintf("是否返回每个文件的patch，默认为%t", defaultInclude)),
+		)(t)
+		mcp.WithNumber("max_bytes",
+			mcp.Description(fmt.Sprintf("所有patch的总字节数上限，默认%d，超出后的文件不返回patch", DefaultMaxFileBytes)),
+		)(t)
+	}
+}
+
+// patchOptionsFromRequest 从工具调用参数中读取patch相关参数
+func patchOptionsFromRequest(request mcp.CallToolRequest, defaultInclude bool) (bool, int) {
+	includePatch := defaultInclude
+	if v, ok := request.Params.Arguments["include_patch"].(bool); ok {
+		includePatch = v
+	}
+	maxBytes := DefaultMaxFileBytes
+	if v, ok := request.Params.Arguments["max_bytes"].(float64); ok && v > 0 {
+		maxBytes = int(v)
+	}
+	return includePatch, maxBytes
+}
+
+// AddCommitTools 添加提交相关工具到MCP服务器
+func AddCommitTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
+	// 列出提交
+	listCommitsTool := mcp.NewTool("list_commits",
+		mcp.WithDescription("列出仓库的提交历史，可按分支、路径、作者和时间范围过滤"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("ref",
+			mcp.Description("分支名、标签名或提交SHA，默认为仓库的默认分支"),
+		),
+		mcp.WithString("path",
+			mcp.Description("只返回修改了该文件或目录的提交"),
+		),
+		mcp.WithString("author",
+			mcp.Description("作者的用户名或邮箱"),
+		),
+		mcp.WithString("since",
+			mcp.Description("只返回该时间之后的提交，RFC3339格式或YYYY-MM-DD"),
+		),
+		mcp.WithString("until",
+			mcp.Description("只返回该时间之前的提交，RFC3339格式或YYYY-MM-DD"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listCommitsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+
+		filter := &api.ListCommitsOptions{}
+		filter.SHA, _ = request.Params.Arguments["ref"].(string)
+		filter.Path, _ = request.Params.Arguments["path"].(string)
+		filter.Author, _ = request.Params.Arguments["author"].(string)
+
+		var err error
+		if filter.Since, err = TimeArgument(request, "since"); err != nil {
+			return nil, err
+		}
+		if filter.Until, err = TimeArgument(request, "until"); err != nil {
+			return nil, err
+		}
+
+		opts := ListOptionsFromRequest(request)
+		commits, err := client.Commits.ListCommits(ctx, owner, repo, filter, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取提交列表失败: %w", err)
+		}
+		return FormatListResult(commits, len(commits), opts)
+	})
+
+	// 获取提交
+	getCommitTool := mcp.NewTool("get_commit",
+		mcp.WithDescription("获取单个提交的详细信息，包括增删行数统计、修改的文件和patch"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("sha",
+			mcp.Required(),
+			mcp.Description("提交SHA，也可以是分支名或标签名"),
+		),
+		WithPatchOptions(true),
+		WithFresh(),
+	)
+	s.AddReadTool(getCommitTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		sha, _ := request.Params.Arguments["sha"].(string)
+		includePatch, maxBytes := patchOptionsFromRequest(request, true)
+
+		commit, err := client.Commits.GetCommit(ctx, owner, repo, sha)
+		if err != nil {
+			return nil, fmt.Errorf("获取提交详情失败: %w", err)
+		}
+
+		omitted := limitPatches(commit.Files, includePatch, maxBytes)
+		result, err := FormatJSONResult(commit)
+		if err != nil {
+			return nil, err
+		}
+		if omitted > 0 {
+			result.Content = append(result.Content, mcp.NewTextContent(
+				fmt.Sprintf("patch总大小超过%d字节，有%d个文件未返回patch。可增大max_bytes获取。", maxBytes, omitted),
+			))
+		}
+		return result, nil
+	})
+
+	// 比较引用
+	compareRefsTool := mcp.NewTool("compare_refs",
+		mcp.WithDescription("比较两个分支、标签或提交，返回head相对base领先和落后的提交数、提交列表和修改的文件"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("base",
+			mcp.Required(),
+			mcp.Description("基准引用，如release-1.2"),
+		),
+		mcp.WithString("head",
+			mcp.Required(),
+			mcp.Description("比较的引用，如main"),
+		),
+		mcp.WithNumber("max_commits",
+			mcp.Description(fmt.Sprintf("最多返回的提交数，默认%d，0表示不限制", DefaultMaxCompareCommits)),
+		),
+		WithPatchOptions(false),
+		WithFresh(),
+	)
+	s.AddReadTool(compareRefsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		base, _ := request.Params.Arguments["base"].(string)
+		head, _ := request.Params.Arguments["head"].(string)
+		includePatch, maxBytes := patchOptionsFromRequest(request, false)
+
+		maxCommits := DefaultMaxCompareCommits
+		if v, ok := request.Params.Arguments["max_commits"].(float64); ok && v >= 0 {
+			maxCommits = int(v)
+		}
+
+		comparison, err := client.Commits.CompareRefs(ctx, owner, repo, base, head)
+		if err != nil {
+			return nil, fmt.Errorf("比较引用失败: %w", err)
+		}
+
+		compared := comparisonResult{
+			Status:       comparison.Status,
+			AheadBy:      comparison.AheadBy,
+			BehindBy:     comparison.BehindBy,
+			TotalCommits: comparison.TotalCommits,
+			Commits:      []commitSummary{},
+			Files:        comparison.Files,
+			HTMLURL:      comparison.HTMLURL,
+		}
+		if comparison.MergeBaseCommit != nil {
+			compared.MergeBase = comparison.MergeBaseCommit.SHA
+		}
+		if compared.Files == nil {
+			compared.Files = []api.CommitFile{}
+		}
+
+		// 提交列表按时间从旧到新排列，超出上限时保留最新的提交
+		commits := comparison.Commits
+		if maxCommits > 0 && len(commits) > maxCommits {
+			commits = commits[len(commits)-maxCommits:]
+		}
+		for _, commit := range commits {
+			compared.Commits = append(compared.Commits, summarizeCommit(commit))
+		}
+
+		omitted := limitPatches(compared.Files, includePatch, maxBytes)
+		result, err := FormatJSONResult(compared)
+		if err != nil {
+			return nil, err
+		}
+		if len(commits) < len(comparison.Commits) {
+			result.Content = append(result.Content, mcp.NewTextContent(
+				fmt.Sprintf("共%d个提交，只返回了最新的%d个。可增大max_commits获取更多。", len(comparison.Commits), len(commits)),
+			))
+		}
+		if omitted > 0 {
+			result.Content = append(result.Content, mcp.NewTextContent(
+				fmt.Sprintf("patch总大小超过%d字节，有%d个文件未返回patch。可增大max_bytes或使用get_commit查看单个提交。", maxBytes, omitted),
+			))
+		}
+		return result, nil
+	})
+}
@@ -0,0 +1,531 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+)
+
+const (
+	// DefaultMaxFileBytes get_file_contents默认最多返回的文件字节数
+	DefaultMaxFileBytes = 100 * 1024
+	// DefaultMaxTreeEntries get_repo_tree默认最多返回的条目数
+	DefaultMaxTreeEntries = 1000
+)
+
+// fileResult get_file_contents返回的文件信息
+type fileResult struct {
+	Path        string `json:"path"`
+	SHA         string `json:"sha"`
+	Size        int    `json:"size"`
+	Binary      bool   `json:"binary,omitempty"`
+	Truncated   bool   `json:"truncated,omitempty"`
+	Content     string `json:"content,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// dirEntry get_file_contents返回的目录项
+type dirEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Size int    `json:"size,omitempty"`
+	SHA  string `json:"sha"`
+}
+
+// treeResult get_repo_tree返回的树信息
+type treeResult struct {
+	SHA       string          `json:"sha"`
+	Total     int             `json:"total"`
+	Truncated bool            `json:"truncated,omitempty"`
+	Entries   []api.TreeEntry `json:"entries"`
+}
+
+// truncateLineWindow 截断文本时向前寻找换行符的最大字节数
+const truncateLineWindow = 1024
+
+// truncateText 将文本截断到不超过maxBytes字节且不拆开多字节字符。
+// 截断点之前truncateLineWindow字节内有换行符时在换行处截断，否则直接在截断点处截断，避免长行丢失过多内容
+func truncateText(data []byte, maxBytes int) []byte {
+	if len(data) <= maxBytes {
+		return data
+	}
+	cut := data[:maxBytes]
+	window := max(len(cut)-truncateLineWindow, 0)
+	if i := bytes.LastIndexByte(cut[window:], '\n'); i >= 0 && window+i > 0 {
+		return cut[:window+i+1]
+	}
+	// data[n]是字符的第一个字节时，data[:n]不会拆开多字节字符
+	n := len(cut)
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	return cut[:n]
+}
+
+// WithFileContent 为写文件工具添加content和encoding参数
+func WithFileContent() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithString("content",
+			mcp.Required(),
+			mcp.Description("文件的完整内容"),
+		)(t)
+		mcp.WithString("encoding",
+			mcp.Description("content的编码方式，默认为text；写入二进制文件时使用base64"),
+			mcp.Enum("text", "base64"),
+		)(t)
+	}
+}
+
+// fileContentFromRequest 从工具调用参数中读取要写入的文件内容
+func fileContentFromRequest(request mcp.CallToolRequest) ([]byte, error) {
+	content, _ := request.Params.Arguments["content"].(string)
+	if encoding, _ := request.Params.Arguments["encoding"].(string); encoding == "base64" {
+		data, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return nil, fmt.Errorf("content不是合法的base64: %w", err)
+		}
+		return data, nil
+	}
+	return []byte(content), nil
+}
+
+// fileOptionsFromRequest 从工具调用参数中读取提交参数
+func fileOptionsFromRequest(request mcp.CallToolRequest) *api.FileOptions {
+	message, _ := request.Params.Arguments["message"].(string)
+	branch, _ := request.Params.Arguments["branch"].(string)
+	sha, _ := request.Params.Arguments["sha"].(string)
+	return &api.FileOptions{
+		Message: message,
+		Branch:  branch,
+		SHA:     sha,
+	}
+}
+
+// fileOperationsFromRequest 从工具调用参数中读取多文件提交的文件操作列表
+func fileOperationsFromRequest(request mcp.CallToolRequest) ([]api.FileOperation, error) {
+	items, _ := request.Params.Arguments["files"].([]interface{})
+	ops := make([]api.FileOperation, 0, len(items))
+	for i, item := range items {
+		file, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("files的第%d项不是对象", i+1)
+		}
+
+		op := api.FileOperation{}
+		op.Action, _ = file["action"].(string)
+		op.Path, _ = file["path"].(string)
+		op.PreviousPath, _ = file["previous_path"].(string)
+		op.SHA, _ = file["sha"].(string)
+
+		// move时不提供content表示保持原内容
+		if content, ok := file["content"].(string); ok {
+			op.Content = []byte(content)
+			if encoding, _ := file["encoding"].(string); encoding == "base64" {
+				data, err := base64.StdEncoding.DecodeString(content)
+				if err != nil {
+					return nil, fmt.Errorf("文件 %s 的content不是合法的base64: %w", op.Path, err)
+				}
+				op.Content = data
+			}
+		} else if op.Action == api.FileActionCreate || op.Action == api.FileActionUpdate {
+			return nil, fmt.Errorf("文件 %s 缺少content", op.Path)
+		}
+
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// AddContentsTools 添加文件内容相关工具到MCP服务器
+func AddContentsTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
+	// 获取文件或目录内容
+	getFileContentsTool := mcp.NewTool("get_file_contents",
+		mcp.WithDescription("获取仓库中文件的内容或目录的列表。文本文件返回解码后的内容，超过大小上限时截断；二进制文件只返回元信息"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("path",
+			mcp.Description("文件或目录路径，为空时列出仓库根目录"),
+		),
+		mcp.WithString("ref",
+			mcp.Description("分支名、标签名或提交SHA，默认为仓库的默认分支"),
+		),
+		mcp.WithNumber("max_bytes",
+			mcp.Description(fmt.Sprintf("最多返回的文件字节数，默认%d", DefaultMaxFileBytes)),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(getFileContentsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		path, _ := request.Params.Arguments["path"].(string)
+		ref, _ := request.Params.Arguments["ref"].(string)
+
+		maxBytes := DefaultMaxFileBytes
+		if v, ok := request.Params.Arguments["max_bytes"].(float64); ok && v > 0 {
+			maxBytes = int(v)
+		}
+
+		file, entries, err := client.Contents.GetContents(ctx, owner, repo, path, ref)
+		if err != nil {
+			return nil, fmt.Errorf("获取文件内容失败: %w", err)
+		}
+
+		if file == nil {
+			dir := make([]dirEntry, 0, len(entries))
+			for _, entry := range entries {
+				dir = append(dir, dirEntry{
+					Name: entry.Name,
+					Path: entry.Path,
+					Type: entry.Type,
+					Size: entry.Size,
+					SHA:  entry.SHA,
+				})
+			}
+			return FormatJSONResult(dir)
+		}
+
+		result := fileResult{
+			Path: file.Path,
+			SHA:  file.SHA,
+			Size: file.Size,
+		}
+
+		// 文件过大时GitCode可能不返回内容
+		if file.Content == "" && file.Size > 0 {
+			result.DownloadURL = file.DownloadURL
+			res, err := FormatJSONResult(result)
+			if err != nil {
+				return nil, err
+			}
+			res.Content = append(res.Content, mcp.NewTextContent("GitCode未返回该文件的内容，文件可能过大，可通过download_url下载。"))
+			return res, nil
+		}
+
+		data, err := file.Decode()
+		if err != nil {
+			return nil, err
+		}
+
+		if api.IsBinary(data) {
+			result.Binary = true
+			result.DownloadURL = file.DownloadURL
+			res, err := FormatJSONResult(result)
+			if err != nil {
+				return nil, err
+			}
+			res.Content = append(res.Content, mcp.NewTextContent("这是一个二进制文件，未返回内容。"))
+			return res, nil
+		}
+
+		content := truncateText(data, maxBytes)
+		result.Content = string(content)
+		result.Truncated = len(content) < len(data)
+
+		res, err := FormatJSONResult(result)
+		if err != nil {
+			return nil, err
+		}
+		if result.Truncated {
+			res.Content = append(res.Content, mcp.NewTextContent(
+				fmt.Sprintf("文件共%d字节，只返回了前%d字节。可增大max_bytes获取更多内容。", len(data), len(content)),
+			))
+		}
+		return res, nil
+	})
+
+	// 获取仓库文件树
+	getRepoTreeTool := mcp.NewTool("get_repo_tree",
+		mcp.WithDescription("获取仓库的文件树，默认递归列出所有文件和目录"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("ref",
+			mcp.Description("分支名、标签名、提交SHA或树SHA，默认为仓库的默认分支"),
+		),
+		mcp.WithString("path",
+			mcp.Description("只返回该目录下的条目"),
+		),
+		mcp.WithBoolean("recursive",
+			mcp.Description("是否递归获取子目录，默认为true"),
+		),
+		mcp.WithNumber("max_entries",
+			mcp.Description(fmt.Sprintf("最多返回的条目数，默认%d，0表示不限制", DefaultMaxTreeEntries)),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(getRepoTreeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		ref, _ := request.Params.Arguments["ref"].(string)
+		path, _ := request.Params.Arguments["path"].(string)
+
+		recursive := true
+		if v, ok := request.Params.Arguments["recursive"].(bool); ok {
+			recursive = v
+		}
+		maxEntries := DefaultMaxTreeEntries
+		if v, ok := request.Params.Arguments["max_entries"].(float64); ok && v >= 0 {
+			maxEntries = int(v)
+		}
+
+		if ref == "" {
+			repository, err := client.Repos.GetRepo(ctx, owner, repo)
+			if err != nil {
+				return nil, fmt.Errorf("获取仓库默认分支失败: %w", err)
+			}
+			ref = repository.DefaultBranch
+		}
+
+		// 指定目录时需要递归获取后再筛选出该目录下的条目
+		prefix := strings.Trim(path, "/")
+		tree, err := client.Contents.GetTree(ctx, owner, repo, ref, recursive || prefix != "")
+		if err != nil {
+			return nil, fmt.Errorf("获取文件树失败: %w", err)
+		}
+
+		entries := tree.Tree
+		if prefix != "" {
+			entries = nil
+			for _, entry := range tree.Tree {
+				rest, found := strings.CutPrefix(entry.Path, prefix+"/")
+				if !found || (!recursive && strings.Contains(rest, "/")) {
+					continue
+				}
+				entries = append(entries, entry)
+			}
+		}
+
+		result := treeResult{
+			SHA:       tree.SHA,
+			Total:     len(entries),
+			Truncated: tree.Truncated,
+			Entries:   entries,
+		}
+		if result.Entries == nil {
+			result.Entries = []api.TreeEntry{}
+		}
+		if maxEntries > 0 && len(entries) > maxEntries {
+			result.Entries = entries[:maxEntries]
+		}
+
+		res, err := FormatJSONResult(result)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Entries) < result.Total {
+			res.Content = append(res.Content, mcp.NewTextContent(
+				fmt.Sprintf("共%d个条目，只返回了前%d个。可通过path参数缩小范围或增大max_entries。", result.Total, len(result.Entries)),
+			))
+		}
+		if tree.Truncated {
+			res.Content = append(res.Content, mcp.NewTextContent("GitCode返回的文件树已被截断，可通过path参数或关闭recursive分目录获取。"))
+		}
+		return res, nil
+	})
+
+	// 创建文件
+	createFileTool := mcp.NewTool("create_file",
+		mcp.WithDescription("在分支上创建新文件并提交，文件已存在时失败"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("文件路径"),
+		),
+		WithFileContent(),
+		mcp.WithString("message",
+			mcp.Required(),
+			mcp.Description("提交信息"),
+		),
+		mcp.WithString("branch",
+			mcp.Description("目标分支，默认为仓库的默认分支"),
+		),
+	)
+	s.AddWriteTool(createFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		path, _ := request.Params.Arguments["path"].(string)
+
+		content, err := fileContentFromRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := client.Contents.CreateFile(ctx, owner, repo, path, content, fileOptionsFromRequest(request))
+		if err != nil {
+			return nil, fmt.Errorf("创建文件失败: %w", err)
+		}
+		return FormatJSONResult(result)
+	})
+
+	// 更新文件
+	updateFileTool := mcp.NewTool("update_file",
+		mcp.WithDescription("更新分支上的已有文件并提交。需要提供文件的当前SHA（可通过get_file_contents获取），文件在此期间被修改时失败"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("文件路径"),
+		),
+		WithFileContent(),
+		mcp.WithString("sha",
+			mcp.Required(),
+			mcp.Description("文件的当前blob SHA"),
+		),
+		mcp.WithString("message",
+			mcp.Required(),
+			mcp.Description("提交信息"),
+		),
+		mcp.WithString("branch",
+			mcp.Description("目标分支，默认为仓库的默认分支"),
+		),
+	)
+	s.AddWriteTool(updateFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		path, _ := request.Params.Arguments["path"].(string)
+
+		content, err := fileContentFromRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := client.Contents.UpdateFile(ctx, owner, repo, path, content, fileOptionsFromRequest(request))
+		if err != nil {
+			return nil, fmt.Errorf("更新文件失败: %w", err)
+		}
+		return FormatJSONResult(result)
+	})
+
+	// 删除文件
+	deleteFileTool := mcp.NewTool("delete_file",
+		mcp.WithDescription("删除分支上的文件并提交。需要提供文件的当前SHA，文件在此期间被修改时失败"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("文件路径"),
+		),
+		mcp.WithString("sha",
+			mcp.Required(),
+			mcp.Description("文件的当前blob SHA"),
+		),
+		mcp.WithString("message",
+			mcp.Required(),
+			mcp.Description("提交信息"),
+		),
+		mcp.WithString("branch",
+			mcp.Description("目标分支，默认为仓库的默认分支"),
+		),
+	)
+	s.AddWriteTool(deleteFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		path, _ := request.Params.Arguments["path"].(string)
+
+		result, err := client.Contents.DeleteFile(ctx, owner, repo, path, fileOptionsFromRequest(request))
+		if err != nil {
+			return nil, fmt.Errorf("删除文件失败: %w", err)
+		}
+		return FormatJSONResult(result)
+	})
+
+	// 多文件提交
+	commitFilesTool := mcp.NewTool("commit_files",
+		mcp.WithDescription("将多个文件的创建、更新、删除和移动作为一个提交落到分支上。平台不支持Git数据接口时会逐个文件提交，失败时撤销已完成的修改"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("branch",
+			mcp.Required(),
+			mcp.Description("目标分支"),
+		),
+		mcp.WithString("message",
+			mcp.Required(),
+			mcp.Description("提交信息"),
+		),
+		mcp.WithArray("files",
+			mcp.Required(),
+			mcp.Description("文件操作列表，每个文件只能出现在一个操作中"),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"action":        map[string]interface{}{"type": "string", "enum": []string{"create", "update", "delete", "move"}, "description": "操作类型"},
+					"path":          map[string]interface{}{"type": "string", "description": "文件路径，move时为目标路径"},
+					"previous_path": map[string]interface{}{"type": "string", "description": "move时的源路径"},
+					"content":       map[string]interface{}{"type": "string", "description": "create和update时的文件内容；move时省略表示保持原内容"},
+					"encoding":      map[string]interface{}{"type": "string", "enum": []string{"text", "base64"}, "description": "content的编码方式，默认为text"},
+					"sha":           map[string]interface{}{"type": "string", "description": "可选，文件的当前blob SHA，文件已被修改时失败"},
+				},
+				"required": []string{"action", "path"},
+			}),
+		),
+	)
+	s.AddWriteTool(commitFilesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		branch, _ := request.Params.Arguments["branch"].(string)
+		message, _ := request.Params.Arguments["message"].(string)
+
+		ops, err := fileOperationsFromRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := client.Contents.CommitFiles(ctx, owner, repo, branch, message, ops)
+		if err != nil {
+			return nil, fmt.Errorf("提交文件失败: %w", err)
+		}
+		return FormatJSONResult(result)
+	})
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"log"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+	"github.com/gitcode-org-com/gitcode-mcp/config"
+)
+
+// ToolFilter 决定哪些工具会被注册。只读模式和拒绝列表优先于其他条件
+type ToolFilter struct {
+	ReadOnly bool     // 只注册不修改数据的工具
+	Toolsets []string // 启用的工具集（即工具分组），为空时启用全部
+	Allow    []string // 只注册名称匹配任意一个glob模式的工具，为空时不限制
+	Deny     []string // 不注册名称匹配任意一个glob模式的工具
+
+	matched map[string]bool // 匹配到工具的allow/deny模式，用于提示没有匹配任何工具的模式
+}
+
+// ToolFilterFromConfig 从全局配置读取工具过滤条件
+func ToolFilterFromConfig() *ToolFilter {
+	return &ToolFilter{
+		ReadOnly: config.GlobalConfig.ReadOnly,
+		Toolsets: config.GlobalConfig.Toolsets,
+		Allow:    config.GlobalConfig.ToolsAllow,
+		Deny:     config.GlobalConfig.ToolsDeny,
+	}
+}
+
+// Allows 判断是否注册工具，filter为nil时注册全部工具
+func (f *ToolFilter) Allows(info ToolInfo) bool {
+	if f == nil {
+		return true
+	}
+	if f.matched == nil {
+		f.matched = map[string]bool{}
+	}
+
+	denied := false
+	for _, pattern := range f.Deny {
+		if api.MatchGlob(pattern, info.Name) {
+			f.matched[pattern] = true
+			denied = true
+		}
+	}
+	allowed := len(f.Allow) == 0
+	for _, pattern := range f.Allow {
+		if api.MatchGlob(pattern, info.Name) {
+			f.matched[pattern] = true
+			allowed = true
+		}
+	}
+
+	if denied || !allowed {
+		return false
+	}
+	if f.ReadOnly && !info.ReadOnly {
+		return false
+	}
+	if len(f.Toolsets) == 0 {
+		return true
+	}
+	for _, toolset := range f.Toolsets {
+		if toolset == info.Group {
+			return true
+		}
+	}
+	return false
+}
+
+// warnUnmatched 提示没有匹配任何工具的allow/deny模式，通常是工具名称拼写错误
+func (f *ToolFilter) warnUnmatched() {
+	if f == nil {
+		return
+	}
+	for _, pattern := range append(append([]string{}, f.Allow...), f.Deny...) {
+		if !f.matched[pattern] {
+			log.Printf("警告: 工具过滤模式%s没有匹配任何工具", pattern)
+		}
+	}
+}
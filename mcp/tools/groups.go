@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+)
+
+// 工具分组，OAuth scope按分组授权
+const (
+	GroupRepos    = "repos"    // 仓库、文件内容、发布版本
+	GroupBranches = "branches" // 分支、提交
+	GroupIssues   = "issues"   // Issue、标签、里程碑
+	GroupPulls    = "pulls"    // Pull Request、代码审查
+	GroupSearch   = "search"   // 搜索
+)
+
+// Groups 返回所有工具分组
+func Groups() []string {
+	return []string{GroupRepos, GroupBranches, GroupIssues, GroupPulls, GroupSearch}
+}
+
+// ErrInsufficientScope 访问令牌没有调用工具所需的scope
+var ErrInsufficientScope = errors.New("权限范围不足")
+
+// ToolRegistrar 注册工具的目标。注册时必须声明工具是否只读，只读与否决定所需的OAuth scope、
+// 只读模式下是否注册以及按仓库访问策略的读取还是写入规则检查
+type ToolRegistrar interface {
+	// AddReadTool 注册不修改任何数据的工具
+	AddReadTool(tool mcp.Tool, handler server.ToolHandlerFunc)
+	// AddWriteTool 注册会修改GitCode数据或服务器本地文件的工具
+	AddWriteTool(tool mcp.Tool, handler server.ToolHandlerFunc)
+}
+
+// ToolInfo 描述工具所属的分组以及是否只读
+type ToolInfo struct {
+	Name     string
+	Group    string
+	ReadOnly bool
+}
+
+// Scope 返回调用工具所需的OAuth scope
+func (t ToolInfo) Scope() string {
+	return Scope(t.Group, t.ReadOnly)
+}
+
+var (
+	toolInfos   = map[string]ToolInfo{}
+	toolInfosMu sync.RWMutex
+)
+
+// LookupTool 返回已注册工具的分组信息
+func LookupTool(name string) (ToolInfo, bool) {
+	toolInfosMu.RLock()
+	defer toolInfosMu.RUnlock()
+	info, ok := toolInfos[name]
+	return info, ok
+}
+
+// toolGroup 将工具登记到指定分组，调用时按上下文中的scope和仓库访问策略授权
+type toolGroup struct {
+	server *server.MCPServer
+	group  string
+	filter *ToolFilter
+	policy *api.RepoPolicy
+}
+
+// AddReadTool 实现ToolRegistrar接口
+func (g *toolGroup) AddReadTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	g.addTool(ToolInfo{Name: tool.Name, Group: g.group, ReadOnly: true}, tool, handler)
+}
+
+// AddWriteTool 实现ToolRegistrar接口
+func (g *toolGroup) AddWriteTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	g.addTool(ToolInfo{Name: tool.Name, Group: g.group, ReadOnly: false}, tool, handler)
+}
+
+// addTool 登记工具信息并注册工具，被过滤条件排除的工具不会注册
+func (g *toolGroup) addTool(info ToolInfo, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if !g.filter.Allows(info) {
+		return
+	}
+	toolInfosMu.Lock()
+	toolInfos[tool.Name] = info
+	toolInfosMu.Unlock()
+
+	g.server.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if scopes, ok := ScopesFromContext(ctx); ok && !scopes.Allows(info) {
+			return nil, fmt.Errorf("%w: 工具%s需要%s", ErrInsufficientScope, info.Name, info.Scope())
+		}
+		if err := checkRepoPolicy(g.policy, info, request); err != nil {
+			return nil, err
+		}
+		return handler(withRepoPolicy(ctx, g.policy), request)
+	})
+}
+
+// Scope 返回分组的OAuth scope，如issues:read、issues:write
+func Scope(group string, readOnly bool) string {
+	if readOnly {
+		return group + ":read"
+	}
+	return group + ":write"
+}
+
+// AllScopes 返回已注册工具用到的所有scope，没有写操作工具的分组不包含write scope
+func AllScopes() []string {
+	used := map[string]bool{}
+	toolInfosMu.RLock()
+	for _, info := range toolInfos {
+		used[info.Scope()] = true
+	}
+	toolInfosMu.RUnlock()
+
+	var scopes []string
+	for _, group := range Groups() {
+		for _, scope := range []string{Scope(group, true), Scope(group, false)} {
+			if used[scope] {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// Scopes 表示访问令牌授予的scope集合
+type Scopes map[string]bool
+
+// ParseScopes 解析以空格分隔的scope列表
+func ParseScopes(s string) Scopes {
+	scopes := Scopes{}
+	for _, scope := range strings.Fields(s) {
+		scopes[scope] = true
+	}
+	return scopes
+}
+
+// Allows 判断是否允许调用工具，分组的write scope同时包含read权限
+func (s Scopes) Allows(info ToolInfo) bool {
+	if s[Scope(info.Group, false)] {
+		return true
+	}
+	return info.ReadOnly && s[Scope(info.Group, true)]
+}
+
+// String 返回以空格分隔的scope列表
+func (s Scopes) String() string {
+	list := make([]string, 0, len(s))
+	for scope := range s {
+		list = append(list, scope)
+	}
+	sort.Strings(list)
+	return strings.Join(list, " ")
+}
+
+type scopesKey struct{}
+
+// WithScopes 返回携带OAuth scope的上下文，工具调用时按scope授权
+func WithScopes(ctx context.Context, scopes Scopes) context.Context {
+	return context.WithValue(ctx, scopesKey{}, scopes)
+}
+
+// ScopesFromContext 返回上下文中的scope，没有启用OAuth时返回false，此时不限制工具调用
+func ScopesFromContext(ctx context.Context) (Scopes, bool) {
+	scopes, ok := ctx.Value(scopesKey{}).(Scopes)
+	return scopes, ok
+}
+
+// FilterTools 只保留scope允许调用的工具
+func FilterTools(list []mcp.Tool, scopes Scopes) []mcp.Tool {
+	filtered := make([]mcp.Tool, 0, len(list))
+	for _, tool := range list {
+		if info, ok := LookupTool(tool.Name); !ok || scopes.Allows(info) {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
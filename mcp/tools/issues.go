@@ -5,16 +5,15 @@ import (
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	
 	"github.com/gitcode-org-com/gitcode-mcp/api"
 )
 
 // AddIssueTools 添加Issue相关工具到MCP服务器
-func AddIssueTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
+func AddIssueTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
 	// 列出Issues
 	listIssuesTool := mcp.NewTool("list_issues",
-		mcp.WithDescription("列出仓库的Issues"),
+		mcp.WithDescription("列出仓库的Issues，可按状态、标签、负责人、创建者、里程碑和更新时间过滤"),
 		mcp.WithString("owner",
 			mcp.Required(),
 			mcp.Description("仓库所有者"),
@@ -23,16 +22,27 @@ func AddIssueTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("仓库名称"),
 		),
+		WithIssueFilters(),
+		WithPagination(),
+		WithFresh(),
 	)
-	s.AddTool(listIssuesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(listIssuesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
 		
-		issues, err := apiClient.Issues.ListIssues(owner, repo)
+		filter, err := IssueFilterFromRequest(request)
+		if err != nil {
+			return nil, err
+		}
+		
+		opts := ListOptionsFromRequest(request)
+		issues, err := client.Issues.ListIssues(ctx, owner, repo, filter, opts)
 		if err != nil {
 			return nil, fmt.Errorf("获取Issues列表失败: %w", err)
 		}
-		return FormatJSONResult(issues)
+		return FormatListResult(issues, len(issues), opts)
 	})
 	
 	// 获取Issue
@@ -50,13 +60,16 @@ func AddIssueTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("Issue编号"),
 		),
+		WithFresh(),
 	)
-	s.AddTool(getIssueTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(getIssueTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
 		issueNumber, _ := request.Params.Arguments["issue_number"].(float64)
 		
-		issue, err := apiClient.Issues.GetIssue(owner, repo, int(issueNumber))
+		issue, err := client.Issues.GetIssue(ctx, owner, repo, int(issueNumber))
 		if err != nil {
 			return nil, fmt.Errorf("获取Issue详情失败: %w", err)
 		}
@@ -81,17 +94,413 @@ func AddIssueTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 		mcp.WithString("body",
 			mcp.Description("Issue内容"),
 		),
+		mcp.WithArray("assignees",
+			mcp.Description("负责人用户名列表"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithArray("labels",
+			mcp.Description("标签名称列表"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("milestone",
+			mcp.Description("关联的里程碑编号"),
+		),
 	)
-	s.AddTool(createIssueTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddWriteTool(createIssueTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
-		title, _ := request.Params.Arguments["title"].(string)
-		body, _ := request.Params.Arguments["body"].(string)
 		
-		issue, err := apiClient.Issues.CreateIssue(owner, repo, title, body)
+		options := api.CreateIssueOptions{}
+		options.Title, _ = request.Params.Arguments["title"].(string)
+		options.Body, _ = request.Params.Arguments["body"].(string)
+		options.Assignees = StringSliceArgument(request, "assignees")
+		options.Labels = StringSliceArgument(request, "labels")
+		if milestone, ok := request.Params.Arguments["milestone"].(float64); ok {
+			options.Milestone = int(milestone)
+		}
+		
+		issue, err := client.Issues.CreateIssue(ctx, owner, repo, options)
 		if err != nil {
 			return nil, fmt.Errorf("创建Issue失败: %w", err)
 		}
 		return FormatJSONResult(issue)
 	})
-} 
\ No newline at end of file
+	
+	// 更新Issue
+	updateIssueTool := mcp.NewTool("update_issue",
+		mcp.WithDescription("更新Issue的标题、内容、状态、负责人、标签或里程碑"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("issue_number",
+			mcp.Required(),
+			mcp.Description("Issue编号"),
+		),
+		mcp.WithString("title",
+			mcp.Description("新的Issue标题"),
+		),
+		mcp.WithString("body",
+			mcp.Description("新的Issue内容"),
+		),
+		mcp.WithString("state",
+			mcp.Description("Issue状态"),
+			mcp.Enum("open", "closed"),
+		),
+		mcp.WithArray("assignees",
+			mcp.Description("负责人用户名列表"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithArray("labels",
+			mcp.Description("标签名称列表，会替换Issue现有的标签"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("milestone",
+			mcp.Description("关联的里程碑编号，0表示移除里程碑"),
+		),
+	)
+	s.AddWriteTool(updateIssueTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		issueNumber, _ := request.Params.Arguments["issue_number"].(float64)
+		
+		options := api.UpdateIssueOptions{}
+		options.Title, _ = request.Params.Arguments["title"].(string)
+		options.Body, _ = request.Params.Arguments["body"].(string)
+		options.State, _ = request.Params.Arguments["state"].(string)
+		options.Assignees = StringSliceArgument(request, "assignees")
+		options.Labels = StringSliceArgument(request, "labels")
+		if milestone, ok := request.Params.Arguments["milestone"].(float64); ok {
+			number := int(milestone)
+			options.Milestone = &number
+		}
+		
+		issue, err := client.Issues.UpdateIssue(ctx, owner, repo, int(issueNumber), options)
+		if err != nil {
+			return nil, fmt.Errorf("更新Issue失败: %w", err)
+		}
+		return FormatJSONResult(issue)
+	})
+	
+	// 关闭Issue
+	closeIssueTool := mcp.NewTool("close_issue",
+		mcp.WithDescription("关闭Issue"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("issue_number",
+			mcp.Required(),
+			mcp.Description("Issue编号"),
+		),
+	)
+	s.AddWriteTool(closeIssueTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		issueNumber, _ := request.Params.Arguments["issue_number"].(float64)
+		
+		issue, err := client.Issues.CloseIssue(ctx, owner, repo, int(issueNumber))
+		if err != nil {
+			return nil, fmt.Errorf("关闭Issue失败: %w", err)
+		}
+		return FormatJSONResult(issue)
+	})
+	
+	// 重新打开Issue
+	reopenIssueTool := mcp.NewTool("reopen_issue",
+		mcp.WithDescription("重新打开已关闭的Issue"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("issue_number",
+			mcp.Required(),
+			mcp.Description("Issue编号"),
+		),
+	)
+	s.AddWriteTool(reopenIssueTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		issueNumber, _ := request.Params.Arguments["issue_number"].(float64)
+		
+		issue, err := client.Issues.ReopenIssue(ctx, owner, repo, int(issueNumber))
+		if err != nil {
+			return nil, fmt.Errorf("重新打开Issue失败: %w", err)
+		}
+		return FormatJSONResult(issue)
+	})
+	
+	// 列出Issue评论
+	listCommentsTool := mcp.NewTool("list_issue_comments",
+		mcp.WithDescription("列出Issue的评论"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("issue_number",
+			mcp.Required(),
+			mcp.Description("Issue编号"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listCommentsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		issueNumber, _ := request.Params.Arguments["issue_number"].(float64)
+		
+		opts := ListOptionsFromRequest(request)
+		comments, err := client.Issues.ListComments(ctx, owner, repo, int(issueNumber), opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取Issue评论列表失败: %w", err)
+		}
+		return FormatListResult(comments, len(comments), opts)
+	})
+	
+	// 添加Issue评论
+	addCommentTool := mcp.NewTool("add_issue_comment",
+		mcp.WithDescription("为Issue添加评论"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("issue_number",
+			mcp.Required(),
+			mcp.Description("Issue编号"),
+		),
+		mcp.WithString("body",
+			mcp.Required(),
+			mcp.Description("评论内容"),
+		),
+	)
+	s.AddWriteTool(addCommentTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		issueNumber, _ := request.Params.Arguments["issue_number"].(float64)
+		body, _ := request.Params.Arguments["body"].(string)
+		
+		comment, err := client.Issues.AddComment(ctx, owner, repo, int(issueNumber), body)
+		if err != nil {
+			return nil, fmt.Errorf("添加Issue评论失败: %w", err)
+		}
+		return FormatJSONResult(comment)
+	})
+	
+	// 编辑Issue评论
+	editCommentTool := mcp.NewTool("edit_issue_comment",
+		mcp.WithDescription("编辑Issue评论"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("comment_id",
+			mcp.Required(),
+			mcp.Description("评论ID"),
+		),
+		mcp.WithString("body",
+			mcp.Required(),
+			mcp.Description("新的评论内容"),
+		),
+	)
+	s.AddWriteTool(editCommentTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		commentID, _ := request.Params.Arguments["comment_id"].(float64)
+		body, _ := request.Params.Arguments["body"].(string)
+		
+		comment, err := client.Issues.EditComment(ctx, owner, repo, int(commentID), body)
+		if err != nil {
+			return nil, fmt.Errorf("编辑Issue评论失败: %w", err)
+		}
+		return FormatJSONResult(comment)
+	})
+	
+	// 删除Issue评论
+	deleteCommentTool := mcp.NewTool("delete_issue_comment",
+		mcp.WithDescription("删除Issue评论"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("comment_id",
+			mcp.Required(),
+			mcp.Description("评论ID"),
+		),
+	)
+	s.AddWriteTool(deleteCommentTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		commentID, _ := request.Params.Arguments["comment_id"].(float64)
+		
+		if err := client.Issues.DeleteComment(ctx, owner, repo, int(commentID)); err != nil {
+			return nil, fmt.Errorf("删除Issue评论失败: %w", err)
+		}
+		return TextResult("已删除评论 %d", int(commentID))
+	})
+	
+	// 列出仓库标签
+	listLabelsTool := mcp.NewTool("list_labels",
+		mcp.WithDescription("列出仓库的标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listLabelsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		
+		opts := ListOptionsFromRequest(request)
+		labels, err := client.Issues.ListLabels(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取标签列表失败: %w", err)
+		}
+		return FormatListResult(labels, len(labels), opts)
+	})
+	
+	// 获取Issue标签
+	getIssueLabelsTool := mcp.NewTool("get_issue_labels",
+		mcp.WithDescription("获取Issue的标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("issue_number",
+			mcp.Required(),
+			mcp.Description("Issue编号"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(getIssueLabelsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		issueNumber, _ := request.Params.Arguments["issue_number"].(float64)
+		
+		opts := ListOptionsFromRequest(request)
+		labels, err := client.Issues.GetIssueLabels(ctx, owner, repo, int(issueNumber), opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取Issue标签失败: %w", err)
+		}
+		return FormatListResult(labels, len(labels), opts)
+	})
+	
+	// 为Issue添加标签
+	addLabelsTool := mcp.NewTool("add_issue_labels",
+		mcp.WithDescription("为Issue添加标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("issue_number",
+			mcp.Required(),
+			mcp.Description("Issue编号"),
+		),
+		mcp.WithArray("labels",
+			mcp.Required(),
+			mcp.Description("要添加的标签名称列表"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+	)
+	s.AddWriteTool(addLabelsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		issueNumber, _ := request.Params.Arguments["issue_number"].(float64)
+		labels := StringSliceArgument(request, "labels")
+		
+		result, err := client.Issues.AddLabelsToIssue(ctx, owner, repo, int(issueNumber), labels)
+		if err != nil {
+			return nil, fmt.Errorf("为Issue添加标签失败: %w", err)
+		}
+		return FormatJSONResult(result)
+	})
+	
+	// 从Issue移除标签
+	removeLabelTool := mcp.NewTool("remove_issue_label",
+		mcp.WithDescription("从Issue移除一个标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("issue_number",
+			mcp.Required(),
+			mcp.Description("Issue编号"),
+		),
+		mcp.WithString("label",
+			mcp.Required(),
+			mcp.Description("要移除的标签名称"),
+		),
+	)
+	s.AddWriteTool(removeLabelTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		issueNumber, _ := request.Params.Arguments["issue_number"].(float64)
+		label, _ := request.Params.Arguments["label"].(string)
+		
+		if err := client.Issues.RemoveLabelFromIssue(ctx, owner, repo, int(issueNumber), label); err != nil {
+			return nil, fmt.Errorf("从Issue移除标签失败: %w", err)
+		}
+		return TextResult("已从Issue #%d 移除标签 %s", int(issueNumber), label)
+	})
+}
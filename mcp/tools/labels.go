@@ -0,0 +1,275 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+)
+
+// labelSyncFailure 同步失败的仓库及原因
+type labelSyncFailure struct {
+	Repo  string `json:"repo"`
+	Error string `json:"error"`
+}
+
+// labelSyncReport sync_labels返回的同步报告
+type labelSyncReport struct {
+	DryRun  bool                  `json:"dry_run"`
+	Results []api.LabelSyncResult `json:"results"`
+	Failed  []labelSyncFailure    `json:"failed"`
+}
+
+// labelSpecFromRequest 从spec或spec_path参数读取标签规范
+func labelSpecFromRequest(request mcp.CallToolRequest) (*api.LabelSpec, error) {
+	spec, _ := request.Params.Arguments["spec"].(string)
+	specPath, _ := request.Params.Arguments["spec_path"].(string)
+
+	var data []byte
+	switch {
+	case spec != "":
+		data = []byte(spec)
+	case specPath != "":
+		path, err := resolveLocalPath(specPath, false)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取标签规范文件失败: %w", err)
+		}
+		data = content
+	default:
+		return nil, fmt.Errorf("需要提供spec或spec_path")
+	}
+
+	return api.ParseLabelSpec(data)
+}
+
+// AddLabelTools 添加标签管理相关工具到MCP服务器
+func AddLabelTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
+	// 创建标签
+	createLabelTool := mcp.NewTool("create_label",
+		mcp.WithDescription("创建仓库标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("标签名称"),
+		),
+		mcp.WithString("color",
+			mcp.Required(),
+			mcp.Description("6位十六进制颜色，如d73a4a，可以带#"),
+		),
+		mcp.WithString("description",
+			mcp.Description("标签描述"),
+		),
+	)
+	s.AddWriteTool(createLabelTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+
+		options := api.LabelOptions{}
+		options.Name, _ = request.Params.Arguments["name"].(string)
+		options.Color, _ = request.Params.Arguments["color"].(string)
+		options.Description, _ = request.Params.Arguments["description"].(string)
+
+		label, err := client.Issues.CreateLabel(ctx, owner, repo, options)
+		if err != nil {
+			return nil, fmt.Errorf("创建标签失败: %w", err)
+		}
+		return FormatJSONResult(label)
+	})
+
+	// 更新标签
+	updateLabelTool := mcp.NewTool("update_label",
+		mcp.WithDescription("更新仓库标签的名称、颜色或描述，重命名后已打上该标签的Issue会随之更新"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("标签的当前名称"),
+		),
+		mcp.WithString("new_name",
+			mcp.Description("新的标签名称"),
+		),
+		mcp.WithString("color",
+			mcp.Description("新的颜色，6位十六进制"),
+		),
+		mcp.WithString("description",
+			mcp.Description("新的标签描述"),
+		),
+	)
+	s.AddWriteTool(updateLabelTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		name, _ := request.Params.Arguments["name"].(string)
+
+		options := api.LabelOptions{}
+		options.Name, _ = request.Params.Arguments["new_name"].(string)
+		options.Color, _ = request.Params.Arguments["color"].(string)
+		options.Description, _ = request.Params.Arguments["description"].(string)
+
+		label, err := client.Issues.UpdateLabel(ctx, owner, repo, name, options)
+		if err != nil {
+			return nil, fmt.Errorf("更新标签失败: %w", err)
+		}
+		return FormatJSONResult(label)
+	})
+
+	// 删除标签
+	deleteLabelTool := mcp.NewTool("delete_label",
+		mcp.WithDescription("删除仓库标签，标签会从所有Issue和Pull Request上移除"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("标签名称"),
+		),
+	)
+	s.AddWriteTool(deleteLabelTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		name, _ := request.Params.Arguments["name"].(string)
+
+		if err := client.Issues.DeleteLabel(ctx, owner, repo, name); err != nil {
+			return nil, fmt.Errorf("删除标签失败: %w", err)
+		}
+		return TextResult("已删除标签 %s", name)
+	})
+
+	// 同步标签
+	syncLabelsTool := mcp.NewTool("sync_labels",
+		mcp.WithDescription("按照YAML或JSON格式的标签规范同步标签，不指定repo时同步组织下的所有仓库。"+
+			"规范格式：labels为标签列表，每项包含name、color、description?和aliases?（旧名称，存在时重命名）；prune为true时删除规范中没有的标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者或组织名"),
+		),
+		mcp.WithString("repo",
+			mcp.Description("仓库名称，不提供时同步组织下的所有仓库"),
+		),
+		mcp.WithString("spec",
+			mcp.Description("标签规范的内容，与spec_path二选一"),
+		),
+		mcp.WithString("spec_path",
+			mcp.Description("服务器本地标签规范文件的路径，与spec二选一，SSE/HTTP模式下需配置GITCODE_LOCAL_FILE_DIR"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("为true时只返回将要进行的修改，不实际执行，默认为false"),
+		),
+	)
+	s.AddWriteTool(syncLabelsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		dryRun, _ := request.Params.Arguments["dry_run"].(bool)
+
+		spec, err := labelSpecFromRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		repos := []string{repo}
+		if repo == "" {
+			orgRepos, err := client.Repos.ListReposByOrg(ctx, owner, &api.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("获取组织仓库列表失败: %w", err)
+			}
+			repos = repos[:0]
+			for _, r := range orgRepos {
+				repos = append(repos, r.Name)
+			}
+		}
+
+		// 单个仓库失败不影响其他仓库的同步
+		report := labelSyncReport{DryRun: dryRun, Results: []api.LabelSyncResult{}, Failed: []labelSyncFailure{}}
+		policy := repoPolicyFromContext(ctx)
+		for _, name := range repos {
+			// 同步组织下的所有仓库时逐个检查策略，被拒绝的仓库记为失败
+			if err := policy.Check(owner, name, true); err != nil {
+				report.Failed = append(report.Failed, labelSyncFailure{Repo: owner + "/" + name, Error: err.Error()})
+				continue
+			}
+			result, err := client.Issues.SyncLabels(ctx, owner, name, spec, dryRun)
+			if result != nil {
+				report.Results = append(report.Results, *result)
+			}
+			if err != nil {
+				report.Failed = append(report.Failed, labelSyncFailure{Repo: owner + "/" + name, Error: err.Error()})
+			}
+		}
+		return FormatJSONResult(report)
+	})
+
+	// 批量重命名或合并标签
+	bulkRelabelTool := mcp.NewTool("bulk_relabel",
+		mcp.WithDescription("将一个或多个标签替换为目标标签。只有一个原标签且目标标签不存在时直接重命名；"+
+			"否则合并：为所有带原标签的Issue和Pull Request加上目标标签并移除原标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithArray("from",
+			mcp.Required(),
+			mcp.Description("原标签名称列表"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("目标标签名称，不存在时以第一个原标签的颜色创建"),
+		),
+		mcp.WithBoolean("delete_old",
+			mcp.Description("合并后是否删除原标签，默认为true"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("为true时只返回受影响的Issue和Pull Request，不实际修改，默认为false"),
+		),
+	)
+	s.AddWriteTool(bulkRelabelTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		from := StringSliceArgument(request, "from")
+		to, _ := request.Params.Arguments["to"].(string)
+		dryRun, _ := request.Params.Arguments["dry_run"].(bool)
+		deleteOld := true
+		if v, ok := request.Params.Arguments["delete_old"].(bool); ok {
+			deleteOld = v
+		}
+
+		result, err := client.Issues.Relabel(ctx, owner, repo, from, to, deleteOld, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("批量替换标签失败: %w", err)
+		}
+		return FormatJSONResult(result)
+	})
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gitcode-org-com/gitcode-mcp/config"
+)
+
+// ErrLocalPathDenied 不允许访问指定的本地路径
+var ErrLocalPathDenied = errors.New("不允许访问本地路径")
+
+// remoteTransport 判断客户端是否通过网络连接，此时工具参数中的本地路径由远程客户端提供
+func remoteTransport() bool {
+	transport := strings.ToLower(config.GlobalConfig.MCPTransport)
+	return transport == "sse" || transport == "http"
+}
+
+// resolveLocalPath 检查工具参数中的本地路径并返回实际访问的路径。
+// 配置了GITCODE_LOCAL_FILE_DIR时，相对路径基于该目录，解析符号链接后必须位于该目录内；
+// SSE/HTTP模式下没有配置该目录时不允许使用本地路径；stdio模式下没有配置时不做限制。
+// forWrite为true时目标文件可以不存在，此时检查其所在目录
+func resolveLocalPath(path string, forWrite bool) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("本地路径不能为空")
+	}
+	base := config.GlobalConfig.LocalFileDir
+	if base == "" {
+		if remoteTransport() {
+			return "", fmt.Errorf("%w: SSE/HTTP模式下只能使用内联内容，如需读写服务器文件请配置GITCODE_LOCAL_FILE_DIR", ErrLocalPathDenied)
+		}
+		return filepath.Clean(path), nil
+	}
+
+	root, err := filepath.Abs(base)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", fmt.Errorf("解析本地文件目录失败: %w", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+
+	// 解析符号链接，防止通过目录内的链接访问目录外的文件
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if !forWrite || !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("解析本地路径失败: %w", err)
+		}
+		dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+		if err != nil {
+			return "", fmt.Errorf("解析本地路径失败: %w", err)
+		}
+		resolved = filepath.Join(dir, filepath.Base(path))
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s 不在本地文件目录 %s 中", ErrLocalPathDenied, path, root)
+	}
+	return resolved, nil
+}
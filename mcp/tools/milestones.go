@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+)
+
+// milestoneOptionsFromRequest 从工具调用参数中读取里程碑参数
+func milestoneOptionsFromRequest(request mcp.CallToolRequest) (api.MilestoneOptions, error) {
+	options := api.MilestoneOptions{}
+	options.Title, _ = request.Params.Arguments["title"].(string)
+	options.Description, _ = request.Params.Arguments["description"].(string)
+	options.State, _ = request.Params.Arguments["state"].(string)
+
+	dueOn, err := TimeArgument(request, "due_on")
+	if err != nil {
+		return options, err
+	}
+	if !dueOn.IsZero() {
+		options.DueOn = dueOn.UTC().Format(time.RFC3339)
+	}
+	return options, nil
+}
+
+// AddMilestoneTools 添加里程碑相关工具到MCP服务器
+func AddMilestoneTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
+	// 列出里程碑
+	listMilestonesTool := mcp.NewTool("list_milestones",
+		mcp.WithDescription("列出仓库的里程碑"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("state",
+			mcp.Description("里程碑状态，默认为open"),
+			mcp.Enum("open", "closed", "all"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listMilestonesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		state, _ := request.Params.Arguments["state"].(string)
+
+		opts := ListOptionsFromRequest(request)
+		milestones, err := client.Milestones.ListMilestones(ctx, owner, repo, state, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取里程碑列表失败: %w", err)
+		}
+		return FormatListResult(milestones, len(milestones), opts)
+	})
+
+	// 获取里程碑
+	getMilestoneTool := mcp.NewTool("get_milestone",
+		mcp.WithDescription("获取里程碑的详细信息"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("milestone_number",
+			mcp.Required(),
+			mcp.Description("里程碑编号"),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(getMilestoneTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		number, _ := request.Params.Arguments["milestone_number"].(float64)
+
+		milestone, err := client.Milestones.GetMilestone(ctx, owner, repo, int(number))
+		if err != nil {
+			return nil, fmt.Errorf("获取里程碑详情失败: %w", err)
+		}
+		return FormatJSONResult(milestone)
+	})
+
+	// 创建里程碑
+	createMilestoneTool := mcp.NewTool("create_milestone",
+		mcp.WithDescription("创建里程碑"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("title",
+			mcp.Required(),
+			mcp.Description("里程碑标题"),
+		),
+		mcp.WithString("description",
+			mcp.Description("里程碑描述"),
+		),
+		mcp.WithString("due_on",
+			mcp.Description("截止时间，RFC3339格式或YYYY-MM-DD"),
+		),
+		mcp.WithString("state",
+			mcp.Description("里程碑状态，默认为open"),
+			mcp.Enum("open", "closed"),
+		),
+	)
+	s.AddWriteTool(createMilestoneTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+
+		options, err := milestoneOptionsFromRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		milestone, err := client.Milestones.CreateMilestone(ctx, owner, repo, options)
+		if err != nil {
+			return nil, fmt.Errorf("创建里程碑失败: %w", err)
+		}
+		return FormatJSONResult(milestone)
+	})
+
+	// 更新里程碑
+	updateMilestoneTool := mcp.NewTool("update_milestone",
+		mcp.WithDescription("更新里程碑的标题、描述、截止时间或状态，未提供的参数保持不变"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("milestone_number",
+			mcp.Required(),
+			mcp.Description("里程碑编号"),
+		),
+		mcp.WithString("title",
+			mcp.Description("新的里程碑标题"),
+		),
+		mcp.WithString("description",
+			mcp.Description("新的里程碑描述"),
+		),
+		mcp.WithString("due_on",
+			mcp.Description("新的截止时间，RFC3339格式或YYYY-MM-DD"),
+		),
+		mcp.WithString("state",
+			mcp.Description("里程碑状态"),
+			mcp.Enum("open", "closed"),
+		),
+	)
+	s.AddWriteTool(updateMilestoneTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		number, _ := request.Params.Arguments["milestone_number"].(float64)
+
+		options, err := milestoneOptionsFromRequest(request)
+		if err != nil {
+			return nil, err
+		}
+
+		milestone, err := client.Milestones.UpdateMilestone(ctx, owner, repo, int(number), options)
+		if err != nil {
+			return nil, fmt.Errorf("更新里程碑失败: %w", err)
+		}
+		return FormatJSONResult(milestone)
+	})
+
+	// 删除里程碑
+	deleteMilestoneTool := mcp.NewTool("delete_milestone",
+		mcp.WithDescription("删除里程碑，关联的Issue和Pull Request不会被删除"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("milestone_number",
+			mcp.Required(),
+			mcp.Description("里程碑编号"),
+		),
+	)
+	s.AddWriteTool(deleteMilestoneTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		number, _ := request.Params.Arguments["milestone_number"].(float64)
+
+		if err := client.Milestones.DeleteMilestone(ctx, owner, repo, int(number)); err != nil {
+			return nil, fmt.Errorf("删除里程碑失败: %w", err)
+		}
+		return TextResult("已删除里程碑 #%d", int(number))
+	})
+
+	// 里程碑进度
+	milestoneProgressTool := mcp.NewTool("milestone_progress",
+		mcp.WithDescription(fmt.Sprintf("统计里程碑中已关闭和未关闭的Issue及Pull Request数量，已过截止时间时列出仍未关闭的条目（最多%d个）", DefaultMaxItems)),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("milestone_number",
+			mcp.Required(),
+			mcp.Description("里程碑编号"),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(milestoneProgressTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		number, _ := request.Params.Arguments["milestone_number"].(float64)
+
+		progress, err := client.Milestones.GetProgress(ctx, owner, repo, int(number), DefaultMaxItems)
+		if err != nil {
+			return nil, fmt.Errorf("获取里程碑进度失败: %w", err)
+		}
+		return FormatJSONResult(progress)
+	})
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+)
+
+// repoPolicyHandled 不针对单个仓库的工具，由处理函数逐个检查目标仓库或过滤返回结果
+var repoPolicyHandled = map[string]bool{
+	"create_repository":      true,
+	"list_repositories":      true,
+	"list_org_repositories":  true,
+	"list_user_repositories": true,
+	"search_code":            true,
+	"search_repositories":    true,
+	"search_issues":          true,
+	"search_commits":         true,
+	"sync_labels":            true,
+}
+
+// repoPolicyExempt 不读写任何仓库数据的工具
+var repoPolicyExempt = map[string]bool{
+	"get_cache_stats": true,
+	"search_users":    true,
+}
+
+// checkRepoPolicy 按仓库访问策略检查工具调用涉及的仓库。只读工具按读取规则检查，其余工具按写入规则检查；
+// transfer_repository的new_owner与repo组成的目标仓库同样按写入规则检查。
+// 其他工具缺少owner或repo参数时拒绝调用，避免未指定仓库的调用绕过策略
+func checkRepoPolicy(policy *api.RepoPolicy, info ToolInfo, request mcp.CallToolRequest) error {
+	if policy == nil || repoPolicyExempt[info.Name] {
+		return nil
+	}
+	owner, _ := request.Params.Arguments["owner"].(string)
+	repo, _ := request.Params.Arguments["repo"].(string)
+	if owner == "" || repo == "" {
+		if repoPolicyHandled[info.Name] {
+			return nil
+		}
+		return fmt.Errorf("%w: 工具%s未指定owner和repo", api.ErrPolicyDenied, info.Name)
+	}
+	if err := policy.Check(owner, repo, !info.ReadOnly); err != nil {
+		return err
+	}
+	if newOwner, _ := request.Params.Arguments["new_owner"].(string); newOwner != "" {
+		return policy.Check(newOwner, repo, true)
+	}
+	return nil
+}
+
+type repoPolicyKey struct{}
+
+// withRepoPolicy 返回携带仓库访问策略的上下文，供需要逐个检查仓库的处理函数使用
+func withRepoPolicy(ctx context.Context, policy *api.RepoPolicy) context.Context {
+	if policy == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, repoPolicyKey{}, policy)
+}
+
+// repoPolicyFromContext 返回上下文中的仓库访问策略，没有加载策略时返回nil
+func repoPolicyFromContext(ctx context.Context) *api.RepoPolicy {
+	policy, _ := ctx.Value(repoPolicyKey{}).(*api.RepoPolicy)
+	return policy
+}
+
+// splitFullName 将owner/repo格式的仓库全名拆分为所有者和仓库名称
+func splitFullName(fullName string) (string, string, bool) {
+	owner, repo, ok := strings.Cut(fullName, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", false
+	}
+	return owner, repo, true
+}
+
+// repoFullName 返回仓库的owner/repo全名
+func repoFullName(repo api.Repository) string {
+	if repo.FullName != "" {
+		return repo.FullName
+	}
+	if repo.Owner.Username != "" && repo.Name != "" {
+		return repo.Owner.Username + "/" + repo.Name
+	}
+	return ""
+}
+
+// repoFromAPIURL 从API地址（如.../repos/owner/repo/issues/1）中读取仓库全名
+func repoFromAPIURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+2 < len(segments); i++ {
+		if segments[i] == "repos" {
+			return segments[i+1] + "/" + segments[i+2]
+		}
+	}
+	return ""
+}
+
+// allowsRead 判断策略是否允许读取仓库，无法确定所属仓库的数据一律不允许
+func allowsRead(policy *api.RepoPolicy, fullName string) bool {
+	if policy == nil {
+		return true
+	}
+	owner, repo, ok := splitFullName(fullName)
+	return ok && policy.Check(owner, repo, false) == nil
+}
+
+// filterByRepo 只保留策略允许读取的仓库中的数据
+func filterByRepo[T any](ctx context.Context, items []T, fullName func(T) string) []T {
+	policy := repoPolicyFromContext(ctx)
+	if policy == nil {
+		return items
+	}
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		if allowsRead(policy, fullName(item)) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// filterRepos 只保留策略允许读取的仓库
+func filterRepos(ctx context.Context, repos []api.Repository) []api.Repository {
+	return filterByRepo(ctx, repos, repoFullName)
+}
@@ -5,16 +5,15 @@ import (
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	
 	"github.com/gitcode-org-com/gitcode-mcp/api"
 )
 
 // AddPullRequestTools 添加Pull Request相关工具到MCP服务器
-func AddPullRequestTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
+func AddPullRequestTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
 	// 列出Pull Requests
 	listPRsTool := mcp.NewTool("list_pull_requests",
-		mcp.WithDescription("列出仓库的Pull Requests"),
+		mcp.WithDescription("列出仓库的Pull Requests，可按状态、标签、负责人、创建者、里程碑和更新时间过滤"),
 		mcp.WithString("owner",
 			mcp.Required(),
 			mcp.Description("仓库所有者"),
@@ -23,16 +22,27 @@ func AddPullRequestTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("仓库名称"),
 		),
+		WithIssueFilters(),
+		WithPagination(),
+		WithFresh(),
 	)
-	s.AddTool(listPRsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(listPRsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
 		
-		prs, err := apiClient.Pulls.ListPullRequests(owner, repo)
+		filter, err := IssueFilterFromRequest(request)
+		if err != nil {
+			return nil, err
+		}
+		
+		opts := ListOptionsFromRequest(request)
+		prs, err := client.Pulls.ListPullRequests(ctx, owner, repo, filter, opts)
 		if err != nil {
 			return nil, fmt.Errorf("获取Pull Requests列表失败: %w", err)
 		}
-		return FormatJSONResult(prs)
+		return FormatListResult(prs, len(prs), opts)
 	})
 	
 	// 获取Pull Request
@@ -50,13 +60,16 @@ func AddPullRequestTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("Pull Request编号"),
 		),
+		WithFresh(),
 	)
-	s.AddTool(getPRTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(getPRTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
 		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
 		
-		pr, err := apiClient.Pulls.GetPullRequest(owner, repo, int(prNumber))
+		pr, err := client.Pulls.GetPullRequest(ctx, owner, repo, int(prNumber))
 		if err != nil {
 			return nil, fmt.Errorf("获取Pull Request详情失败: %w", err)
 		}
@@ -89,19 +102,400 @@ func AddPullRequestTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 		mcp.WithString("body",
 			mcp.Description("Pull Request内容"),
 		),
+		mcp.WithNumber("milestone",
+			mcp.Description("关联的里程碑编号"),
+		),
 	)
-	s.AddTool(createPRTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddWriteTool(createPRTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
-		title, _ := request.Params.Arguments["title"].(string)
-		head, _ := request.Params.Arguments["head"].(string)
-		base, _ := request.Params.Arguments["base"].(string)
-		body, _ := request.Params.Arguments["body"].(string)
 		
-		pr, err := apiClient.Pulls.CreatePullRequest(owner, repo, title, head, base, body)
+		options := api.CreatePullRequestOptions{}
+		options.Title, _ = request.Params.Arguments["title"].(string)
+		options.Head, _ = request.Params.Arguments["head"].(string)
+		options.Base, _ = request.Params.Arguments["base"].(string)
+		options.Body, _ = request.Params.Arguments["body"].(string)
+		if milestone, ok := request.Params.Arguments["milestone"].(float64); ok {
+			options.Milestone = int(milestone)
+		}
+		
+		pr, err := client.Pulls.CreatePullRequest(ctx, owner, repo, options)
 		if err != nil {
 			return nil, fmt.Errorf("创建Pull Request失败: %w", err)
 		}
 		return FormatJSONResult(pr)
 	})
-} 
\ No newline at end of file
+	
+	// 更新Pull Request
+	updatePRTool := mcp.NewTool("update_pull_request",
+		mcp.WithDescription("更新Pull Request的标题、内容、状态、目标分支或里程碑"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		mcp.WithString("title",
+			mcp.Description("新的Pull Request标题"),
+		),
+		mcp.WithString("body",
+			mcp.Description("新的Pull Request内容"),
+		),
+		mcp.WithString("state",
+			mcp.Description("Pull Request状态"),
+			mcp.Enum("open", "closed"),
+		),
+		mcp.WithString("base",
+			mcp.Description("新的目标分支"),
+		),
+		mcp.WithNumber("milestone",
+			mcp.Description("关联的里程碑编号，0表示移除里程碑"),
+		),
+	)
+	s.AddWriteTool(updatePRTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		
+		options := api.UpdatePullRequestOptions{}
+		options.Title, _ = request.Params.Arguments["title"].(string)
+		options.Body, _ = request.Params.Arguments["body"].(string)
+		options.State, _ = request.Params.Arguments["state"].(string)
+		options.Base, _ = request.Params.Arguments["base"].(string)
+		if milestone, ok := request.Params.Arguments["milestone"].(float64); ok {
+			number := int(milestone)
+			options.Milestone = &number
+		}
+		
+		pr, err := client.Pulls.UpdatePullRequest(ctx, owner, repo, int(prNumber), options)
+		if err != nil {
+			return nil, fmt.Errorf("更新Pull Request失败: %w", err)
+		}
+		return FormatJSONResult(pr)
+	})
+	
+	// 关闭Pull Request
+	closePRTool := mcp.NewTool("close_pull_request",
+		mcp.WithDescription("关闭Pull Request"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+	)
+	s.AddWriteTool(closePRTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		
+		pr, err := client.Pulls.ClosePullRequest(ctx, owner, repo, int(prNumber))
+		if err != nil {
+			return nil, fmt.Errorf("关闭Pull Request失败: %w", err)
+		}
+		return FormatJSONResult(pr)
+	})
+	
+	// 合并Pull Request
+	mergePRTool := mcp.NewTool("merge_pull_request",
+		mcp.WithDescription("合并Pull Request"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		mcp.WithString("merge_method",
+			mcp.Description("合并方式"),
+			mcp.Enum("merge", "squash", "rebase"),
+		),
+		mcp.WithString("commit_title",
+			mcp.Description("合并提交的标题"),
+		),
+		mcp.WithString("commit_message",
+			mcp.Description("合并提交的说明"),
+		),
+		mcp.WithString("sha",
+			mcp.Description("期望的源分支最新提交SHA，不一致时拒绝合并"),
+		),
+		mcp.WithBoolean("delete_branch_after",
+			mcp.Description("合并后是否删除源分支"),
+		),
+	)
+	s.AddWriteTool(mergePRTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		
+		options := api.MergeOptions{}
+		options.MergeMethod, _ = request.Params.Arguments["merge_method"].(string)
+		options.CommitTitle, _ = request.Params.Arguments["commit_title"].(string)
+		options.CommitMessage, _ = request.Params.Arguments["commit_message"].(string)
+		options.SHA, _ = request.Params.Arguments["sha"].(string)
+		options.DeleteBranchAfter, _ = request.Params.Arguments["delete_branch_after"].(bool)
+		
+		result, err := client.Pulls.MergePullRequest(ctx, owner, repo, int(prNumber), options)
+		if err != nil {
+			return nil, fmt.Errorf("合并Pull Request失败: %w", err)
+		}
+		return FormatJSONResult(result)
+	})
+	
+	// 检查Pull Request是否可合并
+	mergeableTool := mcp.NewTool("check_pull_request_mergeable",
+		mcp.WithDescription("检查Pull Request是否可以合并"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(mergeableTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		
+		mergeable, err := client.Pulls.IsPRMergeable(ctx, owner, repo, int(prNumber))
+		if err != nil {
+			return nil, fmt.Errorf("检查Pull Request是否可合并失败: %w", err)
+		}
+		return FormatJSONResult(map[string]interface{}{"mergeable": mergeable})
+	})
+	
+	// 列出Pull Request的代码审查
+	listReviewsTool := mcp.NewTool("list_pull_request_reviews",
+		mcp.WithDescription("列出Pull Request的代码审查"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listReviewsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		
+		opts := ListOptionsFromRequest(request)
+		reviews, err := client.Pulls.ListPRReviews(ctx, owner, repo, int(prNumber), opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取代码审查列表失败: %w", err)
+		}
+		return FormatListResult(reviews, len(reviews), opts)
+	})
+	
+	// 创建Pull Request代码审查
+	createReviewTool := mcp.NewTool("create_pull_request_review",
+		mcp.WithDescription("为Pull Request提交代码审查，可附带行内评论。行内评论的位置会先与diff核对，有无效位置时不提交并列出所有问题"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		mcp.WithString("event",
+			mcp.Required(),
+			mcp.Description("审查结论"),
+			mcp.Enum("APPROVE", "REQUEST_CHANGES", "COMMENT"),
+		),
+		mcp.WithString("body",
+			mcp.Description("审查意见"),
+		),
+		mcp.WithArray("comments",
+			mcp.Description("行内评论列表，每项包含path、body，以及line（可配合side）或position"),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path":     map[string]interface{}{"type": "string", "description": "文件路径"},
+					"line":     map[string]interface{}{"type": "number", "description": "文件中的行号，见get_pull_request_diff中的行号列"},
+					"side":     map[string]interface{}{"type": "string", "enum": []string{"RIGHT", "LEFT"}, "description": "RIGHT为新文件的行号（默认），LEFT为原文件中被删除的行"},
+					"position": map[string]interface{}{"type": "number", "description": "在该文件diff中的行位置，提供line时忽略"},
+					"body":     map[string]interface{}{"type": "string", "description": "评论内容"},
+				},
+				"required": []string{"path", "body"},
+			}),
+		),
+	)
+	s.AddWriteTool(createReviewTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		event, _ := request.Params.Arguments["event"].(string)
+		body, _ := request.Params.Arguments["body"].(string)
+		
+		var comments []api.DraftReviewComment
+		items, _ := request.Params.Arguments["comments"].([]interface{})
+		for _, item := range items {
+			comment, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			draft := api.DraftReviewComment{}
+			draft.Path, _ = comment["path"].(string)
+			draft.Body, _ = comment["body"].(string)
+			draft.Side, _ = comment["side"].(string)
+			if line, ok := comment["line"].(float64); ok {
+				draft.Line = int(line)
+			}
+			if position, ok := comment["position"].(float64); ok {
+				draft.Position = int(position)
+			}
+			comments = append(comments, draft)
+		}
+		
+		review, err := client.Pulls.CreatePRReview(ctx, owner, repo, int(prNumber), body, event, comments)
+		if err != nil {
+			return nil, fmt.Errorf("提交代码审查失败: %w", err)
+		}
+		return FormatJSONResult(review)
+	})
+	
+	// 列出Pull Request评论
+	listPRCommentsTool := mcp.NewTool("list_pull_request_comments",
+		mcp.WithDescription("列出Pull Request的评论"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listPRCommentsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		
+		opts := ListOptionsFromRequest(request)
+		comments, err := client.Pulls.ListPRComments(ctx, owner, repo, int(prNumber), opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取Pull Request评论列表失败: %w", err)
+		}
+		return FormatListResult(comments, len(comments), opts)
+	})
+	
+	// 列出Pull Request文件
+	listFilesTool := mcp.NewTool("list_pull_request_files",
+		mcp.WithDescription("列出Pull Request修改的文件"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listFilesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		
+		opts := ListOptionsFromRequest(request)
+		files, err := client.Pulls.ListFiles(ctx, owner, repo, int(prNumber), opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取Pull Request文件列表失败: %w", err)
+		}
+		return FormatListResult(files, len(files), opts)
+	})
+	
+	// 列出Pull Request提交
+	listPRCommitsTool := mcp.NewTool("list_pull_request_commits",
+		mcp.WithDescription("列出Pull Request包含的提交"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listPRCommitsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		
+		opts := ListOptionsFromRequest(request)
+		commits, err := client.Pulls.ListCommits(ctx, owner, repo, int(prNumber), opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取Pull Request提交列表失败: %w", err)
+		}
+		return FormatListResult(commits, len(commits), opts)
+	})
+}
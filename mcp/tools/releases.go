@@ -0,0 +1,513 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+	"github.com/gitcode-org-com/gitcode-mcp/config"
+)
+
+// MaxInlineAssetBytes 不提供dest_path时以base64返回的附件最大字节数，更大的附件需要下载到服务器本地
+const MaxInlineAssetBytes = 1 << 20
+
+// releaseAssetContent 表示以base64返回的附件内容
+type releaseAssetContent struct {
+	Name    string `json:"name"`
+	Size    int    `json:"size"`
+	Content string `json:"content"` // base64编码
+}
+
+// releaseOptionsFromRequest 从工具调用参数中读取发布版本参数，未提供的布尔参数保持为空
+func releaseOptionsFromRequest(request mcp.CallToolRequest) api.ReleaseOptions {
+	options := api.ReleaseOptions{}
+	options.TagName, _ = request.Params.Arguments["tag_name"].(string)
+	options.TargetCommitish, _ = request.Params.Arguments["target_commitish"].(string)
+	options.Name, _ = request.Params.Arguments["name"].(string)
+	options.Body, _ = request.Params.Arguments["body"].(string)
+	if draft, ok := request.Params.Arguments["draft"].(bool); ok {
+		options.Draft = &draft
+	}
+	if prerelease, ok := request.Params.Arguments["prerelease"].(bool); ok {
+		options.Prerelease = &prerelease
+	}
+	return options
+}
+
+// AddReleaseTools 添加发布版本和标签相关工具到MCP服务器
+func AddReleaseTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
+	// 列出发布版本
+	listReleasesTool := mcp.NewTool("list_releases",
+		mcp.WithDescription("列出仓库的发布版本"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listReleasesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+
+		opts := ListOptionsFromRequest(request)
+		releases, err := client.Releases.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取发布版本列表失败: %w", err)
+		}
+		return FormatListResult(releases, len(releases), opts)
+	})
+
+	// 获取发布版本
+	getReleaseTool := mcp.NewTool("get_release",
+		mcp.WithDescription("根据ID或标签名获取发布版本的详细信息"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("release_id",
+			mcp.Description("发布版本ID，与tag二选一"),
+		),
+		mcp.WithString("tag",
+			mcp.Description("发布版本对应的标签名，与release_id二选一"),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(getReleaseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		releaseID, _ := request.Params.Arguments["release_id"].(float64)
+		tag, _ := request.Params.Arguments["tag"].(string)
+
+		var release *api.Release
+		var err error
+		switch {
+		case releaseID > 0:
+			release, err = client.Releases.GetRelease(ctx, owner, repo, int(releaseID))
+		case tag != "":
+			release, err = client.Releases.GetReleaseByTag(ctx, owner, repo, tag)
+		default:
+			return nil, fmt.Errorf("需要提供release_id或tag")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("获取发布版本详情失败: %w", err)
+		}
+		return FormatJSONResult(release)
+	})
+
+	// 获取最新发布版本
+	latestReleaseTool := mcp.NewTool("latest_release",
+		mcp.WithDescription("获取仓库最新的正式发布版本，不包括草稿和预发布版本"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(latestReleaseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+
+		release, err := client.Releases.GetLatestRelease(ctx, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("获取最新发布版本失败: %w", err)
+		}
+		return FormatJSONResult(release)
+	})
+
+	// 创建发布版本
+	createReleaseTool := mcp.NewTool("create_release",
+		mcp.WithDescription("创建发布版本，标签不存在时基于target_commitish创建"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("tag_name",
+			mcp.Required(),
+			mcp.Description("标签名"),
+		),
+		mcp.WithString("target_commitish",
+			mcp.Description("标签不存在时创建标签所基于的分支名或提交SHA，默认为仓库的默认分支"),
+		),
+		mcp.WithString("name",
+			mcp.Description("发布版本标题"),
+		),
+		mcp.WithString("body",
+			mcp.Description("发布说明"),
+		),
+		mcp.WithBoolean("draft",
+			mcp.Description("是否为草稿"),
+		),
+		mcp.WithBoolean("prerelease",
+			mcp.Description("是否为预发布版本"),
+		),
+	)
+	s.AddWriteTool(createReleaseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+
+		release, err := client.Releases.CreateRelease(ctx, owner, repo, releaseOptionsFromRequest(request))
+		if err != nil {
+			return nil, fmt.Errorf("创建发布版本失败: %w", err)
+		}
+		return FormatJSONResult(release)
+	})
+
+	// 更新发布版本
+	updateReleaseTool := mcp.NewTool("update_release",
+		mcp.WithDescription("更新发布版本的标签、标题、说明或状态，未提供的参数保持不变"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("release_id",
+			mcp.Required(),
+			mcp.Description("发布版本ID"),
+		),
+		mcp.WithString("tag_name",
+			mcp.Description("新的标签名"),
+		),
+		mcp.WithString("name",
+			mcp.Description("新的发布版本标题"),
+		),
+		mcp.WithString("body",
+			mcp.Description("新的发布说明"),
+		),
+		mcp.WithBoolean("draft",
+			mcp.Description("是否为草稿，设为false即正式发布"),
+		),
+		mcp.WithBoolean("prerelease",
+			mcp.Description("是否为预发布版本"),
+		),
+	)
+	s.AddWriteTool(updateReleaseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		releaseID, _ := request.Params.Arguments["release_id"].(float64)
+
+		release, err := client.Releases.UpdateRelease(ctx, owner, repo, int(releaseID), releaseOptionsFromRequest(request))
+		if err != nil {
+			return nil, fmt.Errorf("更新发布版本失败: %w", err)
+		}
+		return FormatJSONResult(release)
+	})
+
+	// 删除发布版本
+	deleteReleaseTool := mcp.NewTool("delete_release",
+		mcp.WithDescription("删除发布版本，对应的标签不会被删除"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("release_id",
+			mcp.Required(),
+			mcp.Description("发布版本ID"),
+		),
+	)
+	s.AddWriteTool(deleteReleaseTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		releaseID, _ := request.Params.Arguments["release_id"].(float64)
+
+		if err := client.Releases.DeleteRelease(ctx, owner, repo, int(releaseID)); err != nil {
+			return nil, fmt.Errorf("删除发布版本失败: %w", err)
+		}
+		return TextResult("已删除发布版本 %d", int(releaseID))
+	})
+
+	// 列出发布版本附件
+	listAssetsTool := mcp.NewTool("list_release_assets",
+		mcp.WithDescription("列出发布版本的附件"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("release_id",
+			mcp.Required(),
+			mcp.Description("发布版本ID"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listAssetsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		releaseID, _ := request.Params.Arguments["release_id"].(float64)
+
+		opts := ListOptionsFromRequest(request)
+		assets, err := client.Releases.ListReleaseAssets(ctx, owner, repo, int(releaseID), opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取附件列表失败: %w", err)
+		}
+		return FormatListResult(assets, len(assets), opts)
+	})
+
+	// 上传发布版本附件
+	uploadAssetTool := mcp.NewTool("upload_release_asset",
+		mcp.WithDescription("上传发布版本的附件，内容通过content以base64提供，或通过file_path指定服务器本地文件（SSE/HTTP模式下需配置GITCODE_LOCAL_FILE_DIR），大小受RELEASE_ASSET_MAX_BYTES限制"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("release_id",
+			mcp.Required(),
+			mcp.Description("发布版本ID"),
+		),
+		mcp.WithString("content",
+			mcp.Description("base64编码的附件内容，与file_path二选一"),
+		),
+		mcp.WithString("file_path",
+			mcp.Description("要上传的服务器本地文件路径，与content二选一"),
+		),
+		mcp.WithString("name",
+			mcp.Description("附件名称，使用file_path时默认为文件名，使用content时必须提供"),
+		),
+	)
+	s.AddWriteTool(uploadAssetTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		releaseID, _ := request.Params.Arguments["release_id"].(float64)
+		content, _ := request.Params.Arguments["content"].(string)
+		filePath, _ := request.Params.Arguments["file_path"].(string)
+		name, _ := request.Params.Arguments["name"].(string)
+
+		maxBytes := int64(config.GlobalConfig.ReleaseAssetMaxBytes)
+		var asset *api.ReleaseAsset
+		var err error
+		switch {
+		case content != "" && filePath != "":
+			return nil, fmt.Errorf("content和file_path只能提供一个")
+		case content != "":
+			data, decodeErr := base64.StdEncoding.DecodeString(content)
+			if decodeErr != nil {
+				return nil, fmt.Errorf("content不是有效的base64编码: %w", decodeErr)
+			}
+			asset, err = client.Releases.UploadReleaseAssetContent(ctx, owner, repo, int(releaseID), name, data, maxBytes)
+		case filePath != "":
+			path, pathErr := resolveLocalPath(filePath, false)
+			if pathErr != nil {
+				return nil, pathErr
+			}
+			asset, err = client.Releases.UploadReleaseAsset(ctx, owner, repo, int(releaseID), path, name, maxBytes)
+		default:
+			return nil, fmt.Errorf("需要提供content或file_path")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("上传附件失败: %w", err)
+		}
+		return FormatJSONResult(asset)
+	})
+
+	// 下载发布版本附件
+	downloadAssetTool := mcp.NewTool("download_release_asset",
+		mcp.WithDescription("下载发布版本的附件，提供dest_path时保存到服务器本地路径（SSE/HTTP模式下需配置GITCODE_LOCAL_FILE_DIR），否则以base64返回内容（不超过1MB），大小受RELEASE_ASSET_MAX_BYTES限制"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("release_id",
+			mcp.Required(),
+			mcp.Description("发布版本ID"),
+		),
+		mcp.WithNumber("asset_id",
+			mcp.Description("附件ID，与asset_name二选一"),
+		),
+		mcp.WithString("asset_name",
+			mcp.Description("附件名称，与asset_id二选一"),
+		),
+		mcp.WithString("dest_path",
+			mcp.Description("保存到的服务器本地文件路径，不提供时以base64返回附件内容，超过1MB的附件必须提供"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("目标文件已存在时是否覆盖，默认为false"),
+		),
+	)
+	s.AddWriteTool(downloadAssetTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		releaseID, _ := request.Params.Arguments["release_id"].(float64)
+		assetID, _ := request.Params.Arguments["asset_id"].(float64)
+		assetName, _ := request.Params.Arguments["asset_name"].(string)
+		destPath, _ := request.Params.Arguments["dest_path"].(string)
+		overwrite, _ := request.Params.Arguments["overwrite"].(bool)
+
+		if assetID <= 0 && assetName == "" {
+			return nil, fmt.Errorf("需要提供asset_id或asset_name")
+		}
+		if destPath != "" {
+			path, err := resolveLocalPath(destPath, true)
+			if err != nil {
+				return nil, err
+			}
+			destPath = path
+			if _, err := os.Stat(destPath); err == nil && !overwrite {
+				return nil, fmt.Errorf("文件 %s 已存在，如需覆盖请设置overwrite为true", destPath)
+			}
+		}
+
+		assets, err := client.Releases.ListReleaseAssets(ctx, owner, repo, int(releaseID), &api.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("获取附件列表失败: %w", err)
+		}
+
+		var asset *api.ReleaseAsset
+		for i := range assets {
+			if (assetID > 0 && assets[i].ID == int(assetID)) || (assetID <= 0 && assets[i].Name == assetName) {
+				asset = &assets[i]
+				break
+			}
+		}
+		if asset == nil {
+			return nil, fmt.Errorf("发布版本 %d 中没有找到指定的附件", int(releaseID))
+		}
+
+		maxBytes := int64(config.GlobalConfig.ReleaseAssetMaxBytes)
+		if destPath == "" {
+			// base64内容会完整放入响应，限制在较小的大小内；配置的上限更小时以配置为准
+			limit := maxBytes
+			inlineLimited := limit == 0 || limit > MaxInlineAssetBytes
+			if inlineLimited {
+				limit = MaxInlineAssetBytes
+			}
+			data, err := client.Releases.DownloadReleaseAssetContent(ctx, asset, limit)
+			if errors.Is(err, api.ErrTooLarge) && inlineLimited {
+				return nil, fmt.Errorf("下载附件失败: %w，以base64返回的附件不能超过%d字节，请提供dest_path下载到服务器本地", err, MaxInlineAssetBytes)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("下载附件失败: %w", err)
+			}
+			return FormatJSONResult(releaseAssetContent{
+				Name:    asset.Name,
+				Size:    len(data),
+				Content: base64.StdEncoding.EncodeToString(data),
+			})
+		}
+		n, err := client.Releases.DownloadReleaseAsset(ctx, asset, destPath, maxBytes)
+		if err != nil {
+			return nil, fmt.Errorf("下载附件失败: %w", err)
+		}
+		return TextResult("已将附件 %s 下载到 %s，共%d字节", asset.Name, destPath, n)
+	})
+
+	// 列出标签
+	listTagsTool := mcp.NewTool("list_tags",
+		mcp.WithDescription("列出仓库的标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listTagsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+
+		opts := ListOptionsFromRequest(request)
+		tags, err := client.Releases.ListTags(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取标签列表失败: %w", err)
+		}
+		return FormatListResult(tags, len(tags), opts)
+	})
+
+	// 创建标签
+	createTagTool := mcp.NewTool("create_tag",
+		mcp.WithDescription("创建标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("tag_name",
+			mcp.Required(),
+			mcp.Description("标签名"),
+		),
+		mcp.WithString("ref",
+			mcp.Required(),
+			mcp.Description("标签指向的分支名或提交SHA"),
+		),
+		mcp.WithString("message",
+			mcp.Description("标签说明，提供时创建附注标签"),
+		),
+	)
+	s.AddWriteTool(createTagTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+
+		options := api.CreateTagOptions{}
+		options.TagName, _ = request.Params.Arguments["tag_name"].(string)
+		options.Refs, _ = request.Params.Arguments["ref"].(string)
+		options.TagMessage, _ = request.Params.Arguments["message"].(string)
+
+		tag, err := client.Releases.CreateTag(ctx, owner, repo, options)
+		if err != nil {
+			return nil, fmt.Errorf("创建标签失败: %w", err)
+		}
+		return FormatJSONResult(tag)
+	})
+}
@@ -5,23 +5,28 @@ import (
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	
 	"github.com/gitcode-org-com/gitcode-mcp/api"
 )
 
 // AddRepositoryTools 添加仓库相关工具到MCP服务器
-func AddRepositoryTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
+func AddRepositoryTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
 	// 列出用户仓库
 	listReposTool := mcp.NewTool("list_repositories",
 		mcp.WithDescription("列出当前用户的仓库"),
+		WithPagination(),
+		WithFresh(),
 	)
-	s.AddTool(listReposTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		repos, err := apiClient.Repos.ListUserRepos()
+	s.AddReadTool(listReposTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		opts := ListOptionsFromRequest(request)
+		repos, err := client.Repos.ListUserRepos(ctx, opts)
 		if err != nil {
 			return nil, fmt.Errorf("获取仓库列表失败: %w", err)
 		}
-		return FormatJSONResult(repos)
+		repos = filterRepos(ctx, repos)
+		return FormatListResult(repos, len(repos), opts)
 	})
 	
 	// 获取仓库
@@ -35,12 +40,15 @@ func AddRepositoryTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("仓库名称"),
 		),
+		WithFresh(),
 	)
-	s.AddTool(getRepoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(getRepoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		owner, _ := request.Params.Arguments["owner"].(string)
 		repo, _ := request.Params.Arguments["repo"].(string)
 		
-		repository, err := apiClient.Repos.GetRepo(owner, repo)
+		repository, err := client.Repos.GetRepo(ctx, owner, repo)
 		if err != nil {
 			return nil, fmt.Errorf("获取仓库详情失败: %w", err)
 		}
@@ -61,15 +69,280 @@ func AddRepositoryTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Description("是否为私有仓库"),
 		),
 	)
-	s.AddTool(createRepoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddWriteTool(createRepoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
 		name, _ := request.Params.Arguments["name"].(string)
 		description, _ := request.Params.Arguments["description"].(string)
 		private, _ := request.Params.Arguments["private"].(bool)
 		
-		repo, err := apiClient.Repos.CreateRepo(name, description, private)
+		// 新仓库属于当前用户，按当前用户名检查写入规则
+		if policy := repoPolicyFromContext(ctx); policy != nil {
+			user, err := client.Repos.GetCurrentUser(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("获取当前用户失败: %w", err)
+			}
+			if user.Username == "" {
+				return nil, fmt.Errorf("%w: 无法确定新仓库的所有者", api.ErrPolicyDenied)
+			}
+			if err := policy.Check(user.Username, name, true); err != nil {
+				return nil, err
+			}
+		}
+		
+		repo, err := client.Repos.CreateRepo(ctx, name, description, private)
 		if err != nil {
 			return nil, fmt.Errorf("创建仓库失败: %w", err)
 		}
 		return FormatJSONResult(repo)
 	})
-} 
\ No newline at end of file
+	
+	// 更新仓库
+	updateRepoTool := mcp.NewTool("update_repository",
+		mcp.WithDescription("更新仓库信息"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("name",
+			mcp.Description("新的仓库名称"),
+		),
+		mcp.WithString("description",
+			mcp.Description("新的仓库描述"),
+		),
+		mcp.WithString("homepage",
+			mcp.Description("仓库主页地址"),
+		),
+		mcp.WithString("default_branch",
+			mcp.Description("默认分支"),
+		),
+		mcp.WithBoolean("private",
+			mcp.Description("是否为私有仓库"),
+		),
+	)
+	s.AddWriteTool(updateRepoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		
+		// 只提交调用方给出的字段
+		options := map[string]interface{}{}
+		for _, field := range []string{"name", "description", "homepage", "default_branch", "private"} {
+			if value, ok := request.Params.Arguments[field]; ok {
+				options[field] = value
+			}
+		}
+		if len(options) == 0 {
+			return nil, fmt.Errorf("更新仓库失败: 未提供任何要更新的字段")
+		}
+		
+		repository, err := client.Repos.UpdateRepo(ctx, owner, repo, options)
+		if err != nil {
+			return nil, fmt.Errorf("更新仓库失败: %w", err)
+		}
+		return FormatJSONResult(repository)
+	})
+	
+	// 删除仓库
+	deleteRepoTool := mcp.NewTool("delete_repository",
+		mcp.WithDescription("删除仓库。该操作不可恢复，请谨慎使用"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+	)
+	s.AddWriteTool(deleteRepoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		
+		if err := client.Repos.DeleteRepo(ctx, owner, repo); err != nil {
+			return nil, fmt.Errorf("删除仓库失败: %w", err)
+		}
+		return TextResult("已删除仓库 %s/%s", owner, repo)
+	})
+	
+	// 转移仓库
+	transferRepoTool := mcp.NewTool("transfer_repository",
+		mcp.WithDescription("将仓库转移给其他用户或组织"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("new_owner",
+			mcp.Required(),
+			mcp.Description("新的所有者"),
+		),
+	)
+	s.AddWriteTool(transferRepoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		newOwner, _ := request.Params.Arguments["new_owner"].(string)
+		
+		repository, err := client.Repos.TransferRepo(ctx, owner, repo, newOwner)
+		if err != nil {
+			return nil, fmt.Errorf("转移仓库失败: %w", err)
+		}
+		return FormatJSONResult(repository)
+	})
+	
+	// 列出组织仓库
+	listOrgReposTool := mcp.NewTool("list_org_repositories",
+		mcp.WithDescription("列出组织的仓库"),
+		mcp.WithString("org",
+			mcp.Required(),
+			mcp.Description("组织名称"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listOrgReposTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		org, _ := request.Params.Arguments["org"].(string)
+		
+		opts := ListOptionsFromRequest(request)
+		repos, err := client.Repos.ListReposByOrg(ctx, org, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取组织仓库列表失败: %w", err)
+		}
+		repos = filterRepos(ctx, repos)
+		return FormatListResult(repos, len(repos), opts)
+	})
+	
+	// 列出指定用户的仓库
+	listUserReposTool := mcp.NewTool("list_user_repositories",
+		mcp.WithDescription("列出指定用户的仓库"),
+		mcp.WithString("username",
+			mcp.Required(),
+			mcp.Description("用户名"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listUserReposTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		username, _ := request.Params.Arguments["username"].(string)
+		
+		opts := ListOptionsFromRequest(request)
+		repos, err := client.Repos.ListReposByUser(ctx, username, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取用户仓库列表失败: %w", err)
+		}
+		repos = filterRepos(ctx, repos)
+		return FormatListResult(repos, len(repos), opts)
+	})
+	
+	// 列出星标用户
+	listStargazersTool := mcp.NewTool("list_stargazers",
+		mcp.WithDescription("列出为仓库添加星标的用户"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(listStargazersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		
+		opts := ListOptionsFromRequest(request)
+		users, err := client.Repos.ListStargazers(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("获取星标用户列表失败: %w", err)
+		}
+		return FormatListResult(users, len(users), opts)
+	})
+	
+	// 添加星标
+	starRepoTool := mcp.NewTool("star_repository",
+		mcp.WithDescription("为仓库添加星标"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+	)
+	s.AddWriteTool(starRepoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		
+		if err := client.Repos.StarRepo(ctx, owner, repo); err != nil {
+			return nil, fmt.Errorf("添加星标失败: %w", err)
+		}
+		return TextResult("已为 %s/%s 添加星标", owner, repo)
+	})
+	
+	// 取消星标
+	unstarRepoTool := mcp.NewTool("unstar_repository",
+		mcp.WithDescription("取消仓库星标"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+	)
+	s.AddWriteTool(unstarRepoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		
+		if err := client.Repos.UnstarRepo(ctx, owner, repo); err != nil {
+			return nil, fmt.Errorf("取消星标失败: %w", err)
+		}
+		return TextResult("已取消 %s/%s 的星标", owner, repo)
+	})
+	
+	// 检查是否已添加星标
+	checkStarredTool := mcp.NewTool("check_starred",
+		mcp.WithDescription("检查当前用户是否已为仓库添加星标"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(checkStarredTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		
+		starred, err := client.Repos.CheckIfStarred(ctx, owner, repo)
+		if err != nil {
+			return nil, fmt.Errorf("检查星标状态失败: %w", err)
+		}
+		return FormatJSONResult(map[string]interface{}{"starred": starred})
+	})
+}
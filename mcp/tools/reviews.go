@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+)
+
+// DefaultDiffMaxTokens get_pull_request_diff每页默认的token预算
+const DefaultDiffMaxTokens = 8000
+
+// AddReviewTools 添加代码审查相关工具到MCP服务器
+func AddReviewTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
+	// 获取Pull Request的diff
+	getDiffTool := mcp.NewTool("get_pull_request_diff",
+		mcp.WithDescription("获取Pull Request的unified diff。默认在每行前标出原文件和新文件的行号，"+
+			"提交行内评论时用新文件行号作为line，评论被删除的行时用原文件行号并设置side为LEFT。"+
+			"diff超过max_tokens时在diff段（@@）之间分页，用page依次获取"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		mcp.WithArray("files",
+			mcp.Description("只返回这些路径的文件"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithArray("include",
+			mcp.Description("只返回匹配任意一个glob模式的文件，如src/**/*.go；不含/的模式只匹配文件名"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("排除匹配任意一个glob模式的文件，如**/*_test.go、*.lock"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("max_tokens",
+			mcp.Description(fmt.Sprintf("每页的token预算（估算值），默认%d，0表示不分页", DefaultDiffMaxTokens)),
+		),
+		mcp.WithNumber("page",
+			mcp.Description("页码，从1开始，默认为1"),
+		),
+		mcp.WithBoolean("line_numbers",
+			mcp.Description("是否在每行前标出原文件和新文件的行号，默认为true"),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(getDiffTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		lineNumbers := true
+		if v, ok := request.Params.Arguments["line_numbers"].(bool); ok {
+			lineNumbers = v
+		}
+		maxTokens := DefaultDiffMaxTokens
+		if v, ok := request.Params.Arguments["max_tokens"].(float64); ok && v >= 0 {
+			maxTokens = int(v)
+		}
+		page := 1
+		if v, ok := request.Params.Arguments["page"].(float64); ok && v > 1 {
+			page = int(v)
+		}
+		filter := &api.DiffFilter{
+			Files:   StringSliceArgument(request, "files"),
+			Include: StringSliceArgument(request, "include"),
+			Exclude: StringSliceArgument(request, "exclude"),
+		}
+
+		diffs, err := client.Pulls.GetDiff(ctx, owner, repo, int(prNumber))
+		if err != nil {
+			return nil, fmt.Errorf("获取Pull Request的diff失败: %w", err)
+		}
+
+		matched := make([]api.FileDiff, 0, len(diffs))
+		for i := range diffs {
+			if filter.Match(&diffs[i]) {
+				matched = append(matched, diffs[i])
+			}
+		}
+		if len(matched) == 0 {
+			return TextResult("Pull Request #%d 共修改%d个文件，没有符合条件的文件", int(prNumber), len(diffs))
+		}
+
+		chunks := api.ChunkDiff(matched, lineNumbers, maxTokens)
+		if page > len(chunks) {
+			return nil, fmt.Errorf("页码超出范围，共%d页", len(chunks))
+		}
+		chunk := chunks[page-1]
+
+		result := mcp.NewToolResultText(chunk.Text)
+		notes := []string{fmt.Sprintf("第%d/%d页，共%d个文件符合条件（PR共修改%d个文件），本页约%d个token，包含：%s。",
+			page, len(chunks), len(matched), len(diffs), chunk.Tokens, strings.Join(chunk.Files, ", "))}
+		if chunk.Oversized {
+			notes = append(notes, "本页包含超过预算的单个diff段，无法再拆分。")
+		}
+		if page < len(chunks) {
+			notes = append(notes, fmt.Sprintf("使用page=%d获取下一页。", page+1))
+		}
+		result.Content = append(result.Content, mcp.NewTextContent(strings.Join(notes, "")))
+		return result, nil
+	})
+
+	// 列出代码审查讨论
+	listThreadsTool := mcp.NewTool("list_review_threads",
+		mcp.WithDescription("列出Pull Request的行内评论，按回复关系整理为讨论"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		WithFresh(),
+	)
+	s.AddReadTool(listThreadsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+
+		threads, err := client.Pulls.ListReviewThreads(ctx, owner, repo, int(prNumber))
+		if err != nil {
+			return nil, fmt.Errorf("获取代码审查讨论失败: %w", err)
+		}
+		return FormatJSONResult(threads)
+	})
+
+	// 回复代码审查讨论
+	replyTool := mcp.NewTool("reply_to_review_comment",
+		mcp.WithDescription("回复Pull Request中的行内评论"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("pull_number",
+			mcp.Required(),
+			mcp.Description("Pull Request编号"),
+		),
+		mcp.WithNumber("comment_id",
+			mcp.Required(),
+			mcp.Description("要回复的评论ID，通常为讨论的id"),
+		),
+		mcp.WithString("body",
+			mcp.Required(),
+			mcp.Description("回复内容"),
+		),
+	)
+	s.AddWriteTool(replyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		prNumber, _ := request.Params.Arguments["pull_number"].(float64)
+		commentID, _ := request.Params.Arguments["comment_id"].(float64)
+		body, _ := request.Params.Arguments["body"].(string)
+
+		comment, err := client.Pulls.ReplyToReviewComment(ctx, owner, repo, int(prNumber), int(commentID), body)
+		if err != nil {
+			return nil, fmt.Errorf("回复评论失败: %w", err)
+		}
+		return FormatJSONResult(comment)
+	})
+
+	// 解决代码审查讨论
+	resolveTool := mcp.NewTool("resolve_review_thread",
+		mcp.WithDescription("将Pull Request中的行内评论讨论标记为已解决或重新打开"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithNumber("comment_id",
+			mcp.Required(),
+			mcp.Description("讨论中第一条评论的ID，即list_review_threads返回的id"),
+		),
+		mcp.WithBoolean("resolved",
+			mcp.Description("为false时重新打开讨论，默认为true"),
+		),
+	)
+	s.AddWriteTool(resolveTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		commentID, _ := request.Params.Arguments["comment_id"].(float64)
+		resolved := true
+		if v, ok := request.Params.Arguments["resolved"].(bool); ok {
+			resolved = v
+		}
+
+		if _, err := client.Pulls.ResolveReviewThread(ctx, owner, repo, int(commentID), resolved); err != nil {
+			return nil, fmt.Errorf("更新讨论状态失败: %w", err)
+		}
+		if resolved {
+			return TextResult("已将讨论 %d 标记为已解决", int(commentID))
+		}
+		return TextResult("已重新打开讨论 %d", int(commentID))
+	})
+}
@@ -5,13 +5,12 @@ import (
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mark3labs/mcp-go/server"
 	
 	"github.com/gitcode-org-com/gitcode-mcp/api"
 )
 
 // AddSearchTools 添加搜索相关工具到MCP服务器
-func AddSearchTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
+func AddSearchTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
 	// 搜索代码
 	searchCodeTool := mcp.NewTool("search_code",
 		mcp.WithDescription("搜索代码"),
@@ -19,15 +18,27 @@ func AddSearchTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("搜索关键词"),
 		),
+		WithPagination(),
+		WithFresh(),
 	)
-	s.AddTool(searchCodeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(searchCodeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		query, _ := request.Params.Arguments["query"].(string)
 		
-		results, err := apiClient.Search.SearchCode(query)
+		opts := ListOptionsFromRequest(request)
+		results, err := client.Search.SearchCode(ctx, query, opts)
 		if err != nil {
 			return nil, fmt.Errorf("搜索代码失败: %w", err)
 		}
-		return FormatJSONResult(results)
+		if policy := repoPolicyFromContext(ctx); policy != nil {
+			items := filterByRepo(ctx, results.Items, func(item api.CodeMatch) string {
+				return repoFullName(item.Repository)
+			})
+			results.TotalCount -= len(results.Items) - len(items)
+			results.Items = items
+		}
+		return FormatListResult(results, len(results.Items), opts)
 	})
 	
 	// 搜索仓库
@@ -37,15 +48,21 @@ func AddSearchTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("搜索关键词"),
 		),
+		WithPagination(),
+		WithFresh(),
 	)
-	s.AddTool(searchReposTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(searchReposTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		query, _ := request.Params.Arguments["query"].(string)
 		
-		results, err := apiClient.Search.SearchRepositories(query)
+		opts := ListOptionsFromRequest(request)
+		results, err := client.Search.SearchRepositories(ctx, query, opts)
 		if err != nil {
 			return nil, fmt.Errorf("搜索仓库失败: %w", err)
 		}
-		return FormatJSONResult(results)
+		results = filterRepos(ctx, results)
+		return FormatListResult(results, len(results), opts)
 	})
 	
 	// 搜索Issues
@@ -55,15 +72,23 @@ func AddSearchTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("搜索关键词"),
 		),
+		WithPagination(),
+		WithFresh(),
 	)
-	s.AddTool(searchIssuesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(searchIssuesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		query, _ := request.Params.Arguments["query"].(string)
 		
-		results, err := apiClient.Search.SearchIssues(query)
+		opts := ListOptionsFromRequest(request)
+		results, err := client.Search.SearchIssues(ctx, query, opts)
 		if err != nil {
 			return nil, fmt.Errorf("搜索Issues失败: %w", err)
 		}
-		return FormatJSONResult(results)
+		results = filterByRepo(ctx, results, func(issue api.Issue) string {
+			return repoFromAPIURL(issue.URL)
+		})
+		return FormatListResult(results, len(results), opts)
 	})
 	
 	// 搜索用户
@@ -73,14 +98,77 @@ func AddSearchTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
 			mcp.Required(),
 			mcp.Description("搜索关键词"),
 		),
+		WithPagination(),
+		WithFresh(),
 	)
-	s.AddTool(searchUsersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.AddReadTool(searchUsersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
 		query, _ := request.Params.Arguments["query"].(string)
 		
-		results, err := apiClient.Search.SearchUsers(query)
+		opts := ListOptionsFromRequest(request)
+		results, err := client.Search.SearchUsers(ctx, query, opts)
 		if err != nil {
 			return nil, fmt.Errorf("搜索用户失败: %w", err)
 		}
-		return FormatJSONResult(results)
+		return FormatListResult(results, len(results), opts)
+	})
+	
+	// 搜索提交
+	searchCommitsTool := mcp.NewTool("search_commits",
+		mcp.WithDescription("搜索提交"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("搜索关键词"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(searchCommitsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		query, _ := request.Params.Arguments["query"].(string)
+		
+		opts := ListOptionsFromRequest(request)
+		results, err := client.Search.SearchCommits(ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("搜索提交失败: %w", err)
+		}
+		results = filterByRepo(ctx, results, func(commit api.Commit) string {
+			return repoFromAPIURL(commit.URL)
+		})
+		return FormatListResult(results, len(results), opts)
+	})
+	
+	// 搜索标签
+	searchLabelsTool := mcp.NewTool("search_labels",
+		mcp.WithDescription("搜索仓库的标签"),
+		mcp.WithString("owner",
+			mcp.Required(),
+			mcp.Description("仓库所有者"),
+		),
+		mcp.WithString("repo",
+			mcp.Required(),
+			mcp.Description("仓库名称"),
+		),
+		mcp.WithString("query",
+			mcp.Description("搜索关键词，为空时返回全部标签"),
+		),
+		WithPagination(),
+		WithFresh(),
+	)
+	s.AddReadTool(searchLabelsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = FreshContext(ctx, request)
+		client := api.ClientFromContext(ctx, apiClient)
+		owner, _ := request.Params.Arguments["owner"].(string)
+		repo, _ := request.Params.Arguments["repo"].(string)
+		query, _ := request.Params.Arguments["query"].(string)
+		
+		opts := ListOptionsFromRequest(request)
+		results, err := client.Search.SearchLabels(ctx, owner, repo, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("搜索标签失败: %w", err)
+		}
+		return FormatListResult(results, len(results), opts)
 	})
-} 
\ No newline at end of file
+}
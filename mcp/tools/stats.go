@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/gitcode-org-com/gitcode-mcp/api"
+)
+
+// AddStatsTools 添加服务器统计相关工具到MCP服务器
+func AddStatsTools(s ToolRegistrar, apiClient *api.GitCodeAPI) {
+	// 获取缓存统计
+	getCacheStatsTool := mcp.NewTool("get_cache_stats",
+		mcp.WithDescription("获取API缓存命中、请求合并和上游请求数量的统计数据"),
+	)
+	s.AddReadTool(getCacheStatsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := api.ClientFromContext(ctx, apiClient)
+		return FormatJSONResult(client.Stats())
+	})
+}
@@ -8,18 +8,51 @@ import (
 
 // RegisterAllTools 注册所有工具到MCP服务器
 func RegisterAllTools(s *server.MCPServer, apiClient *api.GitCodeAPI) {
+	RegisterTools(s, apiClient, nil, nil)
+}
+
+// RegisterTools 注册filter允许的工具到MCP服务器，filter为nil时注册全部工具，每个工具登记到所属的分组。
+// 工具调用时优先使用上下文中的会话客户端，没有会话客户端时使用apiClient；policy不为nil时按仓库访问策略检查调用
+func RegisterTools(s *server.MCPServer, apiClient *api.GitCodeAPI, filter *ToolFilter, policy *api.RepoPolicy) {
+	group := func(name string) ToolRegistrar {
+		return &toolGroup{server: s, group: name, filter: filter, policy: policy}
+	}
+	
 	// 注册仓库相关工具
-	AddRepositoryTools(s, apiClient)
+	AddRepositoryTools(group(GroupRepos), apiClient)
 	
 	// 注册分支相关工具
-	AddBranchTools(s, apiClient)
+	AddBranchTools(group(GroupBranches), apiClient)
 	
 	// 注册Issue相关工具
-	AddIssueTools(s, apiClient)
+	AddIssueTools(group(GroupIssues), apiClient)
 	
 	// 注册Pull Request相关工具
-	AddPullRequestTools(s, apiClient)
+	AddPullRequestTools(group(GroupPulls), apiClient)
+	
+	// 注册代码审查相关工具
+	AddReviewTools(group(GroupPulls), apiClient)
+	
+	// 注册里程碑相关工具
+	AddMilestoneTools(group(GroupIssues), apiClient)
+	
+	// 注册标签管理相关工具
+	AddLabelTools(group(GroupIssues), apiClient)
+	
+	// 注册文件内容相关工具
+	AddContentsTools(group(GroupRepos), apiClient)
+	
+	// 注册提交相关工具
+	AddCommitTools(group(GroupBranches), apiClient)
+	
+	// 注册发布版本和标签相关工具
+	AddReleaseTools(group(GroupRepos), apiClient)
 	
 	// 注册搜索相关工具
-	AddSearchTools(s, apiClient)
-} 
\ No newline at end of file
+	AddSearchTools(group(GroupSearch), apiClient)
+	
+	// 注册统计相关工具
+	AddStatsTools(group(GroupRepos), apiClient)
+	
+	filter.warnUnmatched()
+}
@@ -1,10 +1,15 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 	
 	"github.com/mark3labs/mcp-go/mcp"
+	
+	"github.com/gitcode-org-com/gitcode-mcp/api"
 )
 
 // FormatJSONResult 将数据格式化为JSON结果
@@ -17,4 +22,180 @@ func FormatJSONResult(data interface{}) (*mcp.CallToolResult, error) {
 	
 	// 使用 NewToolResultText 创建结果
 	return mcp.NewToolResultText(string(jsonBytes)), nil
-} 
\ No newline at end of file
+}
+
+// DefaultMaxItems 列表类工具默认最多返回的条目数
+const DefaultMaxItems = 100
+
+// WithPagination 为列表类工具添加page、per_page和max_items参数
+func WithPagination() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber("page",
+			mcp.Description("起始页码，从1开始，指GitCode接口的页；从该页开始获取，最多返回max_items条"),
+		)(t)
+		mcp.WithNumber("per_page",
+			mcp.Description(fmt.Sprintf("每页数量，默认%d，最大%d", api.DefaultPerPage, api.MaxPerPage)),
+		)(t)
+		mcp.WithNumber("max_items",
+			mcp.Description(fmt.Sprintf("最多返回的条目数，默认%d，0表示获取全部", DefaultMaxItems)),
+		)(t)
+		mcp.WithNumber("offset",
+			mcp.Description("跳过page页中已返回的条目数，用于从上次截断处继续获取，取值见上次结果的提示"),
+		)(t)
+	}
+}
+
+// ListOptionsFromRequest 从工具调用参数中解析分页参数
+func ListOptionsFromRequest(request mcp.CallToolRequest) *api.ListOptions {
+	opts := &api.ListOptions{MaxItems: DefaultMaxItems}
+	
+	if page, ok := request.Params.Arguments["page"].(float64); ok {
+		opts.Page = int(page)
+	}
+	if perPage, ok := request.Params.Arguments["per_page"].(float64); ok {
+		opts.PerPage = int(perPage)
+	}
+	if maxItems, ok := request.Params.Arguments["max_items"].(float64); ok {
+		opts.MaxItems = int(maxItems)
+	}
+	if offset, ok := request.Params.Arguments["offset"].(float64); ok {
+		opts.Offset = int(offset)
+	}
+	
+	return opts
+}
+
+// FormatListResult 将列表数据格式化为JSON结果，还有更多数据时附加继续获取的提示
+func FormatListResult(data interface{}, count int, opts *api.ListOptions) (*mcp.CallToolResult, error) {
+	result, err := FormatJSONResult(data)
+	if err != nil {
+		return nil, err
+	}
+	
+	if opts != nil && opts.NextPage > 0 {
+		next := fmt.Sprintf("将page设为%d", opts.NextPage)
+		if opts.NextOffset > 0 {
+			next += fmt.Sprintf("、offset设为%d", opts.NextOffset)
+		}
+		result.Content = append(result.Content, mcp.NewTextContent(
+			fmt.Sprintf("已返回%d条结果，还有更多数据。可增大max_items，或%s继续获取。", count, next),
+		))
+	}
+	
+	return result, nil
+}
+
+// WithFresh 为只读工具添加fresh参数，用于跳过缓存直接读取最新数据
+func WithFresh() mcp.ToolOption {
+	return mcp.WithBoolean("fresh",
+		mcp.Description("是否跳过缓存直接从GitCode获取最新数据"),
+	)
+}
+
+// FreshContext 在调用参数要求fresh时返回跳过缓存的上下文
+func FreshContext(ctx context.Context, request mcp.CallToolRequest) context.Context {
+	if fresh, _ := request.Params.Arguments["fresh"].(bool); fresh {
+		return api.WithNoCache(ctx)
+	}
+	return ctx
+}
+
+// StringSliceArgument 从工具调用参数中读取字符串数组，也接受逗号分隔的字符串
+func StringSliceArgument(request mcp.CallToolRequest, name string) []string {
+	var values []string
+	switch v := request.Params.Arguments[name].(type) {
+	case []interface{}:
+		for _, item := range v {
+			if str, ok := item.(string); ok && str != "" {
+				values = append(values, str)
+			}
+		}
+	case []string:
+		values = append(values, v...)
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// TextResult 返回纯文本结果，用于没有返回数据的操作
+func TextResult(format string, args ...interface{}) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultText(fmt.Sprintf(format, args...)), nil
+}
+
+// TimeArgument 从工具调用参数中读取时间，支持RFC3339格式和YYYY-MM-DD格式，未提供时返回零值
+func TimeArgument(request mcp.CallToolRequest, name string) (time.Time, error) {
+	value, _ := request.Params.Arguments[name].(string)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s必须是RFC3339格式（如2024-01-02T15:04:05Z）或YYYY-MM-DD格式: %q", name, value)
+}
+
+// WithIssueFilters 为列出Issue和Pull Request的工具添加过滤和排序参数
+func WithIssueFilters() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithString("state",
+			mcp.Description("状态，默认为open"),
+			mcp.Enum("open", "closed", "all"),
+		)(t)
+		mcp.WithArray("labels",
+			mcp.Description("只返回同时带有这些标签的条目"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		)(t)
+		mcp.WithString("assignee",
+			mcp.Description("负责人用户名，none表示没有负责人"),
+		)(t)
+		mcp.WithString("creator",
+			mcp.Description("创建者用户名"),
+		)(t)
+		mcp.WithString("milestone",
+			mcp.Description("里程碑编号，none表示没有里程碑，*表示有任意里程碑"),
+		)(t)
+		mcp.WithString("since",
+			mcp.Description("只返回该时间之后更新过的条目，RFC3339格式或YYYY-MM-DD"),
+		)(t)
+		mcp.WithString("sort",
+			mcp.Description("排序字段，默认为created"),
+			mcp.Enum("created", "updated", "comments"),
+		)(t)
+		mcp.WithString("direction",
+			mcp.Description("排序方向，默认为desc"),
+			mcp.Enum("asc", "desc"),
+		)(t)
+	}
+}
+
+// IssueFilterFromRequest 从工具调用参数中读取过滤和排序参数
+func IssueFilterFromRequest(request mcp.CallToolRequest) (*api.ListIssuesOptions, error) {
+	filter := &api.ListIssuesOptions{}
+	filter.State, _ = request.Params.Arguments["state"].(string)
+	filter.Labels = StringSliceArgument(request, "labels")
+	filter.Assignee, _ = request.Params.Arguments["assignee"].(string)
+	filter.Creator, _ = request.Params.Arguments["creator"].(string)
+	filter.Sort, _ = request.Params.Arguments["sort"].(string)
+	filter.Direction, _ = request.Params.Arguments["direction"].(string)
+
+	switch v := request.Params.Arguments["milestone"].(type) {
+	case string:
+		filter.Milestone = v
+	case float64:
+		filter.Milestone = fmt.Sprintf("%d", int(v))
+	}
+
+	var err error
+	if filter.Since, err = TimeArgument(request, "since"); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}